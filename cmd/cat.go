@@ -1,49 +1,69 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/LomotHo/pq-tools/pkg/parquet"
 	"github.com/spf13/cobra"
 )
 
 var catCmd = &cobra.Command{
-	Use:   "cat [parquet file]",
+	Use:   "cat [parquet file...]",
 	Short: "Print all rows in a parquet file",
-	Long:  `Print all rows in a parquet file in a human-readable format.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Print all rows in one or more parquet files in a human-readable format.
+Files, glob patterns and directories are streamed one after another.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		signal.Ignore(syscall.SIGPIPE)
-
-		reader, err := handleParquetReader(args[0])
+		opts, err := readerOptions(cmd)
 		if err != nil {
 			return err
 		}
-		defer safeClose(reader)
+		return streamRows(cmd, args, opts)
+	},
+}
 
-		encoder := json.NewEncoder(os.Stdout)
-		err = reader.StreamAll(func(row map[string]interface{}) error {
-			if err := encoder.Encode(row); err != nil {
-				if pathErr, ok := err.(*os.PathError); ok && (pathErr.Err == syscall.EPIPE || pathErr.Err.Error() == "broken pipe") {
-					return err
-				}
-				return fmt.Errorf("failed to print row: %w", err)
-			}
-			return nil
-		})
-		if err != nil {
-			if pathErr, ok := err.(*os.PathError); ok && pathErr.Err == syscall.EPIPE {
-				return nil
+// streamRows prints every row of the inputs as one JSON object per line
+func streamRows(cmd *cobra.Command, inputs []string, opts []parquet.ReaderOption) error {
+	signal.Ignore(syscall.SIGPIPE)
+
+	ds, err := handleDataset(inputs, opts...)
+	if err != nil {
+		return err
+	}
+	defer safeClose(ds)
+
+	w, err := newRowWriter(cmd, ds)
+	if err != nil {
+		return err
+	}
+	defer discardOutput(w)
+	err = ds.StreamAll(func(row map[string]interface{}) error {
+		if err := w.WriteRow(row); err != nil {
+			if isBrokenPipe(err) {
+				return err
 			}
-			return err
+			return fmt.Errorf("failed to print row: %w", err)
 		}
 		return nil
-	},
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	reportBadRows(cmd, ds)
+	if err != nil {
+		if isBrokenPipe(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(catCmd)
+	catCmd.Flags().StringSliceP("columns", "c", nil, "Comma-separated list of columns to read (dotted paths for nested fields)")
+	catCmd.Flags().StringP("where", "w", "", "Only include rows matching the expression, e.g. \"age > 30 AND name LIKE 'user_%'\"")
+	addOutputFlags(catCmd)
 }
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/LomotHo/pq-tools/pkg/parquet"
+)
+
+// Exit codes, so that scripts can tell failures apart
+const (
+	exitOK         = 0
+	exitFailure    = 1 // any other error
+	exitUsage      = 2 // invalid arguments, flags or filter expression
+	exitNotFound   = 3 // an input file does not exist
+	exitNotParquet = 4 // an input is not a Parquet file
+	exitCorrupt    = 5 // an input is corrupt or truncated, or has unreadable rows
+	exitEmpty      = 6 // an input has no rows
+)
+
+// usageError marks errors caused by invalid command-line input
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	var usage usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usage), errors.Is(err, parquet.ErrInvalidFilter):
+		return exitUsage
+	case errors.Is(err, fs.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, parquet.ErrNotParquet):
+		return exitNotParquet
+	case errors.Is(err, parquet.ErrTruncated), errors.Is(err, parquet.ErrCorrupt), errors.Is(err, parquet.ErrNoSchema):
+		return exitCorrupt
+	case errors.Is(err, parquet.ErrEmptyFile):
+		return exitEmpty
+	}
+	return exitFailure
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/LomotHo/pq-tools/pkg/parquet"
+)
+
+func TestExitCode(t *testing.T) {
+	dir := t.TempDir()
+	_, emptyDir := parquet.ExpandPaths([]string{dir})
+	_, noMatch := parquet.ExpandPaths([]string{filepath.Join(dir, "*.parquet")})
+
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, exitOK},
+		{"usage", usageError{fmt.Errorf("invalid --rows -1")}, exitUsage},
+		{"invalid filter", fmt.Errorf("wrapped: %w", parquet.ErrInvalidFilter), exitUsage},
+		{"directory without Parquet files", emptyDir, exitNotFound},
+		{"glob without matches", noMatch, exitNotFound},
+		{"not parquet", parquet.ErrNotParquet, exitNotParquet},
+		{"empty", parquet.ErrEmptyFile, exitEmpty},
+		{"other", fmt.Errorf("no space left"), exitFailure},
+	}
+	for _, tc := range cases {
+		if got := ExitCode(tc.err); got != tc.want {
+			t.Errorf("%s: ExitCode(%v) = %d, want %d", tc.name, tc.err, got, tc.want)
+		}
+	}
+}
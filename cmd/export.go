@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/LomotHo/pq-tools/pkg/parquet"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [parquet file...]",
+	Short: "Export rows for loading into a database",
+	Long: `Export the rows of one or more parquet files in a form a database can load.
+With --format sql, a CREATE TABLE statement derived from the schema is followed
+by batched INSERT statements. Nested columns are stored as JSON.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signal.Ignore(syscall.SIGPIPE)
+
+		opts, err := exportOptions(cmd, args)
+		if err != nil {
+			return err
+		}
+		readerOpts, err := readerOptions(cmd)
+		if err != nil {
+			return err
+		}
+		ds, err := handleDataset(args, readerOpts...)
+		if err != nil {
+			return err
+		}
+		defer safeClose(ds)
+
+		columns, err := ds.Columns()
+		if err != nil {
+			return fmt.Errorf("Failed to read schema: %w", err)
+		}
+		format, _ := cmd.Flags().GetString("format")
+		w, err := parquet.NewExportWriter(os.Stdout, format, columns, opts...)
+		if err != nil {
+			return usageError{err}
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit > 0 {
+			var rows []map[string]interface{}
+			rows, err = ds.Head(limit)
+			for i := 0; err == nil && i < len(rows); i++ {
+				err = w.WriteRow(rows[i])
+			}
+		} else {
+			err = ds.StreamAll(w.WriteRow)
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		reportBadRows(cmd, ds)
+		if err != nil && !isBrokenPipe(err) {
+			return fmt.Errorf("Failed to export rows: %w", err)
+		}
+		return nil
+	},
+}
+
+// exportOptions builds the output options of the export flags
+func exportOptions(cmd *cobra.Command, args []string) ([]parquet.OutputOption, error) {
+	dialect, _ := cmd.Flags().GetString("dialect")
+	d, err := parquet.ParseSQLDialect(dialect)
+	if err != nil {
+		return nil, usageError{fmt.Errorf("invalid --dialect: %v", err)}
+	}
+	table, _ := cmd.Flags().GetString("table")
+	if table == "" {
+		table = defaultTableName(args[0])
+		if table == "" {
+			return nil, usageError{fmt.Errorf("--table is required when the input is not a file or directory name")}
+		}
+	}
+	batchSize, _ := cmd.Flags().GetInt("batch-size")
+	return []parquet.OutputOption{
+		parquet.WithSQLDialect(d),
+		parquet.WithTable(table),
+		parquet.WithInsertBatchSize(batchSize),
+	}, nil
+}
+
+// defaultTableName names the table after an input: "users" for users.parquet
+// or a users/ directory. It is empty for stdin and glob patterns.
+func defaultTableName(input string) string {
+	if input == "-" || strings.ContainsAny(input, "*?[") {
+		return ""
+	}
+	name := filepath.Base(filepath.Clean(input))
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringP("format", "f", "sql", "Export format: "+strings.Join(parquet.ExportFormats, ", "))
+	exportCmd.Flags().String("dialect", "postgres", "SQL dialect: postgres, mysql or sqlite")
+	exportCmd.Flags().String("table", "", "Table to create and fill (default: the name of the first input)")
+	exportCmd.Flags().Int("batch-size", 1000, "Number of rows per INSERT statement")
+	exportCmd.Flags().IntP("limit", "n", 0, "Export at most this many rows (0 means all)")
+	exportCmd.Flags().StringSliceP("columns", "c", nil, "Comma-separated list of columns to read (dotted paths for nested fields)")
+	exportCmd.Flags().StringP("where", "w", "", "Only include rows matching the expression, e.g. \"age > 30 AND name LIKE 'user_%'\"")
+}
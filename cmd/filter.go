@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/LomotHo/pq-tools/pkg/parquet"
+	"github.com/spf13/cobra"
+)
+
+// filterCmd represents the filter command
+var filterCmd = &cobra.Command{
+	Use:   "filter [file...] [expression]",
+	Short: "Print rows of a Parquet file that match an expression",
+	Long: `Print rows of a Parquet file that match an expression, one JSON object per line.
+
+Expressions compare columns with literals and combine them with AND, OR and NOT:
+
+  pq filter data.parquet "age > 30 AND info.city = 'Paris' AND name LIKE 'user_%'"
+
+Supported operators: =, !=, <>, <, <=, >, >=, LIKE, NOT LIKE, IN (...), NOT IN (...),
+IS NULL and IS NOT NULL. Row groups whose statistics rule out any match are skipped.
+Several files, glob patterns or directories may be given before the expression.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := readerOptions(cmd)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, parquet.WithFilter(args[len(args)-1]))
+		return streamRows(cmd, args[:len(args)-1], opts)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filterCmd)
+	filterCmd.Flags().StringSliceP("columns", "c", nil, "Comma-separated list of columns to read (dotted paths for nested fields)")
+	addOutputFlags(filterCmd)
+}
@@ -2,21 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
-	"github.com/parquet-go/parquet-go"
+	"github.com/LomotHo/pq-tools/pkg/parquet"
+	parquetgo "github.com/parquet-go/parquet-go"
 	"github.com/spf13/cobra"
 )
 
-type SchemaField struct {
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	Optional bool   `json:"optional,omitempty"`
-}
-
 // Define fixed data structure for generating test data
 type ParquetData struct {
 	ID     int64   `parquet:"name=id"`
@@ -29,67 +27,194 @@ type ParquetData struct {
 var generateCmd = &cobra.Command{
 	Use:   "generate [output]",
 	Short: "Generate test Parquet files",
-	Long:  `Generate test Parquet files with specified number of rows and custom schema`,
-	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		// Initialize random number generator
-		rand.Seed(time.Now().UnixNano())
+	Long: `Generate test Parquet files with specified number of rows and custom schema.
+
+Without --schema the file holds five fixed fields. --schema takes a JSON or
+YAML file describing the fields, as for pq import, including nested structs,
+lists and maps, with optional settings of the random values of each field:
+min and max for numbers, decimals, dates, times and timestamps, values to pick
+from, null_ratio for optional fields, and min_length and max_length for
+strings, byte arrays, lists and maps.
 
+--like copies the schema, codec and metadata of an existing file and makes
+values that follow those of a sample of its rows: the share of nulls, the
+range of numbers, the share of distinct values, the values of columns with
+few of them, the lengths of strings and the numbers of items of lists and
+maps. Values of columns with many distinct values are synthesized, except
+for booleans, JSON, INT96 timestamps and decimals stored as bytes, which are
+picked from the sample like those of columns with few distinct values.
+
+--seed makes the output reproducible: the same seed and flags give the same
+bytes. Without it the seed drawn is printed. --files splits the rows over
+several files, or with --target-size writes files of about that size each.
+The rows are made by --workers goroutines at once, in blocks of up to 8192
+rows written in order, so that a single file is made in parallel too; each
+block draws from its own seed, so the output does not depend on the number
+of workers.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get output path
 		outputPath := "./.tmp/test.parquet"
 		if len(args) > 0 {
 			outputPath = args[0]
 		}
 
+		// Get flag values
+		rowCount, _ := cmd.Flags().GetInt("rows")
+		if rowCount < 0 {
+			return usageError{fmt.Errorf("invalid --rows %d", rowCount)}
+		}
+		files, _ := cmd.Flags().GetInt("files")
+		if files < 1 {
+			return usageError{fmt.Errorf("invalid --files %d", files)}
+		}
+		workers, _ := cmd.Flags().GetInt("workers")
+		if workers < 1 {
+			return usageError{fmt.Errorf("invalid --workers %d", workers)}
+		}
+		var targetSize int64
+		if size, _ := cmd.Flags().GetString("target-size"); size != "" {
+			if cmd.Flags().Changed("rows") {
+				return usageError{fmt.Errorf("--rows cannot be used with --target-size")}
+			}
+			n, err := parseSize(size)
+			if err != nil || n == 0 {
+				return usageError{fmt.Errorf("invalid --target-size %q", size)}
+			}
+			targetSize = n
+		}
+		seed := time.Now().UnixNano()
+		if cmd.Flags().Changed("seed") {
+			seed, _ = cmd.Flags().GetInt64("seed")
+		}
+
+		var opts []parquet.ImportOption
+		if compression, _ := cmd.Flags().GetString("compression"); compression != "" {
+			codec, err := parquet.ParseCompression(compression)
+			if err != nil {
+				return usageError{fmt.Errorf("invalid --compression: %v", err)}
+			}
+			opts = append(opts, parquet.WithCompression(codec))
+		}
+		rowGroupSize, _ := cmd.Flags().GetInt64("row-group-size")
+		if rowGroupSize < 0 {
+			return usageError{fmt.Errorf("invalid --row-group-size %d", rowGroupSize)}
+		}
+		if rowGroupSize > 0 {
+			opts = append(opts, parquet.WithRowGroupSize(rowGroupSize))
+		}
+
+		// newGenerator returns the generator of a dataset of n rows; every
+		// call draws from a source seeded alike
+		var newGenerator func(n int64) *parquet.Generator
+		like, _ := cmd.Flags().GetString("like")
+		if like != "" && cmd.Flags().Changed("schema") {
+			return usageError{fmt.Errorf("--like cannot be used with --schema")}
+		}
+		if like != "" {
+			profileRows, _ := cmd.Flags().GetInt("profile-rows")
+			if profileRows < 0 {
+				return usageError{fmt.Errorf("invalid --profile-rows %d", profileRows)}
+			}
+			profile, err := parquet.ProfileFile(like, profileRows)
+			if err != nil {
+				return fmt.Errorf("Failed to profile %s: %w", like, err)
+			}
+			newGenerator = func(n int64) *parquet.Generator {
+				return parquet.NewLikeGenerator(profile, n, rand.New(rand.NewSource(seed)))
+			}
+		}
+		if path, _ := cmd.Flags().GetString("schema"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("Failed to read schema: %w", err)
+			}
+			spec, err := parquet.ParseSchemaSpec(data)
+			if err != nil {
+				return usageError{err}
+			}
+			generator, err := parquet.NewGenerator(spec, rand.New(rand.NewSource(seed)))
+			if err != nil {
+				return usageError{err}
+			}
+			newGenerator = func(int64) *parquet.Generator { return generator }
+		}
+		if newGenerator == nil {
+			generator := parquet.NewRowGenerator(parquetgo.SchemaOf(new(ParquetData)), func(i int64, rng *rand.Rand) interface{} {
+				return &ParquetData{
+					ID:     i,
+					Name:   fmt.Sprintf("User%d", i),
+					Age:    int32(20 + rng.Intn(50)),
+					Active: rng.Intn(2) == 1,
+					Weight: float32(50 + rng.Intn(50)),
+				}
+			}, rand.New(rand.NewSource(seed)))
+			newGenerator = func(int64) *parquet.Generator { return generator }
+		}
+
+		// Work out the rows of each file
+		total := int64(rowCount)
+		generator := newGenerator(total)
+		if targetSize > 0 {
+			perFile, err := parquet.RowsForSize(generator, targetSize, seed, opts...)
+			if err != nil {
+				return fmt.Errorf("Failed to estimate the size of a row: %w", err)
+			}
+			total = perFile * int64(files)
+			generator = newGenerator(total)
+		}
+		rows := make([]int64, files)
+		for i := range rows {
+			rows[i] = total / int64(files)
+			if int64(i) < total%int64(files) {
+				rows[i]++
+			}
+		}
+		paths := []string{outputPath}
+		if files > 1 {
+			ext := filepath.Ext(outputPath)
+			base := strings.TrimSuffix(outputPath, ext)
+			paths = make([]string, files)
+			for i := range paths {
+				paths[i] = fmt.Sprintf("%s_%d%s", base, i+1, ext)
+			}
+		}
+
 		// Ensure output directory exists
 		dir := filepath.Dir(outputPath)
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			fmt.Printf("Failed to create directory: %v\n", err)
-			return
+			return fmt.Errorf("Failed to create directory: %w", err)
 		}
 
-		// Get flag values
-		rowCount, _ := cmd.Flags().GetInt("rows")
-		
-		// Create output file
-		file, err := os.Create(outputPath)
-		if err != nil {
-			fmt.Printf("Failed to create file: %v\n", err)
-			return
-		}
-		defer file.Close()
-		
-		// Create writer
-		writer := parquet.NewWriter(file)
-		
-		// Generate and write data
-		for i := 0; i < rowCount; i++ {
-			data := ParquetData{
-				ID:     int64(i),
-				Name:   fmt.Sprintf("User%d", i),
-				Age:    int32(20 + rand.Intn(50)),
-				Active: rand.Intn(2) == 1,
-				Weight: float32(50 + rand.Intn(50)),
-			}
-			
-			if err := writer.Write(&data); err != nil {
-				fmt.Printf("Failed to write data: %v\n", err)
-				return
-			}
+		create := func(i int) (io.WriteCloser, error) {
+			return os.Create(paths[i])
+		}
+		if err := parquet.GenerateShards(generator, seed, rows, workers, create, opts...); err != nil {
+			return fmt.Errorf("Failed to write data: %w", err)
 		}
-		
-		// Close writer
-		if err := writer.Close(); err != nil {
-			fmt.Printf("Failed to close writer: %v\n", err)
-			return
+
+		if files > 1 {
+			fmt.Printf("Successfully generated %d rows of data to %d files (%s to %s)\n", total, files, paths[0], paths[files-1])
+		} else {
+			fmt.Printf("Successfully generated %d rows of data to %s\n", total, outputPath)
 		}
-		
-		fmt.Printf("Successfully generated %d rows of data to %s\n", rowCount, outputPath)
+		if !cmd.Flags().Changed("seed") {
+			fmt.Printf("Seed: %d (pass --seed %d to generate the same data again)\n", seed, seed)
+		}
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(generateCmd)
-	generateCmd.Flags().IntP("rows", "r", 100, "Number of rows to generate")
-	generateCmd.Flags().StringP("schema", "s", "", "Custom schema in JSON format (not currently supported)")
-} 
\ No newline at end of file
+	generateCmd.Flags().IntP("rows", "r", 100, "Number of rows to generate, over all files")
+	generateCmd.Flags().StringP("schema", "s", "", "Schema file (JSON or YAML) describing the fields to generate")
+	generateCmd.Flags().String("like", "", "Parquet file whose schema and value distributions to mimic")
+	generateCmd.Flags().Int("profile-rows", parquet.DefaultProfileRows, "Number of rows of the --like file to sample (0 means all)")
+	generateCmd.Flags().Int64("seed", 0, "Seed of the random values, for reproducible output (default: random)")
+	generateCmd.Flags().Int("files", 1, "Number of files to generate, named like those of pq split")
+	generateCmd.Flags().String("target-size", "", "Approximate size of each file, e.g. 128MB, instead of --rows")
+	generateCmd.Flags().Int("workers", runtime.NumCPU(), "Number of blocks of rows to make, and of files to write, at once")
+	generateCmd.Flags().String("compression", "", "Compression codec: "+strings.Join(parquet.CompressionCodecs, ", ")+" (default: the --like file's, or the writer's)")
+	generateCmd.Flags().Int64("row-group-size", 0, "Maximum number of rows per row group (default: the writer's)")
+}
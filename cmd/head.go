@@ -2,21 +2,19 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strconv"
-	"github.com/LomotHo/pq-tools/pkg/parquet"
+
 	"github.com/spf13/cobra"
 )
 
 // headCmd represents the head command
 var headCmd = &cobra.Command{
-	Use:   "head [file]",
+	Use:   "head [file...]",
 	Short: "Display the first few rows of a Parquet file",
-	Long:  `Display the first few rows of a Parquet file, default is 10 rows.`,
+	Long:  `Display the first few rows of one or more Parquet files, default is 10 rows.
+Files, glob patterns and directories are read in order as one sequence of rows.`,
 	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		filePath := args[0]
-		
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get the number of rows parameter
 		nStr, _ := cmd.Flags().GetString("n")
 		n, err := strconv.Atoi(nStr)
@@ -24,28 +22,29 @@ var headCmd = &cobra.Command{
 			n = 10 // If parsing fails, display 10 rows by default
 		}
 
-		// Get formatting option
-		pretty, _ := cmd.Flags().GetBool("pretty")
-
-		// Create Parquet reader
-		reader, err := handleParquetReader(filePath)
+		// Open the input files
+		opts, err := readerOptions(cmd)
+		if err != nil {
+			return err
+		}
+		ds, err := handleDataset(args, opts...)
 		if err != nil {
-			er(err.Error())
-			return
+			return err
 		}
-		defer safeClose(reader)
+		defer safeClose(ds)
 
 		// Read the first n rows
-		rows, err := reader.Head(n)
+		rows, err := ds.Head(n)
+		reportBadRows(cmd, ds)
 		if err != nil {
-			er(handleRowsError(err).Error())
-			return
+			return handleRowsError(err)
 		}
 
 		// Print the results
-		if err := parquet.PrintJSON(rows, os.Stdout, pretty); err != nil {
-			er(fmt.Sprintf("Failed to print data: %v", err))
+		if err := printRows(cmd, ds, rows); err != nil {
+			return fmt.Errorf("Failed to print data: %w", err)
 		}
+		return nil
 	},
 }
 
@@ -53,4 +52,7 @@ func init() {
 	rootCmd.AddCommand(headCmd)
 	headCmd.Flags().StringP("n", "n", "10", "Number of rows to display")
 	headCmd.Flags().BoolP("pretty", "p", false, "Use formatted output (multiple lines per record)")
+	addOutputFlags(headCmd)
+	headCmd.Flags().StringSliceP("columns", "c", nil, "Comma-separated list of columns to read (dotted paths for nested fields)")
+	headCmd.Flags().StringP("where", "w", "", "Only include rows matching the expression, e.g. \"age > 30 AND name LIKE 'user_%'\"")
 } 
\ No newline at end of file
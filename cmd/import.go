@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LomotHo/pq-tools/pkg/parquet"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Convert JSON Lines or CSV to a Parquet file",
+	Long: `Convert a JSON Lines file, one object per line, or a CSV or TSV file to
+a Parquet file. The format follows the file extension unless --format is set.
+Use "-" to read from stdin.
+
+The schema is inferred from the leading records unless --schema gives one.
+In JSON Lines, nested objects become structs, arrays lists, and fields
+missing from some records optional. CSV columns are optional booleans,
+integers, decimals, doubles, dates, timestamps or strings, whichever holds
+all of their values; --type sets the type of a column instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := args[0]
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			if input == "-" {
+				return usageError{fmt.Errorf("--output is required when reading from stdin")}
+			}
+			output = strings.TrimSuffix(input, filepath.Ext(input)) + ".parquet"
+		}
+		if output == input {
+			return usageError{fmt.Errorf("refusing to overwrite the input %s, pass another --output", input)}
+		}
+		format, err := importFormat(cmd, input)
+		if err != nil {
+			return err
+		}
+		opts, err := importOptions(cmd)
+		if err != nil {
+			return err
+		}
+		if format != "jsonl" {
+			csvOpts, err := csvImportOptions(cmd, format)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, csvOpts...)
+		}
+
+		var r io.Reader = os.Stdin
+		if input != "-" {
+			f, err := os.Open(input)
+			if err != nil {
+				return fmt.Errorf("Failed to read file: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+		out, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("Failed to create file: %w", err)
+		}
+		importFile := parquet.ImportJSONL
+		if format != "jsonl" {
+			importFile = parquet.ImportCSV
+		}
+		rows, err := importFile(r, out, opts...)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(output)
+			return fmt.Errorf("Failed to import %s: %w", input, err)
+		}
+		fmt.Printf("Successfully imported %d rows to %s\n", rows, output)
+		return nil
+	},
+}
+
+// importFormat returns the format of the input: jsonl, csv or tsv, from
+// --format or else the file extension.
+func importFormat(cmd *cobra.Command, input string) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "auto" {
+		switch strings.ToLower(filepath.Ext(input)) {
+		case ".csv":
+			return "csv", nil
+		case ".tsv", ".tab":
+			return "tsv", nil
+		}
+		return "jsonl", nil
+	}
+	switch format {
+	case "jsonl", "ndjson", "json":
+		return "jsonl", nil
+	case "csv", "tsv":
+		return format, nil
+	}
+	return "", usageError{fmt.Errorf("invalid --format %q, expected auto, jsonl, csv or tsv", format)}
+}
+
+// csvImportOptions builds the import options of the CSV flags
+func csvImportOptions(cmd *cobra.Command, format string) ([]parquet.ImportOption, error) {
+	var opts []parquet.ImportOption
+	delimiter := ','
+	if format == "tsv" {
+		delimiter = '\t'
+	}
+	if cmd.Flags().Changed("delimiter") {
+		d, _ := cmd.Flags().GetString("delimiter")
+		if d == `\t` || strings.EqualFold(d, "tab") {
+			d = "\t"
+		}
+		runes := []rune(d)
+		if len(runes) != 1 || runes[0] == '\n' || runes[0] == '\r' {
+			return nil, usageError{fmt.Errorf("invalid --delimiter %q, expected a single character", d)}
+		}
+		delimiter = runes[0]
+	}
+	opts = append(opts, parquet.WithDelimiter(delimiter))
+
+	quote, _ := cmd.Flags().GetString("quote")
+	switch runes := []rune(quote); {
+	case len(runes) == 0:
+		opts = append(opts, parquet.WithQuote(0))
+	case len(runes) == 1 && runes[0] != delimiter:
+		opts = append(opts, parquet.WithQuote(runes[0]))
+	default:
+		return nil, usageError{fmt.Errorf("invalid --quote %q, expected a single character other than the delimiter", quote)}
+	}
+
+	header, _ := cmd.Flags().GetString("header")
+	switch strings.ToLower(strings.TrimSpace(header)) {
+	case "auto":
+	case "yes", "true":
+		opts = append(opts, parquet.WithHeader(true))
+	case "no", "false":
+		opts = append(opts, parquet.WithHeader(false))
+	default:
+		return nil, usageError{fmt.Errorf("invalid --header %q, expected auto, yes or no", header)}
+	}
+
+	if tokens, _ := cmd.Flags().GetStringArray("null"); len(tokens) > 0 {
+		opts = append(opts, parquet.WithNullValues(tokens...))
+	}
+	types, _ := cmd.Flags().GetStringArray("type")
+	if len(types) > 0 && cmd.Flags().Changed("schema") {
+		return nil, usageError{fmt.Errorf("--type cannot be used with --schema")}
+	}
+	for _, t := range types {
+		column, typ, ok := strings.Cut(t, "=")
+		if !ok || column == "" {
+			return nil, usageError{fmt.Errorf("invalid --type %q, expected column=type", t)}
+		}
+		if _, err := parquet.ParseTypeName(typ); err != nil {
+			return nil, usageError{fmt.Errorf("invalid --type %q: %v", t, err)}
+		}
+		opts = append(opts, parquet.WithColumnType(column, typ))
+	}
+	return opts, nil
+}
+
+// importOptions builds the import options of the schema and writer flags
+func importOptions(cmd *cobra.Command) ([]parquet.ImportOption, error) {
+	var opts []parquet.ImportOption
+	if path, _ := cmd.Flags().GetString("schema"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read schema: %w", err)
+		}
+		spec, err := parquet.ParseSchemaSpec(data)
+		if err != nil {
+			return nil, usageError{err}
+		}
+		schema, err := spec.Schema()
+		if err != nil {
+			return nil, usageError{err}
+		}
+		opts = append(opts, parquet.WithImportSchema(schema))
+	}
+	inferRows, _ := cmd.Flags().GetInt("infer-rows")
+	if inferRows < 0 {
+		return nil, usageError{fmt.Errorf("invalid --infer-rows %d", inferRows)}
+	}
+	opts = append(opts, parquet.WithInferRows(inferRows))
+	if n, _ := cmd.Flags().GetInt64("row-group-size"); n > 0 {
+		opts = append(opts, parquet.WithRowGroupSize(n))
+	}
+	compression, _ := cmd.Flags().GetString("compression")
+	codec, err := parquet.ParseCompression(compression)
+	if err != nil {
+		return nil, usageError{fmt.Errorf("invalid --compression: %v", err)}
+	}
+	opts = append(opts, parquet.WithCompression(codec))
+	return opts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringP("output", "o", "", "Parquet file to write (default: the input with a .parquet extension)")
+	importCmd.Flags().String("format", "auto", "Input format: auto (from the extension), jsonl, csv or tsv")
+	importCmd.Flags().String("schema", "", "JSON schema file to use instead of inferring the schema")
+	importCmd.Flags().Int("infer-rows", parquet.DefaultInferRows, "Number of leading records to infer the schema from (0 means all)")
+	importCmd.Flags().Int64("row-group-size", 0, "Maximum number of rows per row group (default: the writer's)")
+	importCmd.Flags().String("compression", "snappy", "Compression codec: "+strings.Join(parquet.CompressionCodecs, ", "))
+	importCmd.Flags().String("delimiter", "", "CSV field delimiter (default: a comma, or a tab for tsv); \\t for a tab")
+	importCmd.Flags().String("quote", `"`, "CSV quote character, empty for none")
+	importCmd.Flags().String("header", "auto", "Whether the first CSV row names the columns: auto, yes or no")
+	importCmd.Flags().StringArray("null", nil, "CSV value to read as null, besides empty fields (repeatable)")
+	importCmd.Flags().StringArray("type", nil, "Type of a CSV column, as column=type, e.g. created=timestamp[ms] (repeatable)")
+}
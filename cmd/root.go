@@ -12,19 +12,50 @@ var rootCmd = &cobra.Command{
 	Short: "pq is a tool for working with Parquet files",
 	Long: `A simple and easy-to-use Parquet file processing toolkit,
 allowing you to work with Parquet files just like JSONL files.
-Supports viewing header data, tail data, counting rows, and splitting files.`,
+Supports viewing header data, tail data, counting rows, and splitting files.
+
+Exit codes:
+  0  success
+  1  other errors
+  2  invalid arguments, flags or filter expression
+  3  input file not found
+  4  input is not a Parquet file
+  5  input is corrupt or truncated, or has unreadable rows
+  6  input has no rows`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Arguments are valid from here on: don't print usage for runtime errors
+		cmd.SilenceUsage = true
+		started = true
+	},
 }
 
-// Execute executes the root command
+// started is set once argument and flag parsing succeeded
+var started bool
+
+// Execute executes the root command. Errors raised before the command runs
+// are usage errors (see ExitCode).
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err != nil && !started {
+		return usageError{err}
+	}
+	return err
 }
 
 func init() {
 	// You can add global flags here
+	rootCmd.PersistentFlags().String("spool-memory", "64MB", "When reading from stdin or a pipe, buffer up to this much in memory before spilling to a temporary file")
+	rootCmd.PersistentFlags().Bool("raw-types", false, "Print values as stored instead of rendering timestamps, dates, decimals and UUIDs")
+	rootCmd.PersistentFlags().String("spool-max", "0", "Refuse stdin or pipe inputs larger than this size (0 means no limit)")
+	rootCmd.PersistentFlags().String("on-error", "fail", "What to do with rows that cannot be read: fail, skip or null")
 }
 
+// er prints an error to stderr and exits with the matching exit code
 func er(msg interface{}) {
-	fmt.Println("Error:", msg)
-	os.Exit(1)
+	fmt.Fprintln(os.Stderr, "Error:", msg)
+	code := exitFailure
+	if err, ok := msg.(error); ok {
+		code = ExitCode(err)
+	}
+	os.Exit(code)
 } 
\ No newline at end of file
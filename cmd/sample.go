@@ -2,43 +2,44 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 
-	"github.com/LomotHo/pq-tools/pkg/parquet"
 	"github.com/spf13/cobra"
 )
 
 var sampleCmd = &cobra.Command{
-	Use:   "sample [file]",
+	Use:   "sample [file...]",
 	Short: "Randomly sample rows from a Parquet file",
-	Long:  `Randomly sample rows from a Parquet file, default is 10 rows.`,
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Long:  `Randomly sample rows from one or more Parquet files, default is 10 rows.
+Rows are drawn uniformly across all files, globs and directories given.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		nStr, _ := cmd.Flags().GetString("n")
 		n, err := strconv.Atoi(nStr)
 		if err != nil {
 			n = 10
 		}
 
-		pretty, _ := cmd.Flags().GetBool("pretty")
-
-		reader, err := handleParquetReader(args[0])
+		opts, err := readerOptions(cmd)
+		if err != nil {
+			return err
+		}
+		ds, err := handleDataset(args, opts...)
 		if err != nil {
-			er(err.Error())
-			return
+			return err
 		}
-		defer safeClose(reader)
+		defer safeClose(ds)
 
-		rows, err := reader.Sample(n)
+		rows, err := ds.Sample(n)
+		reportBadRows(cmd, ds)
 		if err != nil {
-			er(handleRowsError(err).Error())
-			return
+			return handleRowsError(err)
 		}
 
-		if err := parquet.PrintJSON(rows, os.Stdout, pretty); err != nil {
-			er(fmt.Sprintf("Failed to print data: %v", err))
+		if err := printRows(cmd, ds, rows); err != nil {
+			return fmt.Errorf("Failed to print data: %w", err)
 		}
+		return nil
 	},
 }
 
@@ -46,4 +47,7 @@ func init() {
 	rootCmd.AddCommand(sampleCmd)
 	sampleCmd.Flags().StringP("n", "n", "10", "Number of rows to sample")
 	sampleCmd.Flags().BoolP("pretty", "p", false, "Use formatted output (multiple lines per record)")
+	addOutputFlags(sampleCmd)
+	sampleCmd.Flags().StringSliceP("columns", "c", nil, "Comma-separated list of columns to read (dotted paths for nested fields)")
+	sampleCmd.Flags().StringP("where", "w", "", "Only include rows matching the expression, e.g. \"age > 30 AND name LIKE 'user_%'\"")
 }
@@ -1,52 +1,63 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"github.com/LomotHo/pq-tools/pkg/parquet"
 	"github.com/spf13/cobra"
 )
 
 // schemaCmd represents the schema command
 var schemaCmd = &cobra.Command{
-	Use:   "schema [file]",
+	Use:   "schema [file...]",
 	Short: "Display schema information of a Parquet file",
 	Long:  `Display schema information of a Parquet file, including field names, types, etc.`,
 	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		filePath := args[0]
-		
-		// Create Parquet reader with improved error handling
-		reader, err := handleParquetReader(filePath)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Open the input files with improved error handling
+		opts, err := readerOptions(cmd)
 		if err != nil {
-			er(err.Error())
-			return
+			return err
 		}
-		defer safeClose(reader)
-
-		// Get schema information
-		schema, err := reader.GetSchema()
+		ds, err := handleDataset(args, opts...)
 		if err != nil {
-			// Provide more specific error messages for common issues
-			if err.Error() == "invalid reader: reader is not initialized properly" {
-				er("Failed to get schema: the file appears to be corrupt or invalid")
-			} else if err.Error() == "failed to get schema: schema is nil" {
-				er("Failed to get schema: the file has no schema information")
-			} else {
-				er(fmt.Sprintf("Failed to get schema: %v", err))
-			}
-			return
+			return err
 		}
+		defer safeClose(ds)
+
+		first := true
+		err = ds.Each(func(filePath string, reader *parquet.ParquetReader) error {
+			// Get schema information
+			schema, err := reader.GetSchema()
+			if err != nil {
+				// Provide more specific error messages for common issues
+				if errors.Is(err, parquet.ErrNoSchema) {
+					return fmt.Errorf("Failed to get schema: the file has no schema information: %w", err)
+				}
+				return fmt.Errorf("Failed to get schema: %w", err)
+			}
 
-		// Get file size
-		fileName := filepath.Base(filePath)
-		
-		// Print the results with improved header
-		fmt.Println(strings.Repeat("=", 50))
-		fmt.Printf("  SCHEMA: %s\n", fileName)
-		fmt.Println(strings.Repeat("=", 50))
-		fmt.Println()
-		fmt.Println(schema)
+			// Get file name
+			fileName := filepath.Base(filePath)
+			if len(ds.Files()) > 1 {
+				fileName = filePath
+			}
+
+			// Print the results with improved header
+			if !first {
+				fmt.Println()
+			}
+			first = false
+			fmt.Println(strings.Repeat("=", 50))
+			fmt.Printf("  SCHEMA: %s\n", fileName)
+			fmt.Println(strings.Repeat("=", 50))
+			fmt.Println()
+			fmt.Println(schema)
+			return nil
+		})
+		return err
 	},
 }
 
@@ -26,7 +26,7 @@ var splitCmd = &cobra.Command{
 
 		// Execute the split
 		if err := parquet.SplitParquetFile(filePath, n); err != nil {
-			er(handleSplitError(err).Error())
+			er(handleSplitError(err))
 			return
 		}
 
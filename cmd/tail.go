@@ -2,8 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"github.com/LomotHo/pq-tools/pkg/parquet"
 	"strconv"
 
 	"github.com/spf13/cobra"
@@ -11,42 +9,42 @@ import (
 
 // tailCmd represents the tail command
 var tailCmd = &cobra.Command{
-	Use:   "tail [file]",
+	Use:   "tail [file...]",
 	Short: "Display the last few rows of a Parquet file",
-	Long:  `Display the last few rows of a Parquet file, default is 10 rows.`,
+	Long:  `Display the last few rows of one or more Parquet files, default is 10 rows.
+Files, glob patterns and directories are read in order as one sequence of rows.`,
 	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		filePath := args[0]
-		
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get the number of rows parameter
 		nStr, _ := cmd.Flags().GetString("n")
 		n, err := strconv.Atoi(nStr)
 		if err != nil {
 			n = 10 // If parsing fails, display 10 rows by default
 		}
-		
-		// Get formatting option
-		pretty, _ := cmd.Flags().GetBool("pretty")
 
-		// Create Parquet reader
-		reader, err := handleParquetReader(filePath)
+		// Open the input files
+		opts, err := readerOptions(cmd)
 		if err != nil {
-			er(err.Error())
-			return
+			return err
 		}
-		defer safeClose(reader)
+		ds, err := handleDataset(args, opts...)
+		if err != nil {
+			return err
+		}
+		defer safeClose(ds)
 
 		// Read the last n rows
-		rows, err := reader.Tail(n)
+		rows, err := ds.Tail(n)
+		reportBadRows(cmd, ds)
 		if err != nil {
-			er(handleRowsError(err).Error())
-			return
+			return handleRowsError(err)
 		}
 
 		// Print the results
-		if err := parquet.PrintJSON(rows, os.Stdout, pretty); err != nil {
-			er(fmt.Sprintf("Failed to print data: %v", err))
+		if err := printRows(cmd, ds, rows); err != nil {
+			return fmt.Errorf("Failed to print data: %w", err)
 		}
+		return nil
 	},
 }
 
@@ -54,4 +52,7 @@ func init() {
 	rootCmd.AddCommand(tailCmd)
 	tailCmd.Flags().StringP("n", "n", "10", "Number of rows to display")
 	tailCmd.Flags().BoolP("pretty", "p", false, "Use formatted output (multiple lines per record)")
+	addOutputFlags(tailCmd)
+	tailCmd.Flags().StringSliceP("columns", "c", nil, "Comma-separated list of columns to read (dotted paths for nested fields)")
+	tailCmd.Flags().StringP("where", "w", "", "Only include rows matching the expression, e.g. \"age > 30 AND name LIKE 'user_%'\"")
 } 
\ No newline at end of file
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package cmd
+
+import "os"
+
+// terminalWidth returns 0: the terminal width is only detected on Linux and macOS
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -1,20 +1,85 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/LomotHo/pq-tools/pkg/parquet"
+	"github.com/spf13/cobra"
 )
 
-// handleParquetReader creates a Parquet reader and handles potential errors
-func handleParquetReader(filePath string) (*parquet.ParquetReader, error) {
-	reader, err := parquet.NewParquetReader(filePath)
+// handleDataset expands the file arguments of a command and opens them as one dataset
+func handleDataset(args []string, opts ...parquet.ReaderOption) (*parquet.Dataset, error) {
+	ds, err := parquet.OpenDataset(args, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to read file: %v", err)
+		return nil, fmt.Errorf("Failed to read file: %w", err)
 	}
-	return reader, nil
+	return ds, nil
+}
+
+// readerOptions builds reader options from the row-reading flags of a command
+func readerOptions(cmd *cobra.Command) ([]parquet.ReaderOption, error) {
+	var opts []parquet.ReaderOption
+	if columns, _ := cmd.Flags().GetStringSlice("columns"); len(columns) > 0 {
+		opts = append(opts, parquet.WithColumns(columns...))
+	}
+	if where, _ := cmd.Flags().GetString("where"); where != "" {
+		opts = append(opts, parquet.WithFilter(where))
+	}
+	if raw, _ := cmd.Flags().GetBool("raw-types"); raw {
+		opts = append(opts, parquet.WithRawTypes())
+	}
+	if policy, _ := cmd.Flags().GetString("on-error"); policy != "" {
+		p, err := parquet.ParseErrorPolicy(policy)
+		if err != nil {
+			return nil, usageError{fmt.Errorf("invalid --on-error: %v", err)}
+		}
+		opts = append(opts, parquet.WithErrorPolicy(p))
+	}
+	if limit, _ := cmd.Flags().GetString("spool-memory"); limit != "" {
+		n, err := parseSize(limit)
+		if err != nil {
+			return nil, usageError{fmt.Errorf("invalid --spool-memory: %v", err)}
+		}
+		opts = append(opts, parquet.WithSpoolMemoryLimit(n))
+	}
+	if limit, _ := cmd.Flags().GetString("spool-max"); limit != "" {
+		n, err := parseSize(limit)
+		if err != nil {
+			return nil, usageError{fmt.Errorf("invalid --spool-max: %v", err)}
+		}
+		opts = append(opts, parquet.WithSpoolMaxSize(n))
+	}
+	return opts, nil
+}
+
+// parseSize parses a byte size such as "512", "64KB", "10MB" or "2G" (binary units)
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{
+		{"TIB", 1 << 40}, {"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(multiplier)), nil
 }
 
 // handleRowsError processes errors from reader operations and provides user-friendly messages
@@ -23,10 +88,10 @@ func handleRowsError(err error) error {
 		return nil
 	}
 	
-	if err.Error() == "invalid reader: reader is not initialized properly" {
-		return fmt.Errorf("Failed to read data: the file appears to be corrupt or invalid")
+	if errors.Is(err, parquet.ErrInvalidReader) {
+		return fmt.Errorf("Failed to read data: the file appears to be corrupt or invalid: %w", err)
 	}
-	return fmt.Errorf("Failed to read data: %v", err)
+	return fmt.Errorf("Failed to read data: %w", err)
 }
 
 // handleSplitError processes errors from splitting operations
@@ -35,26 +100,207 @@ func handleSplitError(err error) error {
 		return nil
 	}
 	
-	errMsg := err.Error()
-	
 	// Check for specific error types
-	if strings.Contains(errMsg, "failed to create Parquet reader") || 
-	   strings.Contains(errMsg, "invalid reader") || 
-	   strings.Contains(errMsg, "corrupted") {
-		return fmt.Errorf("Failed to split file: the file appears to be corrupt or invalid")
+	if errors.Is(err, parquet.ErrNotParquet) || errors.Is(err, parquet.ErrCorrupt) || errors.Is(err, parquet.ErrTruncated) {
+		return fmt.Errorf("Failed to split file: the file appears to be corrupt or invalid: %w", err)
 	}
 	
-	if strings.Contains(errMsg, "file is empty") {
-		return fmt.Errorf("Failed to split file: the file is empty")
+	if errors.Is(err, parquet.ErrEmptyFile) {
+		return fmt.Errorf("Failed to split file: the file is empty: %w", err)
 	}
 	
-	return fmt.Errorf("Failed to split file: %v", err)
+	return fmt.Errorf("Failed to split file: %w", err)
+}
+
+// addOutputFlags registers the flags read by newRowWriter
+func addOutputFlags(c *cobra.Command) {
+	c.Flags().StringP("format", "f", "json", "Output format: "+strings.Join(parquet.OutputFormats, ", "))
+	c.Flags().String("null", "", "Text written for null values (default: empty in CSV and TSV output, NULL in tables, Markdown and HTML); strings equal to it are written the same, except that table nulls are dimmed on a terminal")
+	c.Flags().String("lists", "json", "How CSV, TSV and table output write lists and maps: json (in one cell) or explode (one row per element)")
+	c.Flags().Bool("flatten", false, "Turn nested struct fields into dotted top-level keys in JSON output")
+	c.Flags().StringSlice("explode", nil, "Write one row per element of these list columns, repeating the other fields (not with --lists explode)")
+	c.Flags().String("nan", "null", "How JSON output writes NaN and infinite floats: null or string (\"NaN\", \"Infinity\", \"-Infinity\")")
+	c.Flags().Bool("int64-as-string", false, "Write 64-bit integers as strings in JSON output, for consumers limited to 2^53")
+	c.Flags().String("binary", "base64", "How JSON output writes binary values: base64, hex or utf8")
+	c.Flags().Int("max-width", 0, "Maximum width of table output (default: the terminal width)")
+	c.Flags().Bool("wrap", false, "Wrap long values in table output instead of truncating them")
+	c.Flags().StringP("output", "o", "", "Write the rows to a Parquet file with the schema, metadata and compression of the input")
+}
+
+// newRowWriter creates a writer to stdout for the output flags of a command
+func newRowWriter(cmd *cobra.Command, ds *parquet.Dataset) (parquet.RowWriter, error) {
+	if output, _ := cmd.Flags().GetString("output"); output != "" {
+		for _, flag := range []string{"format", "pretty", "null", "lists", "flatten", "explode", "nan", "int64-as-string", "binary", "max-width", "wrap"} {
+			if cmd.Flags().Changed(flag) {
+				return nil, usageError{fmt.Errorf("--output writes Parquet and cannot be combined with --%s", flag)}
+			}
+		}
+		return newParquetFileWriter(ds, output)
+	}
+	format, _ := cmd.Flags().GetString("format")
+	var opts []parquet.OutputOption
+	if pretty, _ := cmd.Flags().GetBool("pretty"); pretty {
+		opts = append(opts, parquet.WithPretty())
+	}
+	if null, _ := cmd.Flags().GetString("null"); null != "" {
+		opts = append(opts, parquet.WithNullValue(null))
+	}
+	switch lists, _ := cmd.Flags().GetString("lists"); lists {
+	case "", "json":
+	case "explode":
+		opts = append(opts, parquet.WithExplode())
+	default:
+		return nil, usageError{fmt.Errorf("invalid --lists %q, expected json or explode", lists)}
+	}
+	width := terminalWidth(os.Stdout)
+	if (strings.EqualFold(format, "arrow") || strings.EqualFold(format, "pgcopy-binary")) && width > 0 {
+		return nil, usageError{fmt.Errorf("refusing to write binary %s output to a terminal, redirect it to a file or pipe", format)}
+	}
+	maxWidth, _ := cmd.Flags().GetInt("max-width")
+	if maxWidth == 0 {
+		maxWidth = width
+	}
+	opts = append(opts, parquet.WithMaxWidth(maxWidth))
+	if wrap, _ := cmd.Flags().GetBool("wrap"); wrap {
+		opts = append(opts, parquet.WithWrap())
+	}
+	if width > 0 && os.Getenv("NO_COLOR") == "" {
+		opts = append(opts, parquet.WithDimNulls())
+	}
+	if flatten, _ := cmd.Flags().GetBool("flatten"); flatten {
+		opts = append(opts, parquet.WithFlatten())
+	}
+	if explode, _ := cmd.Flags().GetStringSlice("explode"); len(explode) > 0 {
+		opts = append(opts, parquet.WithExplodeColumns(explode...))
+	}
+	nan, _ := cmd.Flags().GetString("nan")
+	nanPolicy, err := parquet.ParseNaNPolicy(nan)
+	if err != nil {
+		return nil, usageError{fmt.Errorf("invalid --nan: %v", err)}
+	}
+	opts = append(opts, parquet.WithNaNPolicy(nanPolicy))
+	if asString, _ := cmd.Flags().GetBool("int64-as-string"); asString {
+		opts = append(opts, parquet.WithInt64AsString())
+	}
+	binary, _ := cmd.Flags().GetString("binary")
+	encoding, err := parquet.ParseBinaryEncoding(binary)
+	if err != nil {
+		return nil, usageError{fmt.Errorf("invalid --binary: %v", err)}
+	}
+	opts = append(opts, parquet.WithBinaryEncoding(encoding))
+
+	columns, err := ds.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read schema: %w", err)
+	}
+	w, err := parquet.NewRowWriter(os.Stdout, format, columns, opts...)
+	if err != nil {
+		return nil, usageError{err}
+	}
+	return w, nil
+}
+
+// parquetFileWriter writes rows to a Parquet file and closes it on Flush. A
+// file that is not flushed, or fails to be, is removed by discard.
+type parquetFileWriter struct {
+	parquet.RowWriter
+	file    *os.File
+	flushed bool
+}
+
+func newParquetFileWriter(ds *parquet.Dataset, path string) (*parquetFileWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := ds.NewParquetWriter(file)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return &parquetFileWriter{RowWriter: w, file: file}, nil
+}
+
+func (w *parquetFileWriter) Flush() error {
+	err := w.RowWriter.Flush()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.flushed = err == nil
+	return err
+}
+
+// discard removes the partial output of a run that failed before Flush
+func (w *parquetFileWriter) discard() {
+	if !w.flushed {
+		w.file.Close()
+		os.Remove(w.file.Name())
+	}
+}
+
+// discardOutput removes the output file of w unless it was written in full
+func discardOutput(w parquet.RowWriter) {
+	if fw, ok := w.(*parquetFileWriter); ok {
+		fw.discard()
+	}
+}
+
+// printRows writes rows to stdout in the output format of a command
+func printRows(cmd *cobra.Command, ds *parquet.Dataset, rows []map[string]interface{}) error {
+	w, err := newRowWriter(cmd, ds)
+	if err != nil {
+		return err
+	}
+	defer discardOutput(w)
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			if isBrokenPipe(err) {
+				return nil
+			}
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil && !isBrokenPipe(err) {
+		return err
+	}
+	return nil
+}
+
+// isBrokenPipe reports whether err comes from writing to a closed pipe, as with "pq cat | head"
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE)
+}
+
+// maxReportedRows caps the row indices listed per file by reportBadRows
+const maxReportedRows = 20
+
+// reportBadRows prints a summary of the rows skipped or replaced by nulls under --on-error
+func reportBadRows(cmd *cobra.Command, ds *parquet.Dataset) {
+	action := "skipped"
+	if policy, _ := cmd.Flags().GetString("on-error"); strings.EqualFold(strings.TrimSpace(policy), "null") {
+		action = "replaced with nulls"
+	}
+	for _, bad := range ds.BadRows() {
+		indices := make([]string, 0, maxReportedRows)
+		for i, row := range bad.Rows {
+			if i == maxReportedRows {
+				break
+			}
+			indices = append(indices, strconv.FormatInt(row, 10))
+		}
+		list := strings.Join(indices, ", ")
+		if more := len(bad.Rows) - len(indices); more > 0 {
+			list += fmt.Sprintf(" and %d more", more)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %d unreadable rows in %s were %s: %s\n", len(bad.Rows), bad.File, action, list)
+	}
 }
 
-// safeClose safely closes a ParquetReader and handles any errors
-func safeClose(reader *parquet.ParquetReader) {
-	if reader != nil {
-		if err := reader.Close(); err != nil {
+// safeClose safely closes a Dataset and handles any errors
+func safeClose(ds *parquet.Dataset) {
+	if ds != nil {
+		if err := ds.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error closing file: %v\n", err)
 		}
 	}
@@ -2,46 +2,63 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/LomotHo/pq-tools/pkg/parquet"
 	"github.com/spf13/cobra"
 )
 
 // wcCmd represents the wc command
 var wcCmd = &cobra.Command{
-	Use:   "wc [file]",
+	Use:   "wc [file...]",
 	Short: "Count the number of rows in a Parquet file",
-	Long:  `Count the number of rows in a Parquet file, similar to the wc -l command.`,
-	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		filePath := args[0]
-		
+	Long: `Count the number of rows in a Parquet file, similar to the wc -l command.
+With several files, globs or directories, one line is printed per file followed by a total.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check if we only need to display the line count
 		linesOnly, _ := cmd.Flags().GetBool("l")
 
-		// Create Parquet reader
-		reader, err := handleParquetReader(filePath)
+		// Open the input files
+		opts, err := readerOptions(cmd)
 		if err != nil {
-			er(err.Error())
-			return
+			return err
 		}
-		defer safeClose(reader)
+		ds, err := handleDataset(args, opts...)
+		if err != nil {
+			return err
+		}
+		defer safeClose(ds)
 
-		// Get the row count
-		count, err := reader.Count()
+		// Get the row count of each file
+		total := int64(0)
+		files := ds.Files()
+		err = ds.Each(func(filePath string, reader *parquet.ParquetReader) error {
+			count, err := reader.Count()
+			if err != nil {
+				return err
+			}
+			total += count
+			if !linesOnly {
+				fmt.Printf("%d %s\n", count, filePath)
+			}
+			return nil
+		})
+		reportBadRows(cmd, ds)
 		if err != nil {
-			er(handleRowsError(err).Error())
-			return
+			return handleRowsError(err)
 		}
 
 		// Display results based on parameters
 		if linesOnly {
-			fmt.Println(count)
-		} else {
-			fmt.Printf("%d %s\n", count, filePath)
+			fmt.Println(total)
+		} else if len(files) != 1 {
+			fmt.Printf("%d total\n", total)
 		}
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(wcCmd)
-	wcCmd.Flags().BoolP("l", "l", false, "Display only the line count")
+	wcCmd.Flags().BoolP("l", "l", false, "Display only the line count (the total when several files are given)")
+	wcCmd.Flags().StringP("where", "w", "", "Only include rows matching the expression, e.g. \"age > 30 AND name LIKE 'user_%'\"")
 } 
\ No newline at end of file
@@ -5,6 +5,7 @@ go 1.24.9
 require (
 	github.com/parquet-go/parquet-go v0.29.0
 	github.com/spf13/cobra v1.9.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 replace github.com/parquet-go/parquet-go => github.com/LomotHo/parquet-go v0.0.0-20260426023926-72a7b56f3c7b
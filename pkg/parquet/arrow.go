@@ -0,0 +1,836 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// Arrow IPC constants, from the Arrow columnar format specification
+// (Message.fbs and Schema.fbs).
+const (
+	arrowMetadataV5        = 4
+	arrowHeaderSchema      = 1
+	arrowHeaderRecordBatch = 3
+
+	arrowTypeInt             = 2
+	arrowTypeFloatingPoint   = 3
+	arrowTypeBinary          = 4
+	arrowTypeUtf8            = 5
+	arrowTypeBool            = 6
+	arrowTypeDecimal         = 7
+	arrowTypeDate            = 8
+	arrowTypeTime            = 9
+	arrowTypeTimestamp       = 10
+	arrowTypeList            = 12
+	arrowTypeStruct          = 13
+	arrowTypeFixedSizeBinary = 15
+	arrowTypeMap             = 17
+
+	arrowPrecisionSingle = 1
+	arrowPrecisionDouble = 2
+
+	arrowDateDay = 0
+
+	arrowTimeMillisecond = 1
+	arrowTimeMicrosecond = 2
+	arrowTimeNanosecond  = 3
+)
+
+// arrowBatchRows is the number of rows per record batch.
+const arrowBatchRows = 16384
+
+// arrowWriter writes rows as an Arrow IPC stream: a schema message, record
+// batches and an end-of-stream marker. Values may be as stored or rendered by
+// their logical types: timestamps, dates, times, decimals and UUIDs are
+// converted back from their text form.
+type arrowWriter struct {
+	w        io.Writer
+	columns  []Column
+	builders []arrowBuilder
+	rows     int
+	started  bool
+}
+
+func newArrowWriter(w io.Writer, columns []Column) (*arrowWriter, error) {
+	builders := make([]arrowBuilder, len(columns))
+	for i, col := range columns {
+		b, err := newArrowBuilder(col.Node)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", col.Name, err)
+		}
+		builders[i] = b
+	}
+	return &arrowWriter{w: w, columns: columns, builders: builders}, nil
+}
+
+func (w *arrowWriter) writeSchema() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	fields := make(fbVector, len(w.columns))
+	for i, col := range w.columns {
+		fields[i] = w.builders[i].field(col.Name, !col.Node.Repeated() && !col.Node.Required())
+	}
+	schema := fbTable{fbInt16(0, 0), fbRef(1, fields)}
+	return w.writeMessage(arrowHeaderSchema, schema, nil)
+}
+
+func (w *arrowWriter) WriteRow(row map[string]interface{}) error {
+	if err := w.writeSchema(); err != nil {
+		return err
+	}
+	for i, col := range w.columns {
+		if err := w.builders[i].append(row[col.Name]); err != nil {
+			return fmt.Errorf("column %s: %v", col.Name, err)
+		}
+	}
+	w.rows++
+	if w.rows >= arrowBatchRows {
+		return w.writeBatch()
+	}
+	return nil
+}
+
+func (w *arrowWriter) writeBatch() error {
+	batch := &arrowBatch{}
+	for _, b := range w.builders {
+		b.flush(batch)
+	}
+	recordBatch := fbTable{
+		fbInt64(0, int64(w.rows)),
+		fbRef(1, fbStructVector{n: batch.numNodes, data: batch.nodes}),
+		fbRef(2, fbStructVector{n: batch.numBuffers, data: batch.buffers}),
+	}
+	w.rows = 0
+	return w.writeMessage(arrowHeaderRecordBatch, recordBatch, batch.body)
+}
+
+// writeMessage writes an encapsulated message: a continuation marker, the
+// metadata length, the Message flatbuffer and the body.
+func (w *arrowWriter) writeMessage(headerType uint8, header fbObject, body []byte) error {
+	meta := fbFinish(fbTable{
+		fbInt16(0, arrowMetadataV5),
+		fbUint8(1, headerType),
+		fbRef(2, header),
+		fbInt64(3, int64(len(body))),
+	})
+	prefix := binary.LittleEndian.AppendUint32(nil, 0xFFFFFFFF)
+	prefix = binary.LittleEndian.AppendUint32(prefix, uint32(len(meta)))
+	for _, data := range [][]byte{prefix, meta, body} {
+		if _, err := w.w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *arrowWriter) Flush() error {
+	if err := w.writeSchema(); err != nil {
+		return err
+	}
+	if w.rows > 0 {
+		if err := w.writeBatch(); err != nil {
+			return err
+		}
+	}
+	_, err := w.w.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0})
+	return err
+}
+
+// arrowBatch collects the field nodes, buffer locations and body of a record
+// batch, in the depth-first order of the schema fields.
+type arrowBatch struct {
+	nodes      []byte
+	numNodes   int
+	buffers    []byte
+	numBuffers int
+	body       []byte
+}
+
+func (b *arrowBatch) addNode(length, nullCount int) {
+	b.nodes = binary.LittleEndian.AppendUint64(b.nodes, uint64(length))
+	b.nodes = binary.LittleEndian.AppendUint64(b.nodes, uint64(nullCount))
+	b.numNodes++
+}
+
+func (b *arrowBatch) addBuffer(data []byte) {
+	b.buffers = binary.LittleEndian.AppendUint64(b.buffers, uint64(len(b.body)))
+	b.buffers = binary.LittleEndian.AppendUint64(b.buffers, uint64(len(data)))
+	b.numBuffers++
+	b.body = append(b.body, data...)
+	for len(b.body)%8 != 0 {
+		b.body = append(b.body, 0)
+	}
+}
+
+// bitmap is an Arrow validity or boolean bitmap.
+type bitmap struct {
+	bits  []byte
+	n     int
+	unset int
+}
+
+func (m *bitmap) add(set bool) {
+	if m.n%8 == 0 {
+		m.bits = append(m.bits, 0)
+	}
+	if set {
+		m.bits[m.n/8] |= 1 << (m.n % 8)
+	} else {
+		m.unset++
+	}
+	m.n++
+}
+
+func (m *bitmap) reset() {
+	m.bits, m.n, m.unset = m.bits[:0], 0, 0
+}
+
+// arrowBuilder accumulates the values of a column, or of a nested field, for
+// the next record batch.
+type arrowBuilder interface {
+	field(name string, nullable bool) fbTable
+	append(v interface{}) error
+	// flush adds the nodes and buffers of the values to batch and resets
+	// the builder.
+	flush(batch *arrowBatch)
+}
+
+func arrowField(name string, nullable bool, typeID uint8, typ fbTable, children ...fbObject) fbTable {
+	return fbTable{
+		fbRef(0, fbString(name)),
+		fbBool(1, nullable),
+		fbUint8(2, typeID),
+		fbRef(3, typ),
+		fbRef(5, fbVector(children)),
+	}
+}
+
+// newArrowBuilder returns the builder for the values of node.
+func newArrowBuilder(node parquet.Node) (arrowBuilder, error) {
+	switch {
+	case node.Repeated():
+		// a repeated field without LIST annotation: a list that is never null
+		elem, err := newArrowBuilder(parquet.Required(node))
+		if err != nil {
+			return nil, err
+		}
+		return &listBuilder{elem: elem, elemNullable: false}, nil
+	case isListNode(node):
+		elemNode := listElement(node)
+		if elemNode == nil {
+			return nil, fmt.Errorf("unsupported list layout")
+		}
+		elem, err := newArrowBuilder(elemNode)
+		if err != nil {
+			return nil, err
+		}
+		return &listBuilder{elem: elem, elemNullable: elemNode.Optional()}, nil
+	case isMapNode(node):
+		keyNode, valueNode := mapKeyValue(node)
+		if keyNode == nil || valueNode == nil {
+			return nil, fmt.Errorf("unsupported map layout")
+		}
+		keys, err := newArrowBuilder(keyNode)
+		if err != nil {
+			return nil, err
+		}
+		values, err := newArrowBuilder(valueNode)
+		if err != nil {
+			return nil, err
+		}
+		return &mapBuilder{keys: keys, values: values, valuesNullable: valueNode.Optional()}, nil
+	case node.Leaf():
+		return newArrowLeafBuilder(node.Type()), nil
+	}
+	s := &structBuilder{}
+	for _, field := range node.Fields() {
+		child, err := newArrowBuilder(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", field.Name(), err)
+		}
+		s.names = append(s.names, field.Name())
+		s.nullable = append(s.nullable, !field.Repeated() && !field.Required())
+		s.children = append(s.children, child)
+	}
+	return s, nil
+}
+
+// newArrowLeafBuilder maps a Parquet type to the Arrow type holding the same
+// values.
+func newArrowLeafBuilder(t parquet.Type) arrowBuilder {
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	if lt == nil {
+		lt = &format.LogicalType{}
+	}
+
+	switch {
+	case t.Kind() == parquet.Int96:
+		return timestampBuilder(format.TimeUnit{Nanos: &format.NanoSeconds{}}, true)
+	case lt.Timestamp != nil:
+		return timestampBuilder(lt.Timestamp.Unit, lt.Timestamp.IsAdjustedToUTC)
+	case lt.Date != nil:
+		return &fixedBuilder{
+			typeID: arrowTypeDate,
+			typ:    fbTable{fbInt16(0, arrowDateDay)},
+			width:  4,
+			encode: encodeDate,
+		}
+	case lt.Time != nil:
+		tick, unit, width := arrowTimeUnit(lt.Time.Unit)
+		return &fixedBuilder{
+			typeID: arrowTypeTime,
+			typ:    fbTable{fbInt16(0, unit), fbInt32(1, int32(width*8))},
+			width:  width,
+			encode: func(dst []byte, v interface{}) error { return encodeTime(dst, v, tick) },
+		}
+	case lt.Decimal != nil:
+		width := 16
+		if lt.Decimal.Precision > 38 {
+			width = 32
+		}
+		scale := int(lt.Decimal.Scale)
+		return &fixedBuilder{
+			typeID: arrowTypeDecimal,
+			typ:    fbTable{fbInt32(0, lt.Decimal.Precision), fbInt32(1, lt.Decimal.Scale), fbInt32(2, int32(width*8))},
+			width:  width,
+			encode: func(dst []byte, v interface{}) error { return encodeDecimal(dst, v, scale) },
+		}
+	case lt.UUID != nil:
+		return &fixedBuilder{
+			typeID: arrowTypeFixedSizeBinary,
+			typ:    fbTable{fbInt32(0, 16)},
+			width:  16,
+			encode: encodeUUID,
+		}
+	case lt.Float16 != nil:
+		return &fixedBuilder{
+			typeID: arrowTypeFloatingPoint,
+			typ:    fbTable{fbInt16(0, arrowPrecisionSingle)},
+			width:  4,
+			encode: encodeFloat32,
+		}
+	case lt.Integer != nil:
+		width := int(lt.Integer.BitWidth) / 8
+		if width == 0 {
+			width = 4
+			if t.Kind() == parquet.Int64 {
+				width = 8
+			}
+		}
+		return intBuilder(width, lt.Integer.IsSigned)
+	case lt.UTF8 != nil || lt.Enum != nil || lt.Json != nil:
+		return &binaryBuilder{typeID: arrowTypeUtf8}
+	}
+
+	switch t.Kind() {
+	case parquet.Boolean:
+		return &boolBuilder{}
+	case parquet.Int32:
+		return intBuilder(4, true)
+	case parquet.Int64:
+		return intBuilder(8, true)
+	case parquet.Float:
+		return &fixedBuilder{typeID: arrowTypeFloatingPoint, typ: fbTable{fbInt16(0, arrowPrecisionSingle)}, width: 4, encode: encodeFloat32}
+	case parquet.Double:
+		return &fixedBuilder{typeID: arrowTypeFloatingPoint, typ: fbTable{fbInt16(0, arrowPrecisionDouble)}, width: 8, encode: encodeFloat64}
+	case parquet.FixedLenByteArray:
+		width := t.Length()
+		return &fixedBuilder{
+			typeID: arrowTypeFixedSizeBinary,
+			typ:    fbTable{fbInt32(0, int32(width))},
+			width:  width,
+			encode: func(dst []byte, v interface{}) error {
+				b, ok := bytesValue(v)
+				if !ok || len(b) != width {
+					return fmt.Errorf("expected %d bytes, got %T", width, v)
+				}
+				copy(dst, b)
+				return nil
+			},
+		}
+	}
+	return &binaryBuilder{typeID: arrowTypeBinary}
+}
+
+func intBuilder(width int, signed bool) *fixedBuilder {
+	return &fixedBuilder{
+		typeID: arrowTypeInt,
+		typ:    fbTable{fbInt32(0, int32(width*8)), fbBool(1, signed)},
+		width:  width,
+		encode: func(dst []byte, v interface{}) error {
+			n, err := toInt64(v)
+			if err != nil {
+				return err
+			}
+			putInt(dst, n)
+			return nil
+		},
+	}
+}
+
+func timestampBuilder(unit format.TimeUnit, utc bool) *fixedBuilder {
+	tick, arrowUnit, _ := arrowTimeUnit(unit)
+	typ := fbTable{fbInt16(0, arrowUnit)}
+	if utc {
+		typ = append(typ, fbRef(1, fbString("UTC")))
+	}
+	return &fixedBuilder{
+		typeID: arrowTypeTimestamp,
+		typ:    typ,
+		width:  8,
+		encode: func(dst []byte, v interface{}) error { return encodeTimestamp(dst, v, tick) },
+	}
+}
+
+// arrowTimeUnit returns the tick, Arrow unit and byte width of times in unit.
+func arrowTimeUnit(unit format.TimeUnit) (time.Duration, int16, int) {
+	switch {
+	case unit.Millis != nil:
+		return time.Millisecond, arrowTimeMillisecond, 4
+	case unit.Micros != nil:
+		return time.Microsecond, arrowTimeMicrosecond, 8
+	}
+	return time.Nanosecond, arrowTimeNanosecond, 8
+}
+
+// fixedBuilder holds values of a fixed byte width.
+type fixedBuilder struct {
+	typeID uint8
+	typ    fbTable
+	width  int
+	encode func(dst []byte, v interface{}) error
+	valid  bitmap
+	data   []byte
+}
+
+func (b *fixedBuilder) field(name string, nullable bool) fbTable {
+	return arrowField(name, nullable, b.typeID, b.typ)
+}
+
+func (b *fixedBuilder) append(v interface{}) error {
+	b.data = append(b.data, make([]byte, b.width)...)
+	if v == nil {
+		b.valid.add(false)
+		return nil
+	}
+	if err := b.encode(b.data[len(b.data)-b.width:], v); err != nil {
+		b.data = b.data[:len(b.data)-b.width]
+		return err
+	}
+	b.valid.add(true)
+	return nil
+}
+
+func (b *fixedBuilder) flush(batch *arrowBatch) {
+	batch.addNode(b.valid.n, b.valid.unset)
+	batch.addBuffer(b.valid.bits)
+	batch.addBuffer(b.data)
+	b.valid.reset()
+	b.data = b.data[:0]
+}
+
+type boolBuilder struct {
+	valid  bitmap
+	values bitmap
+}
+
+func (b *boolBuilder) field(name string, nullable bool) fbTable {
+	return arrowField(name, nullable, arrowTypeBool, fbTable{})
+}
+
+func (b *boolBuilder) append(v interface{}) error {
+	if v == nil {
+		b.valid.add(false)
+		b.values.add(false)
+		return nil
+	}
+	x, ok := v.(bool)
+	if !ok {
+		return fmt.Errorf("expected a boolean, got %T", v)
+	}
+	b.valid.add(true)
+	b.values.add(x)
+	return nil
+}
+
+func (b *boolBuilder) flush(batch *arrowBatch) {
+	batch.addNode(b.valid.n, b.valid.unset)
+	batch.addBuffer(b.valid.bits)
+	batch.addBuffer(b.values.bits)
+	b.valid.reset()
+	b.values.reset()
+}
+
+// binaryBuilder holds Utf8 or Binary values.
+type binaryBuilder struct {
+	typeID  uint8
+	valid   bitmap
+	offsets []byte
+	data    []byte
+}
+
+func (b *binaryBuilder) field(name string, nullable bool) fbTable {
+	return arrowField(name, nullable, b.typeID, fbTable{})
+}
+
+func (b *binaryBuilder) append(v interface{}) error {
+	if len(b.offsets) == 0 {
+		b.offsets = binary.LittleEndian.AppendUint32(b.offsets, 0)
+	}
+	if v != nil {
+		data, ok := bytesValue(v)
+		if !ok {
+			return fmt.Errorf("expected a string or bytes, got %T", v)
+		}
+		b.data = append(b.data, data...)
+	}
+	b.valid.add(v != nil)
+	b.offsets = binary.LittleEndian.AppendUint32(b.offsets, uint32(len(b.data)))
+	return nil
+}
+
+func (b *binaryBuilder) flush(batch *arrowBatch) {
+	if len(b.offsets) == 0 {
+		b.offsets = binary.LittleEndian.AppendUint32(b.offsets, 0)
+	}
+	batch.addNode(b.valid.n, b.valid.unset)
+	batch.addBuffer(b.valid.bits)
+	batch.addBuffer(b.offsets)
+	batch.addBuffer(b.data)
+	b.valid.reset()
+	b.offsets, b.data = b.offsets[:0], b.data[:0]
+}
+
+type listBuilder struct {
+	elem         arrowBuilder
+	elemNullable bool
+	valid        bitmap
+	offsets      []byte
+	count        int
+}
+
+func (b *listBuilder) field(name string, nullable bool) fbTable {
+	return arrowField(name, nullable, arrowTypeList, fbTable{}, b.elem.field("element", b.elemNullable))
+}
+
+func (b *listBuilder) append(v interface{}) error {
+	if len(b.offsets) == 0 {
+		b.offsets = binary.LittleEndian.AppendUint32(b.offsets, 0)
+	}
+	if v != nil {
+		items := reflect.ValueOf(v)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			return fmt.Errorf("expected a list, got %T", v)
+		}
+		for i := 0; i < items.Len(); i++ {
+			if err := b.elem.append(items.Index(i).Interface()); err != nil {
+				return err
+			}
+			b.count++
+		}
+	}
+	b.valid.add(v != nil)
+	b.offsets = binary.LittleEndian.AppendUint32(b.offsets, uint32(b.count))
+	return nil
+}
+
+func (b *listBuilder) flush(batch *arrowBatch) {
+	if len(b.offsets) == 0 {
+		b.offsets = binary.LittleEndian.AppendUint32(b.offsets, 0)
+	}
+	batch.addNode(b.valid.n, b.valid.unset)
+	batch.addBuffer(b.valid.bits)
+	batch.addBuffer(b.offsets)
+	b.elem.flush(batch)
+	b.valid.reset()
+	b.offsets, b.count = b.offsets[:0], 0
+}
+
+type structBuilder struct {
+	names    []string
+	nullable []bool
+	children []arrowBuilder
+	valid    bitmap
+}
+
+func (b *structBuilder) field(name string, nullable bool) fbTable {
+	children := make([]fbObject, len(b.children))
+	for i, child := range b.children {
+		children[i] = child.field(b.names[i], b.nullable[i])
+	}
+	return arrowField(name, nullable, arrowTypeStruct, fbTable{}, children...)
+}
+
+func (b *structBuilder) append(v interface{}) error {
+	m, ok := v.(map[string]interface{})
+	if v != nil && !ok {
+		return fmt.Errorf("expected a struct, got %T", v)
+	}
+	for i, child := range b.children {
+		if err := child.append(m[b.names[i]]); err != nil {
+			return fmt.Errorf("%s: %v", b.names[i], err)
+		}
+	}
+	b.valid.add(v != nil)
+	return nil
+}
+
+func (b *structBuilder) flush(batch *arrowBatch) {
+	batch.addNode(b.valid.n, b.valid.unset)
+	batch.addBuffer(b.valid.bits)
+	for _, child := range b.children {
+		child.flush(batch)
+	}
+	b.valid.reset()
+}
+
+// mapBuilder holds maps as lists of key/value entries, in key order.
+type mapBuilder struct {
+	keys           arrowBuilder
+	values         arrowBuilder
+	valuesNullable bool
+	valid          bitmap
+	offsets        []byte
+	count          int
+}
+
+func (b *mapBuilder) field(name string, nullable bool) fbTable {
+	entries := arrowField("entries", false, arrowTypeStruct, fbTable{},
+		b.keys.field("key", false),
+		b.values.field("value", b.valuesNullable))
+	return arrowField(name, nullable, arrowTypeMap, fbTable{fbBool(0, false)}, entries)
+}
+
+func (b *mapBuilder) append(v interface{}) error {
+	if len(b.offsets) == 0 {
+		b.offsets = binary.LittleEndian.AppendUint32(b.offsets, 0)
+	}
+	if v != nil {
+		m := reflect.ValueOf(v)
+		if m.Kind() != reflect.Map {
+			return fmt.Errorf("expected a map, got %T", v)
+		}
+		keys := m.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			if err := b.keys.append(k.Interface()); err != nil {
+				return fmt.Errorf("key: %v", err)
+			}
+			if err := b.values.append(m.MapIndex(k).Interface()); err != nil {
+				return fmt.Errorf("value: %v", err)
+			}
+			b.count++
+		}
+	}
+	b.valid.add(v != nil)
+	b.offsets = binary.LittleEndian.AppendUint32(b.offsets, uint32(b.count))
+	return nil
+}
+
+func (b *mapBuilder) flush(batch *arrowBatch) {
+	if len(b.offsets) == 0 {
+		b.offsets = binary.LittleEndian.AppendUint32(b.offsets, 0)
+	}
+	batch.addNode(b.valid.n, b.valid.unset)
+	batch.addBuffer(b.valid.bits)
+	batch.addBuffer(b.offsets)
+	// the entries struct, which is never null
+	var entries bitmap
+	for i := 0; i < b.count; i++ {
+		entries.add(true)
+	}
+	batch.addNode(b.count, 0)
+	batch.addBuffer(entries.bits)
+	b.keys.flush(batch)
+	b.values.flush(batch)
+	b.valid.reset()
+	b.offsets, b.count = b.offsets[:0], 0
+}
+
+// putInt stores the low len(dst) bytes of n, little-endian.
+func putInt(dst []byte, n int64) {
+	for i := range dst {
+		dst[i] = byte(n >> (8 * i))
+	}
+}
+
+// toInt64 converts an integer value, or its decimal text, to int64. Unsigned
+// 64-bit values keep their bit pattern.
+func toInt64(v interface{}) (int64, error) {
+	if n, ok := intValue(v); ok {
+		return n, nil
+	}
+	switch n := v.(type) {
+	case uint:
+		return int64(n), nil
+	case uint64:
+		return int64(n), nil
+	case string:
+		if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(n, 10, 64); err == nil {
+			return int64(u), nil
+		}
+	}
+	return 0, fmt.Errorf("expected an integer, got %T", v)
+}
+
+func encodeFloat32(dst []byte, v interface{}) error {
+	var f float32
+	switch x := v.(type) {
+	case float32:
+		f = x
+	case float64:
+		f = float32(x)
+	case []byte:
+		if len(x) != 2 {
+			return fmt.Errorf("expected a FLOAT16, got %d bytes", len(x))
+		}
+		f = float16ToFloat32(binary.LittleEndian.Uint16(x))
+	default:
+		return fmt.Errorf("expected a float, got %T", v)
+	}
+	binary.LittleEndian.PutUint32(dst, math.Float32bits(f))
+	return nil
+}
+
+func encodeFloat64(dst []byte, v interface{}) error {
+	var f float64
+	switch x := v.(type) {
+	case float64:
+		f = x
+	case float32:
+		f = float64(x)
+	default:
+		return fmt.Errorf("expected a float, got %T", v)
+	}
+	binary.LittleEndian.PutUint64(dst, math.Float64bits(f))
+	return nil
+}
+
+// encodeTimestamp stores a timestamp in ticks since the Unix epoch. It takes
+// stored values, INT96 values, times and RFC 3339 strings.
+func encodeTimestamp(dst []byte, v interface{}, tick time.Duration) error {
+	var t time.Time
+	switch x := v.(type) {
+	case deprecated.Int96:
+		nanos := int64(uint64(x[1])<<32 | uint64(x[0]))
+		days := int64(x[2]) - julianDayOfUnixEpoch
+		t = time.Unix(days*86400, nanos)
+	case time.Time:
+		t = x
+	case string:
+		var err error
+		if t, err = parseTimestamp(x); err != nil {
+			return err
+		}
+	default:
+		n, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		putInt(dst, n)
+		return nil
+	}
+	putInt(dst, ticksOf(t, tick))
+	return nil
+}
+
+// encodeDate stores a date in days since the Unix epoch.
+func encodeDate(dst []byte, v interface{}) error {
+	if s, ok := v.(string); ok {
+		t, err := parseDate(s)
+		if err != nil {
+			return err
+		}
+		putInt(dst, t.Unix()/86400)
+		return nil
+	}
+	n, err := toInt64(v)
+	if err != nil {
+		return err
+	}
+	putInt(dst, n)
+	return nil
+}
+
+// encodeTime stores a time of day in ticks since midnight.
+func encodeTime(dst []byte, v interface{}, tick time.Duration) error {
+	if s, ok := v.(string); ok {
+		d, err := parseTimeOfDay(s)
+		if err != nil {
+			return err
+		}
+		putInt(dst, int64(d/tick))
+		return nil
+	}
+	n, err := toInt64(v)
+	if err != nil {
+		return err
+	}
+	putInt(dst, n)
+	return nil
+}
+
+// encodeDecimal stores the unscaled value of a decimal as a little-endian
+// two's complement integer.
+func encodeDecimal(dst []byte, v interface{}, scale int) error {
+	var unscaled *big.Int
+	if s, ok := v.(string); ok {
+		var err error
+		if unscaled, err = parseDecimal(s, scale); err != nil {
+			return err
+		}
+	} else {
+		var ok bool
+		if unscaled, ok = decimalUnscaled(v); !ok {
+			return fmt.Errorf("expected a decimal, got %T", v)
+		}
+	}
+	be, err := decimalBytes(unscaled, len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = be[len(be)-1-i]
+	}
+	return nil
+}
+
+// encodeUUID stores the 16 bytes of a UUID, given as bytes or in canonical
+// text form.
+func encodeUUID(dst []byte, v interface{}) error {
+	if s, ok := v.(string); ok && len(s) == 36 {
+		b, err := parseUUID(s)
+		if err != nil {
+			return err
+		}
+		copy(dst, b)
+		return nil
+	}
+	b, ok := bytesValue(v)
+	if !ok || len(b) != 16 {
+		return fmt.Errorf("expected a UUID, got %T", v)
+	}
+	copy(dst, b)
+	return nil
+}
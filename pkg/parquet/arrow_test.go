@@ -0,0 +1,492 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// fbReader reads tables out of a FlatBuffers buffer, enough to check the
+// Arrow messages written by arrowWriter.
+type fbReader struct {
+	buf []byte
+	pos int
+}
+
+func (t fbReader) u32(at int) int { return int(binary.LittleEndian.Uint32(t.buf[at:])) }
+
+// slot returns the position of a field, or 0 if it is absent.
+func (t fbReader) slot(n int) int {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if 4+2*n >= int(binary.LittleEndian.Uint16(t.buf[vtable:])) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(t.buf[vtable+4+2*n:]))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t fbReader) int64(n int) int64 {
+	if at := t.slot(n); at != 0 {
+		return int64(binary.LittleEndian.Uint64(t.buf[at:]))
+	}
+	return 0
+}
+
+func (t fbReader) int32(n int) int32 {
+	if at := t.slot(n); at != 0 {
+		return int32(binary.LittleEndian.Uint32(t.buf[at:]))
+	}
+	return 0
+}
+
+func (t fbReader) int16(n int) int16 {
+	if at := t.slot(n); at != 0 {
+		return int16(binary.LittleEndian.Uint16(t.buf[at:]))
+	}
+	return 0
+}
+
+func (t fbReader) uint8(n int) uint8 {
+	if at := t.slot(n); at != 0 {
+		return t.buf[at]
+	}
+	return 0
+}
+
+func (t fbReader) ref(n int) int {
+	at := t.slot(n)
+	return at + t.u32(at)
+}
+
+func (t fbReader) table(n int) fbReader { return fbReader{t.buf, t.ref(n)} }
+
+func (t fbReader) str(n int) string {
+	at := t.ref(n)
+	return string(t.buf[at+4 : at+4+t.u32(at)])
+}
+
+func (t fbReader) tables(n int) []fbReader {
+	at := t.ref(n)
+	out := make([]fbReader, t.u32(at))
+	for i := range out {
+		elem := at + 4 + 4*i
+		out[i] = fbReader{t.buf, elem + t.u32(elem)}
+	}
+	return out
+}
+
+// structs returns the 16-byte structs of a vector as pairs of int64.
+func (t fbReader) structs(n int) [][2]int64 {
+	at := t.ref(n)
+	out := make([][2]int64, t.u32(at))
+	for i := range out {
+		data := t.buf[at+4+16*i:]
+		out[i] = [2]int64{int64(binary.LittleEndian.Uint64(data)), int64(binary.LittleEndian.Uint64(data[8:]))}
+	}
+	return out
+}
+
+type arrowMessage struct {
+	header     fbReader
+	headerType uint8
+	body       []byte
+}
+
+func readArrowStream(t *testing.T, data []byte) []arrowMessage {
+	t.Helper()
+	var messages []arrowMessage
+	for {
+		if len(data) < 8 || binary.LittleEndian.Uint32(data) != 0xFFFFFFFF {
+			t.Fatalf("missing continuation marker")
+		}
+		size := int(binary.LittleEndian.Uint32(data[4:]))
+		if size == 0 {
+			if len(data) != 8 {
+				t.Fatalf("%d bytes after the end of stream", len(data)-8)
+			}
+			return messages
+		}
+		if size%8 != 0 {
+			t.Fatalf("metadata size %d is not a multiple of 8", size)
+		}
+		meta := data[8 : 8+size]
+		msg := fbReader{meta, int(binary.LittleEndian.Uint32(meta))}
+		if v := msg.int16(0); v != arrowMetadataV5 {
+			t.Fatalf("metadata version %d", v)
+		}
+		bodyLength := int(msg.int64(3))
+		data = data[8+size:]
+		messages = append(messages, arrowMessage{msg.table(2), msg.uint8(1), data[:bodyLength]})
+		data = data[bodyLength:]
+	}
+}
+
+func TestArrowWriter(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Node: parquet.Int(64)},
+		{Name: "name", Node: parquet.Optional(parquet.String())},
+		{Name: "score", Node: parquet.Optional(parquet.Leaf(parquet.DoubleType))},
+		{Name: "tags", Node: parquet.Optional(parquet.List(parquet.String()))},
+		{Name: "attrs", Node: parquet.Optional(parquet.Map(parquet.String(), parquet.Int(32)))},
+		{Name: "at", Node: parquet.Optional(parquet.Timestamp(parquet.Millisecond))},
+	}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []map[string]interface{}{
+		{"id": int64(1), "name": "a", "score": 1.5, "tags": []interface{}{"x", "y"},
+			"attrs": map[string]interface{}{"k": int32(7)}, "at": at.UnixMilli()},
+		{"id": int64(2), "name": nil, "score": nil, "tags": nil, "attrs": nil, "at": nil},
+		{"id": int64(3), "name": "ccc", "score": 2.0, "tags": []interface{}{},
+			"attrs": map[string]interface{}{}, "at": "2024-01-02T03:04:05Z"},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, "arrow", columns)
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	messages := readArrowStream(t, buf.Bytes())
+	if len(messages) != 2 || messages[0].headerType != arrowHeaderSchema || messages[1].headerType != arrowHeaderRecordBatch {
+		t.Fatalf("expected a schema and a record batch, got %d messages", len(messages))
+	}
+
+	fields := messages[0].header.tables(1)
+	wantFields := []struct {
+		name     string
+		nullable bool
+		typeID   uint8
+		children int
+	}{
+		{"id", false, arrowTypeInt, 0},
+		{"name", true, arrowTypeUtf8, 0},
+		{"score", true, arrowTypeFloatingPoint, 0},
+		{"tags", true, arrowTypeList, 1},
+		{"attrs", true, arrowTypeMap, 1},
+		{"at", true, arrowTypeTimestamp, 0},
+	}
+	if len(fields) != len(wantFields) {
+		t.Fatalf("got %d fields, want %d", len(fields), len(wantFields))
+	}
+	for i, want := range wantFields {
+		f := fields[i]
+		if f.str(0) != want.name || (f.uint8(1) == 1) != want.nullable || f.uint8(2) != want.typeID || len(f.tables(5)) != want.children {
+			t.Errorf("field %d: got %s nullable=%v type=%d children=%d, want %+v",
+				i, f.str(0), f.uint8(1) == 1, f.uint8(2), len(f.tables(5)), want)
+		}
+	}
+	if typ := fields[0].table(3); typ.int32(0) != 64 || typ.uint8(1) != 1 {
+		t.Errorf("id: got Int(%d, signed=%d)", typ.int32(0), typ.uint8(1))
+	}
+	if typ := fields[5].table(3); typ.int16(0) != arrowTimeMillisecond || typ.str(1) != "UTC" {
+		t.Errorf("at: got unit %d", typ.int16(0))
+	}
+	entries := fields[4].tables(5)[0]
+	if entries.str(0) != "entries" || entries.uint8(2) != arrowTypeStruct || len(entries.tables(5)) != 2 {
+		t.Errorf("attrs: unexpected entries field %s", entries.str(0))
+	}
+
+	batch := messages[1].header
+	body := messages[1].body
+	if n := batch.int64(0); n != 3 {
+		t.Errorf("batch length %d, want 3", n)
+	}
+	// id, name, score, tags, tags.element, attrs, entries, key, value, at
+	nodes := batch.structs(1)
+	wantNodes := [][2]int64{{3, 0}, {3, 1}, {3, 1}, {3, 1}, {2, 0}, {3, 1}, {1, 0}, {1, 0}, {1, 0}, {3, 1}}
+	if len(nodes) != len(wantNodes) {
+		t.Fatalf("got %d field nodes, want %d", len(nodes), len(wantNodes))
+	}
+	for i := range wantNodes {
+		if nodes[i] != wantNodes[i] {
+			t.Errorf("node %d: got %v, want %v", i, nodes[i], wantNodes[i])
+		}
+	}
+	buffers := batch.structs(2)
+	buffer := func(i int) []byte {
+		b := buffers[i]
+		if b[0]%8 != 0 {
+			t.Errorf("buffer %d is not aligned", i)
+		}
+		return body[b[0] : b[0]+b[1]]
+	}
+	if ids := buffer(1); binary.LittleEndian.Uint64(ids[16:]) != 3 {
+		t.Errorf("id values: %v", ids)
+	}
+	if valid := buffer(2); valid[0] != 0b101 {
+		t.Errorf("name validity: %08b", valid[0])
+	}
+	if names := string(buffer(4)); names != "accc" {
+		t.Errorf("name data: %q", names)
+	}
+	if score := buffer(6); math.Float64frombits(binary.LittleEndian.Uint64(score[16:])) != 2.0 {
+		t.Errorf("score values: %v", score)
+	}
+	timestamps := buffer(len(buffers) - 1)
+	for _, i := range []int{0, 2} {
+		if got := int64(binary.LittleEndian.Uint64(timestamps[8*i:])); got != at.UnixMilli() {
+			t.Errorf("at[%d]: got %d, want %d", i, got, at.UnixMilli())
+		}
+	}
+}
+
+func TestArrowWriterWithoutRows(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, "arrow", []Column{{Name: "id", Node: parquet.Int(64)}})
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	messages := readArrowStream(t, buf.Bytes())
+	if len(messages) != 1 || messages[0].headerType != arrowHeaderSchema {
+		t.Fatalf("expected only a schema message, got %d messages", len(messages))
+	}
+}
+
+func TestEncodeDecimal(t *testing.T) {
+	for _, tt := range []struct {
+		in   interface{}
+		want int64
+	}{
+		{int32(12345), 12345},
+		{"-1.5", -150},
+		{[]byte{0xFF, 0x38}, -200},
+	} {
+		dst := make([]byte, 16)
+		if err := encodeDecimal(dst, tt.in, 2); err != nil {
+			t.Errorf("%v: %v", tt.in, err)
+			continue
+		}
+		lo := int64(binary.LittleEndian.Uint64(dst))
+		hi := int64(binary.LittleEndian.Uint64(dst[8:]))
+		wantHi := int64(0)
+		if tt.want < 0 {
+			wantHi = -1
+		}
+		if lo != tt.want || hi != wantHi {
+			t.Errorf("%v: got %d/%d, want %d", tt.in, hi, lo, tt.want)
+		}
+	}
+	if err := encodeDecimal(make([]byte, 16), "1.234", 2); err == nil {
+		t.Errorf("expected an error for too many fractional digits")
+	}
+}
+
+// arrowFieldDesc describes a field of an Arrow schema, with the defaults of
+// omitted type parameters filled in.
+type arrowFieldDesc struct {
+	Name     string
+	Nullable bool
+	TypeID   uint8
+	Params   []interface{}
+	Children []arrowFieldDesc
+}
+
+func readArrowField(f fbReader) arrowFieldDesc {
+	desc := arrowFieldDesc{Name: f.str(0), Nullable: f.uint8(1) == 1, TypeID: f.uint8(2)}
+	if f.slot(3) != 0 {
+		typ := f.table(3)
+		switch desc.TypeID {
+		case arrowTypeInt:
+			desc.Params = []interface{}{typ.int32(0), typ.uint8(1) == 1}
+		case arrowTypeFloatingPoint:
+			desc.Params = []interface{}{typ.int16(0)}
+		case arrowTypeDecimal:
+			width := typ.int32(2)
+			if typ.slot(2) == 0 {
+				width = 128
+			}
+			desc.Params = []interface{}{typ.int32(0), typ.int32(1), width}
+		case arrowTypeTimestamp:
+			tz := ""
+			if typ.slot(1) != 0 {
+				tz = typ.str(1)
+			}
+			desc.Params = []interface{}{typ.int16(0), tz}
+		case arrowTypeMap:
+			desc.Params = []interface{}{typ.uint8(0) == 1}
+		}
+	}
+	if f.slot(5) != 0 {
+		for _, child := range f.tables(5) {
+			desc.Children = append(desc.Children, readArrowField(child))
+		}
+	}
+	return desc
+}
+
+// arrowBatchReader reads the values of a record batch, taking field nodes and
+// buffers in the depth-first order of the schema fields.
+type arrowBatchReader struct {
+	nodes   [][2]int64
+	buffers [][2]int64
+	body    []byte
+}
+
+func (r *arrowBatchReader) buffer() []byte {
+	b := r.buffers[0]
+	r.buffers = r.buffers[1:]
+	return r.body[b[0] : b[0]+b[1]]
+}
+
+func (r *arrowBatchReader) read(f arrowFieldDesc) []interface{} {
+	length := int(r.nodes[0][0])
+	r.nodes = r.nodes[1:]
+	validity := r.buffer()
+	valid := func(i int) bool { return len(validity) == 0 || validity[i/8]&(1<<(i%8)) != 0 }
+	offsets := func(b []byte, i int) int { return int(binary.LittleEndian.Uint32(b[4*i:])) }
+
+	values := make([]interface{}, length)
+	switch f.TypeID {
+	case arrowTypeInt, arrowTypeTimestamp, arrowTypeFloatingPoint, arrowTypeDecimal:
+		data := r.buffer()
+		width := 8
+		switch f.TypeID {
+		case arrowTypeInt:
+			width = int(f.Params[0].(int32)) / 8
+		case arrowTypeDecimal:
+			width = int(f.Params[2].(int32)) / 8
+		}
+		for i := range values {
+			if !valid(i) {
+				continue
+			}
+			switch {
+			case f.TypeID == arrowTypeFloatingPoint:
+				values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+			case width == 4:
+				values[i] = int64(int32(binary.LittleEndian.Uint32(data[4*i:])))
+			default:
+				// the low 64 bits of decimals are enough for the test values
+				values[i] = int64(binary.LittleEndian.Uint64(data[width*i:]))
+			}
+		}
+	case arrowTypeUtf8:
+		offs, data := r.buffer(), r.buffer()
+		for i := range values {
+			if valid(i) {
+				values[i] = string(data[offsets(offs, i):offsets(offs, i+1)])
+			}
+		}
+	case arrowTypeList, arrowTypeMap:
+		offs := r.buffer()
+		items := r.read(f.Children[0])
+		for i := range values {
+			if valid(i) {
+				values[i] = append([]interface{}{}, items[offsets(offs, i):offsets(offs, i+1)]...)
+			}
+		}
+	case arrowTypeStruct:
+		children := make([][]interface{}, len(f.Children))
+		for j, child := range f.Children {
+			children[j] = r.read(child)
+		}
+		for i := range values {
+			if !valid(i) {
+				continue
+			}
+			m := map[string]interface{}{}
+			for j, child := range f.Children {
+				m[child.Name] = children[j][i]
+			}
+			values[i] = m
+		}
+	}
+	return values
+}
+
+// decodeArrowStream returns the schema fields of a stream and the values of
+// its columns, over all record batches.
+func decodeArrowStream(t *testing.T, data []byte) ([]arrowFieldDesc, [][]interface{}) {
+	t.Helper()
+	messages := readArrowStream(t, data)
+	if len(messages) == 0 || messages[0].headerType != arrowHeaderSchema {
+		t.Fatalf("stream does not start with a schema")
+	}
+	var fields []arrowFieldDesc
+	for _, f := range messages[0].header.tables(1) {
+		fields = append(fields, readArrowField(f))
+	}
+	columns := make([][]interface{}, len(fields))
+	for _, msg := range messages[1:] {
+		if msg.headerType != arrowHeaderRecordBatch {
+			t.Fatalf("unexpected message type %d", msg.headerType)
+		}
+		r := &arrowBatchReader{nodes: msg.header.structs(1), buffers: msg.header.structs(2), body: msg.body}
+		for i, f := range fields {
+			columns[i] = append(columns[i], r.read(f)...)
+		}
+	}
+	return fields, columns
+}
+
+// TestArrowGolden checks the Arrow output against a stream written by pyarrow
+// for the same rows (testdata/gen_fixtures.py, gen_arrow_stream).
+func TestArrowGolden(t *testing.T) {
+	golden, err := os.ReadFile(fixture("golden.arrows"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var info orderedGroup
+	info.add("city", parquet.String())
+	info.add("zip", parquet.Optional(parquet.Int(32)))
+	columns := []Column{
+		{Name: "id", Node: parquet.Int(64)},
+		{Name: "name", Node: parquet.Optional(parquet.String())},
+		{Name: "score", Node: parquet.Optional(parquet.Leaf(parquet.DoubleType))},
+		{Name: "tags", Node: parquet.Optional(parquet.List(parquet.String()))},
+		{Name: "attrs", Node: parquet.Optional(parquet.Map(parquet.String(), parquet.Int(32)))},
+		{Name: "info", Node: parquet.Optional(info)},
+		{Name: "at", Node: parquet.Optional(parquet.Timestamp(parquet.Millisecond))},
+		{Name: "price", Node: parquet.Optional(parquet.Decimal(2, 9, parquet.Int32Type))},
+	}
+	rows := []map[string]interface{}{
+		{"id": int64(1), "name": "a", "score": 1.5, "tags": []interface{}{"x", "y"},
+			"attrs": map[string]interface{}{"k": int32(7)}, "info": map[string]interface{}{"city": "Paris", "zip": int32(75001)},
+			"at": "2024-01-02T03:04:05Z", "price": "1.50"},
+		{"id": int64(2)},
+		{"id": int64(3), "name": "ccc", "score": 2.0, "tags": []interface{}{}, "attrs": map[string]interface{}{},
+			"info": map[string]interface{}{"city": "Oslo", "zip": nil}, "at": "1969-12-31T23:59:59Z", "price": int32(-225)},
+	}
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, "arrow", columns)
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	wantFields, wantColumns := decodeArrowStream(t, golden)
+	gotFields, gotColumns := decodeArrowStream(t, buf.Bytes())
+	if !reflect.DeepEqual(gotFields, wantFields) {
+		t.Errorf("schema:\ngot  %+v\nwant %+v", gotFields, wantFields)
+	}
+	if !reflect.DeepEqual(gotColumns, wantColumns) {
+		t.Errorf("values:\ngot  %v\nwant %v", gotColumns, wantColumns)
+	}
+}
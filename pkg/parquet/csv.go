@@ -0,0 +1,98 @@
+package parquet
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// csvWriter writes rows as CSV or TSV with a header row. Structs are
+// flattened into dotted column names ("info.address.city"); lists and maps
+// are written as JSON, or exploded into several rows.
+type csvWriter struct {
+	w         *csv.Writer
+	flat      *flattener
+	header    []string
+	nullValue string
+	started   bool
+}
+
+func newCSVWriter(w io.Writer, comma rune, columns []Column, cfg *outputConfig) *csvWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	flat := newFlattener(columns, cfg)
+	return &csvWriter{w: cw, flat: flat, header: flat.names(), nullValue: cfg.nullValue}
+}
+
+func (w *csvWriter) writeHeader() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.w.Write(w.header)
+}
+
+func (w *csvWriter) WriteRow(row map[string]interface{}) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	record := make([]string, len(w.header))
+	for _, values := range w.flat.flatten(row) {
+		for i, name := range w.header {
+			cell, err := formatCell(values[name], w.nullValue)
+			if err != nil {
+				return fmt.Errorf("column %s: %v", name, err)
+			}
+			record[i] = cell
+		}
+		if err := w.w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *csvWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// formatCell renders a flattened value as text. Lists and maps that were not
+// exploded are JSON-encoded; binary values are base64-encoded as in JSON
+// output.
+func formatCell(v interface{}, nullValue string) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nullValue, nil
+	case string:
+		return v, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case []interface{}, map[string]interface{}, OrderedRow:
+		data, err := (&jsonEncoder{}).encode(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+// isComposite reports whether node holds lists or maps rather than a single
+// value or a struct.
+func isComposite(node parquet.Node) bool {
+	return node.Repeated() || isListNode(node) || isMapNode(node)
+}
@@ -0,0 +1,163 @@
+package parquet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func writeCSV(t *testing.T, format string, columns []Column, rows []map[string]interface{}, opts ...OutputOption) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, format, columns, opts...)
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return buf.String()
+}
+
+func TestCSVWriter(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Node: parquet.Int(64)},
+		{Name: "info", Node: parquet.Group{
+			"city": parquet.Optional(parquet.String()),
+			"zip":  parquet.String(),
+		}},
+		{Name: "tags", Node: parquet.List(parquet.String())},
+		{Name: "attrs", Node: parquet.Map(parquet.String(), parquet.Int(64))},
+	}
+	rows := []map[string]interface{}{
+		{
+			"id":    int64(1),
+			"info":  map[string]interface{}{"city": "Paris, France", "zip": "75001"},
+			"tags":  []interface{}{"a", "b"},
+			"attrs": map[string]interface{}{"x": int64(1), "y": int64(2)},
+		},
+		{
+			"id":    int64(2),
+			"info":  map[string]interface{}{"city": nil, "zip": `say "hi"`},
+			"tags":  []interface{}{},
+			"attrs": nil,
+		},
+	}
+
+	t.Run("lists as json", func(t *testing.T) {
+		got := writeCSV(t, "csv", columns, rows)
+		want := "id,info.city,info.zip,tags,attrs\n" +
+			`1,"Paris, France",75001,"[""a"",""b""]","{""x"":1,""y"":2}"` + "\n" +
+			`2,,"say ""hi""",[],` + "\n"
+		if got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("explode", func(t *testing.T) {
+		got := writeCSV(t, "csv", columns, rows, WithExplode(), WithNullValue("NULL"))
+		want := "id,info.city,info.zip,tags,attrs.key,attrs.value\n" +
+			"1,\"Paris, France\",75001,a,x,1\n" +
+			"1,\"Paris, France\",75001,a,y,2\n" +
+			"1,\"Paris, France\",75001,b,x,1\n" +
+			"1,\"Paris, France\",75001,b,y,2\n" +
+			"2,NULL,\"say \"\"hi\"\"\",NULL,NULL,NULL\n"
+		if got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("tsv", func(t *testing.T) {
+		got := writeCSV(t, "tsv", columns[:2], rows[:1])
+		want := "id\tinfo.city\tinfo.zip\n1\tParis, France\t75001\n"
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("header without rows", func(t *testing.T) {
+		if got := writeCSV(t, "csv", columns[:1], nil); got != "id\n" {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := NewRowWriter(&bytes.Buffer{}, "xml", columns); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestFormatCell(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, `\N`},
+		{"text", "text"},
+		{true, "true"},
+		{int32(-7), "-7"},
+		{float32(0.1), "0.1"},
+		{1e21, "1e+21"},
+		{[]byte{0xff, 0x00}, "/wA="},
+		{[]interface{}{int64(1), nil}, "[1,null]"},
+	}
+	for _, tc := range cases {
+		got, err := formatCell(tc.v, `\N`)
+		if err != nil || got != tc.want {
+			t.Errorf("formatCell(%#v) = %q, %v; want %q", tc.v, got, err, tc.want)
+		}
+	}
+}
+
+func TestCSVFromFile(t *testing.T) {
+	read := func(t *testing.T, name string, opts ...OutputOption) []string {
+		t.Helper()
+		ds, err := OpenDataset([]string{fixture(name)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer ds.Close()
+		columns, err := ds.Columns()
+		if err != nil {
+			t.Fatalf("Columns: %v", err)
+		}
+		rows, err := ds.Head(2)
+		if err != nil {
+			t.Fatalf("Head: %v", err)
+		}
+		return strings.Split(strings.TrimSpace(writeCSV(t, "csv", columns, rows, opts...)), "\n")
+	}
+
+	t.Run("nested struct", func(t *testing.T) {
+		lines := read(t, "nested_struct.parquet")
+		if lines[0] != "id,info.name,info.address.city,info.address.zip" {
+			t.Errorf("unexpected header %q", lines[0])
+		}
+		if lines[1] != "id_0,user_0,city_0,10000" {
+			t.Errorf("unexpected row %q", lines[1])
+		}
+	})
+
+	t.Run("exploded lists", func(t *testing.T) {
+		lines := read(t, "list_primitive.parquet", WithExplode())
+		want := []string{
+			"id,tags,scores",
+			"id_0,tag_0,0",
+			"id_1,tag_0,0",
+			"id_1,tag_0,0.1",
+			"id_1,tag_1,0",
+			"id_1,tag_1,0.1",
+		}
+		if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+			t.Errorf("got %q, want %q", lines, want)
+		}
+	})
+}
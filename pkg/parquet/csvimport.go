@@ -0,0 +1,543 @@
+package parquet
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// headerMode tells whether the first row of a CSV file names its columns.
+type headerMode int
+
+const (
+	// headerAuto guesses from the values of the first rows
+	headerAuto headerMode = iota
+	headerYes
+	headerNo
+)
+
+// WithHeader tells whether the first CSV row names the columns, instead of
+// guessing it. Without a header the columns are named column_1, column_2...
+func WithHeader(header bool) ImportOption {
+	return func(c *importConfig) {
+		if header {
+			c.header = headerYes
+		} else {
+			c.header = headerNo
+		}
+	}
+}
+
+// WithDelimiter separates CSV fields with delimiter, a comma by default.
+func WithDelimiter(delimiter rune) ImportOption {
+	return func(c *importConfig) {
+		c.delimiter = delimiter
+	}
+}
+
+// WithQuote quotes CSV fields with quote, a double quote by default. A quote
+// of 0 reads quotes as any other character.
+func WithQuote(quote rune) ImportOption {
+	return func(c *importConfig) {
+		c.quote = quote
+	}
+}
+
+// WithNullValues reads CSV fields equal to one of tokens, such as "NULL" or
+// "\N", as nulls. Empty fields are always null.
+func WithNullValues(tokens ...string) ImportOption {
+	return func(c *importConfig) {
+		c.nullValues = append(c.nullValues, tokens...)
+	}
+}
+
+// headerSampleRows is the number of rows after the first one looked at to
+// guess whether the first one is a header.
+const headerSampleRows = 100
+
+// ImportCSV reads delimited text, such as CSV or TSV, and writes it to w as
+// a Parquet file. Unless a schema is given, every column is an optional
+// boolean, integer, decimal, double, date, timestamp or string, whichever
+// holds all of its values among the leading rows (see WithInferRows). The
+// types of single columns can be set with WithColumnType. It returns the
+// number of rows written.
+func ImportCSV(r io.Reader, w io.Writer, opts ...ImportOption) (int64, error) {
+	cfg := newImportConfig(opts)
+	cr := &csvReader{r: bufio.NewReader(r), delimiter: cfg.delimiter, quote: cfg.quote}
+	var rows [][]string
+	var lines []int
+	for len(rows) <= headerSampleRows {
+		row, line, err := cr.read()
+		if err != nil {
+			return 0, err
+		}
+		if row == nil {
+			break
+		}
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+	if len(rows) == 0 {
+		if cfg.schema == nil {
+			return 0, fmt.Errorf("no records to infer a schema from")
+		}
+		end := func() (map[string]interface{}, int, error) { return nil, 0, nil }
+		return importRecords(w, cfg, end, nil, csvRow)
+	}
+
+	columns, header, err := csvColumns(cfg, rows[0], rows[1:])
+	if err != nil {
+		return 0, err
+	}
+	if header {
+		rows, lines = rows[1:], lines[1:]
+	}
+	next := func() (map[string]interface{}, int, error) {
+		var row []string
+		var line int
+		if len(rows) > 0 {
+			row, line = rows[0], lines[0]
+			rows, lines = rows[1:], lines[1:]
+		} else {
+			var err error
+			if row, line, err = cr.read(); err != nil || row == nil {
+				return nil, line, err
+			}
+		}
+		if len(row) != len(columns) {
+			return nil, line, fmt.Errorf("line %d: expected %d fields, got %d", line, len(columns), len(row))
+		}
+		record := make(map[string]interface{}, len(columns))
+		for i, name := range columns {
+			record[name] = cfg.csvText(row[i])
+		}
+		return record, line, nil
+	}
+	infer := func(records []map[string]interface{}) *parquet.Schema {
+		return inferCSVSchema(columns, records)
+	}
+	return importRecords(w, cfg, next, infer, csvRow)
+}
+
+// inferCSVSchema returns a schema holding records of CSV text, with the
+// columns in header order.
+func inferCSVSchema(columns []string, records []map[string]interface{}) *parquet.Schema {
+	var group orderedGroup
+	for _, name := range columns {
+		var c csvColumn
+		for _, record := range records {
+			if s, ok := record[name].(string); ok {
+				c.observe(s)
+			}
+		}
+		group.add(name, c.node())
+	}
+	return parquet.NewSchema("schema", group)
+}
+
+// csvText returns the value of a CSV field, nil for nulls.
+func (c *importConfig) csvText(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	for _, token := range c.nullValues {
+		if s == token {
+			return nil
+		}
+	}
+	return s
+}
+
+// csvColumns returns the column names of a CSV file whose first row is first
+// and whether that row is a header, guessed from the rows of sample unless
+// set by WithHeader.
+func csvColumns(cfg *importConfig, first []string, sample [][]string) ([]string, bool, error) {
+	header := cfg.header == headerYes
+	if cfg.header == headerAuto {
+		if cfg.schema != nil {
+			header = true
+			for _, name := range first {
+				if fieldByName(cfg.schema, name) == nil {
+					header = false
+				}
+			}
+		} else {
+			header = guessHeader(cfg, first, sample)
+		}
+	}
+
+	if !header {
+		if cfg.schema != nil {
+			return nil, false, fmt.Errorf("cannot match the columns to the schema without a header")
+		}
+		columns := make([]string, len(first))
+		for i := range columns {
+			columns[i] = fmt.Sprintf("column_%d", i+1)
+		}
+		return columns, false, nil
+	}
+	seen := make(map[string]bool, len(first))
+	for i, name := range first {
+		switch {
+		case name == "":
+			return nil, false, fmt.Errorf("column %d has no name", i+1)
+		case seen[name]:
+			return nil, false, fmt.Errorf("duplicate column %s", name)
+		case cfg.schema != nil && fieldByName(cfg.schema, name) == nil:
+			return nil, false, fmt.Errorf("column %s is not in the schema", name)
+		}
+		seen[name] = true
+	}
+	return first, true, nil
+}
+
+// guessHeader reports whether first looks like a header rather than the
+// first of the rows of sample: its values must be distinct and not empty,
+// and either turn a column of numbers, dates or booleans into text, or, when
+// no column tells, all be plain text.
+func guessHeader(cfg *importConfig, first []string, sample [][]string) bool {
+	seen := make(map[string]bool, len(first))
+	for _, name := range first {
+		if name == "" || seen[name] {
+			return false
+		}
+		seen[name] = true
+	}
+	for i, name := range first {
+		var c csvColumn
+		for _, row := range sample {
+			if i < len(row) {
+				if s, ok := cfg.csvText(row[i]).(string); ok {
+					c.observe(s)
+				}
+			}
+		}
+		if kind := c.kind(); kind == csvNull || kind == csvString {
+			continue
+		}
+		if c.observe(name); c.kind() == csvString {
+			return true
+		}
+	}
+	for _, name := range first {
+		var c csvColumn
+		c.observe(name)
+		if c.kind() != csvString {
+			return false
+		}
+	}
+	return true
+}
+
+// csvKind is the type inferred for a CSV column.
+type csvKind int
+
+const (
+	csvNull csvKind = iota
+	csvBool
+	csvInt
+	csvDecimal
+	csvDouble
+	csvDate
+	csvTimestamp
+	csvString
+)
+
+// csvColumn accumulates the values seen in a CSV column, ruling out the
+// types some value does not parse as.
+type csvColumn struct {
+	// values counts the values seen
+	values int
+	// not* rule out the types some value does not parse as
+	notBool, notInt, notDecimal, notFloat, notDate, notTime bool
+	// scale is the number of fraction digits of every value, for decimals
+	scale int
+	// digits is the largest number of integer digits of the values
+	digits int
+	// nanos is set once a timestamp has more than microsecond precision
+	nanos bool
+}
+
+func (c *csvColumn) observe(s string) {
+	c.values++
+	if !strings.EqualFold(s, "true") && !strings.EqualFold(s, "false") {
+		c.notBool = true
+	}
+	// zero-padded numbers, such as zip codes, are kept as text
+	digits := strings.TrimPrefix(s, "-")
+	padded := len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9'
+	if _, err := strconv.ParseInt(s, 10, 64); err != nil || padded {
+		c.notInt = true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil || padded {
+		c.notFloat = true
+	}
+	whole, fraction, ok := plainDecimal(digits)
+	switch {
+	case !ok || fraction == 0 || padded:
+		c.notDecimal = true
+	case c.scale == 0:
+		c.scale = fraction
+	case c.scale != fraction:
+		c.notDecimal = true
+	}
+	if whole > c.digits {
+		c.digits = whole
+	}
+	if _, err := parseDate(s); err != nil {
+		c.notDate = true
+	}
+	if _, err := parseTimestamp(normalizeCSVTimestamp(s)); err != nil {
+		c.notTime = true
+	} else if i := strings.IndexByte(s, '.'); i > 0 {
+		n := 0
+		for n < len(s)-i-1 && s[i+1+n] >= '0' && s[i+1+n] <= '9' {
+			n++
+		}
+		if n > 6 {
+			c.nanos = true
+		}
+	}
+}
+
+// plainDecimal returns the number of integer and fraction digits of an
+// unsigned number written without an exponent.
+func plainDecimal(s string) (int, int, bool) {
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if whole == "" {
+		return 0, 0, false
+	}
+	for _, part := range []string{whole, fraction} {
+		for i := 0; i < len(part); i++ {
+			if part[i] < '0' || part[i] > '9' {
+				return 0, 0, false
+			}
+		}
+	}
+	return len(whole), len(fraction), true
+}
+
+// kind returns the narrowest type holding every value seen: integers before
+// decimals, which keep their exact digits, before doubles, and dates before
+// timestamps.
+func (c *csvColumn) kind() csvKind {
+	switch {
+	case c.values == 0:
+		return csvNull
+	case !c.notBool:
+		return csvBool
+	case !c.notInt:
+		return csvInt
+	case !c.notDecimal && c.digits+c.scale <= 38:
+		return csvDecimal
+	case !c.notFloat:
+		return csvDouble
+	case !c.notDate:
+		return csvDate
+	case !c.notTime:
+		return csvTimestamp
+	}
+	return csvString
+}
+
+// node returns the optional Parquet node of the column. Decimals get the
+// largest precision of their physical type, so that later rows may hold
+// larger values than the ones seen.
+func (c *csvColumn) node() parquet.Node {
+	var node parquet.Node
+	switch c.kind() {
+	case csvBool:
+		node = parquet.Leaf(parquet.BooleanType)
+	case csvInt:
+		node = parquet.Int(64)
+	case csvDecimal:
+		precision := 38
+		switch p := c.digits + c.scale; {
+		case p <= 9:
+			precision = 9
+		case p <= 18:
+			precision = 18
+		}
+		node = parquet.Decimal(c.scale, precision, decimalType(precision))
+	case csvDouble:
+		node = parquet.Leaf(parquet.DoubleType)
+	case csvDate:
+		node = parquet.Date()
+	case csvTimestamp:
+		if c.nanos {
+			node = parquet.Timestamp(parquet.Nanosecond)
+		} else {
+			node = parquet.Timestamp(parquet.Microsecond)
+		}
+	default:
+		node = parquet.String()
+	}
+	return parquet.Optional(node)
+}
+
+// normalizeCSVTimestamp returns s, a timestamp as written in CSV files, in the
+// layout parseTimestamp reads: dates are midnight, and the date and time may
+// be separated by a space.
+func normalizeCSVTimestamp(s string) string {
+	if len(s) == len("2006-01-02") {
+		return s + "T00:00:00"
+	}
+	if len(s) > 10 && s[10] == ' ' {
+		return s[:10] + "T" + s[11:]
+	}
+	return s
+}
+
+// csvRow converts a record of CSV fields to the physical values of the
+// fields of node.
+func csvRow(node parquet.Node, record map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(record))
+	for _, field := range node.Fields() {
+		v, err := csvValue(field, record[field.Name()])
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", field.Name(), err)
+		}
+		out[field.Name()] = v
+	}
+	return out, nil
+}
+
+// csvValue converts a CSV field, nil for nulls, to the physical value of
+// node. Lists, maps and structs are read from JSON text.
+func csvValue(node parquet.Node, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return importValue(node, v)
+	}
+	if !node.Leaf() || isComposite(node) {
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		var decoded interface{}
+		if err := dec.Decode(&decoded); err != nil || dec.More() {
+			return nil, fmt.Errorf("invalid JSON %q", s)
+		}
+		return importValue(node, decoded)
+	}
+
+	t := node.Type()
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	switch {
+	case lt != nil && lt.Json != nil:
+		if !json.Valid([]byte(s)) {
+			return nil, fmt.Errorf("invalid JSON %q", s)
+		}
+		return []byte(s), nil
+	case t.Kind() == parquet.Boolean:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected a boolean, got %q", s)
+		}
+		return b, nil
+	case t.Kind() == parquet.Float:
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", s)
+		}
+		return float32(f), nil
+	case t.Kind() == parquet.Double:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", s)
+		}
+		return f, nil
+	case (t.Kind() == parquet.Int32 || t.Kind() == parquet.Int64) && (lt == nil || lt.Integer != nil):
+		n, err := toInt64(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", s)
+		}
+		return physicalInt(t, n)
+	case t.Kind() == parquet.Int96 || lt != nil && lt.Timestamp != nil:
+		s = normalizeCSVTimestamp(s)
+	}
+	return importValue(node, s)
+}
+
+// csvReader reads the rows of delimited text. Quoted fields may hold
+// delimiters, newlines and doubled quotes; lines may end with CRLF.
+type csvReader struct {
+	r         *bufio.Reader
+	delimiter rune
+	quote     rune
+	// line is the number of lines read so far
+	line int
+}
+
+// read returns the fields of the next row and the line it starts on, or nil
+// fields at the end of the input. Blank lines are skipped.
+func (cr *csvReader) read() ([]string, int, error) {
+	var fields []string
+	var field strings.Builder
+	start := cr.line + 1
+	// quoted is set inside quotes; wasQuoted once the field has been quoted
+	quoted, wasQuoted := false, false
+	for {
+		c, _, err := cr.r.ReadRune()
+		if err == io.EOF {
+			if quoted {
+				return nil, start, fmt.Errorf("line %d: unterminated quoted field", start)
+			}
+			last := strings.TrimSuffix(field.String(), "\r")
+			if fields == nil && last == "" && !wasQuoted {
+				return nil, start, nil
+			}
+			cr.line++
+			return append(fields, last), start, nil
+		}
+		if err != nil {
+			return nil, start, err
+		}
+
+		switch {
+		case quoted:
+			if c == cr.quote {
+				next, _, err := cr.r.ReadRune()
+				if err == nil && next == cr.quote {
+					field.WriteRune(c)
+					continue
+				}
+				if err == nil {
+					cr.r.UnreadRune()
+				}
+				quoted = false
+				continue
+			}
+			if c == '\n' {
+				cr.line++
+			}
+			field.WriteRune(c)
+		case c == cr.quote && cr.quote != 0 && field.Len() == 0 && !wasQuoted:
+			quoted, wasQuoted = true, true
+		case c == cr.delimiter:
+			fields = append(fields, field.String())
+			field.Reset()
+			wasQuoted = false
+		case c == '\n':
+			cr.line++
+			last := strings.TrimSuffix(field.String(), "\r")
+			if fields == nil && last == "" && !wasQuoted {
+				// a blank line
+				start = cr.line + 1
+				continue
+			}
+			return append(fields, last), start, nil
+		default:
+			field.WriteRune(c)
+		}
+	}
+}
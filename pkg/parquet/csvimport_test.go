@@ -0,0 +1,254 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func readCSV(t *testing.T, input string, delimiter, quote rune) [][]string {
+	t.Helper()
+	cr := &csvReader{r: bufio.NewReader(strings.NewReader(input)), delimiter: delimiter, quote: quote}
+	var rows [][]string
+	for {
+		row, _, err := cr.read()
+		if err != nil {
+			t.Fatalf("read %q: %v", input, err)
+		}
+		if row == nil {
+			return rows
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	for input, want := range map[string]string{
+		"a,b\n1,2\n":              `[["a","b"],["1","2"]]`,
+		"a,b\r\n1,2":              `[["a","b"],["1","2"]]`,
+		"a,b\n\n1,\n":             `[["a","b"],["1",""]]`,
+		`"x,y","say ""hi""",z`:    `[["x,y","say \"hi\"","z"]]`,
+		"\"two\nlines\",b\nc,d\n": `[["two\nlines","b"],["c","d"]]`,
+		"\"\"\n":                  `[[""]]`,
+		"a\"b,c":                  `[["a\"b","c"]]`,
+	} {
+		got, _ := json.Marshal(readCSV(t, input, ',', '"'))
+		if string(got) != want {
+			t.Errorf("%q: got %s, want %s", input, got, want)
+		}
+	}
+
+	got, _ := json.Marshal(readCSV(t, "\"a\"\tb\n", '\t', 0))
+	if want := `[["\"a\"","b"]]`; string(got) != want {
+		t.Errorf("without quotes: got %s, want %s", got, want)
+	}
+
+	cr := &csvReader{r: bufio.NewReader(strings.NewReader("a,b\n\"c\nd")), delimiter: ',', quote: '"'}
+	cr.read()
+	if _, _, err := cr.read(); err == nil || !strings.Contains(err.Error(), "line 2: unterminated") {
+		t.Errorf("got error %v, want an unterminated field on line 2", err)
+	}
+}
+
+func TestCSVColumnTypes(t *testing.T) {
+	column := func(values ...string) *csvColumn {
+		c := &csvColumn{}
+		for _, v := range values {
+			c.observe(v)
+		}
+		return c
+	}
+	for _, tc := range []struct {
+		values []string
+		want   csvKind
+	}{
+		{[]string{"true", "FALSE"}, csvBool},
+		{[]string{"1", "-20", "0"}, csvInt},
+		{[]string{"12.50", "-3.25"}, csvDecimal},
+		{[]string{"1.5", "2.25"}, csvDouble},
+		{[]string{"1.50", "7"}, csvDouble},
+		{[]string{"1e3", "0.5"}, csvDouble},
+		{[]string{"2024-01-31", "1999-12-01"}, csvDate},
+		{[]string{"2024-01-31 10:00:00", "2024-02-01T00:00:00.5Z", "2024-03-01"}, csvTimestamp},
+		{[]string{"00123", "00456"}, csvString},
+		{[]string{"1", "x"}, csvString},
+		{nil, csvNull},
+	} {
+		if got := column(tc.values...).kind(); got != tc.want {
+			t.Errorf("%v: got kind %d, want %d", tc.values, got, tc.want)
+		}
+	}
+
+	node := column("12.50", "-3.25").node()
+	if d := node.Type().LogicalType().Decimal; !node.Optional() || d.Scale != 2 || d.Precision != 9 || node.Type().Kind() != parquet.Int32 {
+		t.Errorf("expected an optional DECIMAL(9,2) stored as INT32")
+	}
+	node = column("1234567890123456789.00").node()
+	if d := node.Type().LogicalType().Decimal; d.Precision != 38 || node.Type().Length() != 16 {
+		t.Errorf("expected a DECIMAL(38,2) stored in 16 bytes")
+	}
+	node = column("2024-01-31T10:00:00.123456789").node()
+	if node.Type().LogicalType().Timestamp.Unit.Nanos == nil {
+		t.Errorf("expected nanosecond timestamps")
+	}
+	node = column("2024-01-31 10:00:00.5").node()
+	if node.Type().LogicalType().Timestamp.Unit.Micros == nil {
+		t.Errorf("expected microsecond timestamps")
+	}
+}
+
+func TestInferCSVSchemaOrder(t *testing.T) {
+	columns := []string{"zeta", "alpha", "mid"}
+	records := []map[string]interface{}{{"zeta": "1", "alpha": "a", "mid": "true"}}
+	var names []string
+	for _, field := range inferCSVSchema(columns, records).Fields() {
+		names = append(names, field.Name())
+	}
+	if got := strings.Join(names, ","); got != "zeta,alpha,mid" {
+		t.Errorf("got fields %s, want the header order zeta,alpha,mid", got)
+	}
+}
+
+func TestGuessHeader(t *testing.T) {
+	cfg := newImportConfig(nil)
+	for input, want := range map[string]bool{
+		"id,name\n1,a\n2,b\n":            true,
+		"1,a\n2,b\n":                     false,
+		"name,city\nAnn,Oslo\n":          true,
+		"a,a\n1,2\n":                     false,
+		"when,n\n2024-01-01,1.5\n":       true,
+		"2024-01-01,1.5\n2024-01-02,2\n": false,
+		"x,y\n":                          true,
+		"1,2\n":                          false,
+	} {
+		rows := readCSV(t, input, ',', '"')
+		if got := guessHeader(cfg, rows[0], rows[1:]); got != want {
+			t.Errorf("%q: got %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestCSVRow(t *testing.T) {
+	schema := parquet.NewSchema("schema", parquet.Group{
+		"id":    parquet.Optional(parquet.Int(32)),
+		"ok":    parquet.Optional(parquet.Leaf(parquet.BooleanType)),
+		"at":    parquet.Optional(parquet.Timestamp(parquet.Millisecond)),
+		"price": parquet.Optional(parquet.Decimal(2, 9, parquet.Int32Type)),
+		"tags":  parquet.Optional(parquet.List(parquet.String())),
+		"note":  parquet.Optional(parquet.String()),
+		"meta":  parquet.Optional(parquet.JSON()),
+	})
+	row, err := csvRow(schema, map[string]interface{}{
+		"id": "7", "ok": "1", "at": "1970-01-01 00:00:01.5", "price": "12.34",
+		"tags": `["x","y"]`, "note": nil, "meta": `{"a":1}`,
+	})
+	if err != nil {
+		t.Fatalf("csvRow: %v", err)
+	}
+	want := map[string]interface{}{
+		"id":    int32(7),
+		"ok":    true,
+		"at":    int64(1500),
+		"price": int32(1234),
+		"tags":  []interface{}{[]byte("x"), []byte("y")},
+		"note":  nil,
+		"meta":  []byte(`{"a":1}`),
+	}
+	got, _ := json.Marshal(row)
+	wanted, _ := json.Marshal(want)
+	if string(got) != string(wanted) {
+		t.Errorf("got  %s\nwant %s", got, wanted)
+	}
+
+	for record, wantErr := range map[string]string{
+		"id=x":     `column id: expected an integer, got "x"`,
+		"ok=maybe": "column ok: expected a boolean",
+		"tags=x":   "column tags: invalid JSON",
+		"at=noon":  "column at: invalid timestamp",
+	} {
+		kv := strings.SplitN(record, "=", 2)
+		_, err := csvRow(schema, map[string]interface{}{kv[0]: kv[1]})
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: got error %v, want %q", record, err, wantErr)
+		}
+	}
+}
+
+func TestImportCSVErrors(t *testing.T) {
+	cases := map[string]string{
+		"":                   "no records",
+		"id,name\n":          "no records",
+		"id,name\n1,a\n2\n":  "line 3: expected 2 fields, got 1",
+		"id,n\n1,2\n\nx,3\n": "line 4: column id: expected an integer",
+		"id,n\n1,\"2\n":      "line 2: unterminated quoted field",
+	}
+	for input, want := range cases {
+		_, err := ImportCSV(strings.NewReader(input), &bytes.Buffer{}, WithInferRows(1))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: got error %v, want %q", input, err, want)
+		}
+	}
+
+	_, err := ImportCSV(strings.NewReader("id\n1\n"), &bytes.Buffer{}, WithColumnType("other", "int32"))
+	if err == nil || !strings.Contains(err.Error(), "cannot set the type of other: no such column") {
+		t.Errorf("got error %v, want an unknown column", err)
+	}
+	schema := parquet.NewSchema("schema", parquet.Group{"id": parquet.Int(64)})
+	_, err = ImportCSV(strings.NewReader("id,x\n1,2\n"), &bytes.Buffer{}, WithImportSchema(schema), WithHeader(true))
+	if err == nil || !strings.Contains(err.Error(), "column x is not in the schema") {
+		t.Errorf("got error %v, want a column missing from the schema", err)
+	}
+}
+
+func TestImportCSVRoundTrip(t *testing.T) {
+	input := "id;name;ok;score\r\n1;\"a;b\";true;1.5\r\n2;NA;false;\r\n3;c;;2.25\r\n"
+	path := filepath.Join(t.TempDir(), "out.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := ImportCSV(strings.NewReader(input), f, WithDelimiter(';'), WithNullValues("NA"), WithColumnType("id", "int32"))
+	f.Close()
+	if err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("got %d rows, want 3", n)
+	}
+
+	ds, err := OpenDataset([]string{path})
+	if err != nil {
+		t.Fatalf("OpenDataset: %v", err)
+	}
+	defer ds.Close()
+	columns, err := ds.Columns()
+	if err != nil {
+		t.Fatalf("Columns: %v", err)
+	}
+	for _, c := range columns {
+		if c.Name == "id" && c.Node.Type().Kind() != parquet.Int32 {
+			t.Errorf("id: got %v, want INT32", c.Node.Type().Kind())
+		}
+	}
+	rows, err := ds.Head(10)
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := PrintJSON(rows, &buf, false); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":1,"name":"a;b","ok":true,"score":1.5}
+{"id":2,"name":null,"ok":false,"score":null}
+{"id":3,"name":"c","ok":null,"score":2.25}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
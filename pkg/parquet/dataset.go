@@ -0,0 +1,588 @@
+package parquet
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Dataset reads a list of Parquet files as one sequence of rows. Files are
+// opened one at a time, so large datasets do not exhaust file descriptors.
+//
+// Hive-style key=value directories (events/date=2024-01-01/part-0.parquet)
+// add virtual partition columns to every row. They can be selected with
+// WithColumns and filtered with WithFilter; files whose partition values rule
+// out any match are skipped without being opened. Partition values take
+// precedence over file columns of the same name.
+type Dataset struct {
+	files   []datasetFile
+	stdin   *ParquetReader
+	checked bool
+
+	// partition columns added to every row, and whether rows hold nothing
+	// else because only partition columns were selected
+	partitionKeys  []string
+	partitionsOnly bool
+
+	// rows skipped or replaced by nulls under the error policy
+	badRows []BadRows
+
+	// the schema of the Parquet writer, set when files of the same schema
+	// can have their rows copied as stored
+	copySchema *parquet.Schema
+}
+
+// BadRows lists the unreadable rows of a file that were skipped or replaced
+// by nulls, following the error policy (see WithErrorPolicy).
+type BadRows struct {
+	File string
+	// Rows are row indices in the file, counting from 0.
+	Rows []int64
+}
+
+// datasetFile is an input file with its partition values and the reader
+// options it is opened with.
+type datasetFile struct {
+	path       string
+	partitions map[string]interface{}
+	opts       []ReaderOption
+}
+
+// OpenDataset expands inputs (see ExpandPaths). "-" reads stdin and cannot be
+// combined with other inputs. Methods that combine rows of several files
+// first check that their schemas are compatible.
+func OpenDataset(inputs []string, opts ...ReaderOption) (*Dataset, error) {
+	files, err := ExpandPaths(inputs)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dataset{}
+
+	for _, f := range files {
+		if f == "-" && len(files) > 1 {
+			return nil, fmt.Errorf("stdin (-) cannot be combined with other inputs")
+		}
+	}
+	if len(files) == 1 && files[0] == "-" {
+		d.stdin, err = NewParquetReaderFromStream(os.Stdin, opts...)
+		if err != nil {
+			return nil, err
+		}
+		d.files = []datasetFile{{path: "-", opts: opts}}
+		return d, nil
+	}
+	if err := d.plan(files, opts); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// plan works out the partition values and reader options of every file and
+// drops the files whose partition values rule out any match of the filter.
+func (d *Dataset) plan(files []string, opts []ReaderOption) error {
+	var keys []string
+	isKey := make(map[string]bool)
+	raw := make([]map[string]string, len(files))
+	for i, path := range files {
+		fileKeys, values := parsePartitions(path)
+		for _, key := range fileKeys {
+			if !isKey[key] {
+				isKey[key] = true
+				keys = append(keys, key)
+			}
+		}
+		raw[i] = values
+	}
+	if len(keys) == 0 {
+		for _, path := range files {
+			d.files = append(d.files, datasetFile{path: path, opts: opts})
+		}
+		return nil
+	}
+	partitions, text := partitionValues(keys, raw), partitionText(keys, raw)
+
+	// Partition columns are not stored in the files: take them out of the
+	// projection, and bind them in the filter to each file's values.
+	cfg := newReaderConfig(opts)
+	fileOpts := append([]ReaderOption{}, opts...)
+	d.partitionKeys = keys
+	if len(cfg.columns) > 0 {
+		var columns [][]string
+		d.partitionKeys = nil
+		for _, path := range cfg.columns {
+			if len(path) == 1 && isKey[path[0]] {
+				d.partitionKeys = append(d.partitionKeys, path[0])
+			} else {
+				columns = append(columns, path)
+			}
+		}
+		fileOpts = append(fileOpts, withColumnPaths(columns))
+		d.partitionsOnly = len(columns) == 0
+	}
+
+	var filter *Filter
+	if cfg.where != "" {
+		var err error
+		if filter, err = ParseFilter(cfg.where); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+	}
+	for i, path := range files {
+		f := datasetFile{path: path, partitions: partitions[i], opts: fileOpts}
+		if filter != nil {
+			bound := filter.bind(partitions[i], text[i])
+			if match, ok := bound.constant(); ok {
+				if !match {
+					continue
+				}
+				bound = nil
+			}
+			f.opts = append(fileOpts[:len(fileOpts):len(fileOpts)], withBoundFilter(bound))
+		}
+		d.files = append(d.files, f)
+	}
+	return nil
+}
+
+// Files returns the list of files to read, after partition pruning.
+func (d *Dataset) Files() []string {
+	paths := make([]string, len(d.files))
+	for i, f := range d.files {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// Each opens every file in turn and passes its reader to fn. The reader is
+// closed when fn returns. Rows read through it carry no partition columns.
+func (d *Dataset) Each(fn func(path string, r *ParquetReader) error) error {
+	for _, f := range d.files {
+		if err := d.with(f, func(r *ParquetReader) error { return fn(f.path, r) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dataset) with(f datasetFile, fn func(r *ParquetReader) error) error {
+	if d.stdin != nil {
+		d.keepSourceRows(d.stdin)
+		return fn(d.stdin)
+	}
+	r, err := NewParquetReader(f.path, f.opts...)
+	if err != nil {
+		if len(d.files) > 1 {
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+		return err
+	}
+	defer r.Close()
+	d.keepSourceRows(r)
+	err = fn(r)
+	if rows := r.BadRows(); len(rows) > 0 {
+		d.badRows = append(d.badRows, BadRows{File: f.path, Rows: rows})
+	}
+	return err
+}
+
+// keepSourceRows makes r return its rows as stored when they can be copied
+// to the Parquet writer of the dataset: when the file has its schema.
+func (d *Dataset) keepSourceRows(r *ParquetReader) {
+	r.sourceRows = d.copySchema != nil && r.pfile.Schema().String() == d.copySchema.String()
+}
+
+// BadRows returns the unreadable rows met so far, by file.
+func (d *Dataset) BadRows() []BadRows {
+	if d.stdin != nil {
+		if rows := d.stdin.BadRows(); len(rows) > 0 {
+			return []BadRows{{File: "-", Rows: rows}}
+		}
+		return nil
+	}
+	return d.badRows
+}
+
+// Columns describes the rows returned by the dataset: the columns of the
+// first file (see ParquetReader.Columns) followed by the partition columns.
+func (d *Dataset) Columns() ([]Column, error) {
+	var columns []Column
+	if len(d.files) > 0 && !d.partitionsOnly {
+		err := d.with(d.files[0], func(r *ParquetReader) error {
+			columns = r.Columns()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range d.partitionKeys {
+		for i, col := range columns {
+			if col.Name == key {
+				columns = append(columns[:i], columns[i+1:]...)
+				break
+			}
+		}
+		node := parquet.Optional(parquet.Int(64))
+		for _, f := range d.files {
+			if _, ok := f.partitions[key].(string); ok {
+				node = parquet.Optional(parquet.String())
+				break
+			}
+		}
+		columns = append(columns, Column{Name: key, Node: node})
+	}
+	return columns, nil
+}
+
+// decorate adds the partition columns of f to rows read from it.
+func (d *Dataset) decorate(f datasetFile, rows []map[string]interface{}) []map[string]interface{} {
+	for i := range rows {
+		rows[i] = d.decorateRow(f, rows[i])
+	}
+	return rows
+}
+
+func (d *Dataset) decorateRow(f datasetFile, row map[string]interface{}) map[string]interface{} {
+	if d.partitionsOnly {
+		row = make(map[string]interface{}, len(d.partitionKeys))
+	}
+	for _, key := range d.partitionKeys {
+		row[key] = f.partitions[key]
+	}
+	return row
+}
+
+// checkSchemas verifies that every file can be read with the first file's
+// schema. It only opens the files the first time it is called.
+func (d *Dataset) checkSchemas() error {
+	if d.checked || len(d.files) < 2 {
+		return nil
+	}
+	d.checked = true
+	var first *parquet.Schema
+	var firstPath string
+	return d.Each(func(path string, r *ParquetReader) error {
+		schema := r.reader.Schema()
+		if first == nil {
+			first, firstPath = schema, path
+			return nil
+		}
+		if err := compatibleSchemas(first, schema); err != nil {
+			return fmt.Errorf("schema of %s is not compatible with %s: %v", path, firstPath, err)
+		}
+		return nil
+	})
+}
+
+// Head returns the first n rows across all files.
+func (d *Dataset) Head(n int) ([]map[string]interface{}, error) {
+	if err := d.checkSchemas(); err != nil {
+		return nil, err
+	}
+	if len(d.files) == 0 {
+		// every file was pruned by the filter
+		return []map[string]interface{}{}, nil
+	}
+	if len(d.files) == 1 {
+		var rows []map[string]interface{}
+		err := d.with(d.files[0], func(r *ParquetReader) (err error) {
+			rows, err = r.Head(n)
+			return err
+		})
+		return d.decorate(d.files[0], rows), err
+	}
+
+	result := make([]map[string]interface{}, 0)
+	nonEmpty := false
+	for _, f := range d.files {
+		if len(result) >= n {
+			break
+		}
+		err := d.with(f, func(r *ParquetReader) error {
+			if r.NumRows() == 0 {
+				return nil
+			}
+			nonEmpty = true
+			rows, err := r.Head(n - len(result))
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.path, err)
+			}
+			result = append(result, d.decorate(f, rows)...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(result) < n && !nonEmpty {
+		return nil, fmt.Errorf("%w: all %d input files have no rows", ErrEmptyFile, len(d.files))
+	}
+	return result, nil
+}
+
+// Tail returns the last n rows across all files.
+func (d *Dataset) Tail(n int) ([]map[string]interface{}, error) {
+	if err := d.checkSchemas(); err != nil {
+		return nil, err
+	}
+	if len(d.files) == 0 {
+		return []map[string]interface{}{}, nil
+	}
+	if len(d.files) == 1 {
+		var rows []map[string]interface{}
+		err := d.with(d.files[0], func(r *ParquetReader) (err error) {
+			rows, err = r.Tail(n)
+			return err
+		})
+		return d.decorate(d.files[0], rows), err
+	}
+
+	var result []map[string]interface{}
+	nonEmpty := false
+	for i := len(d.files) - 1; i >= 0 && len(result) < n; i-- {
+		f := d.files[i]
+		err := d.with(f, func(r *ParquetReader) error {
+			if r.NumRows() == 0 {
+				return nil
+			}
+			nonEmpty = true
+			rows, err := r.Tail(n - len(result))
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.path, err)
+			}
+			result = append(d.decorate(f, rows), result...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !nonEmpty {
+		return nil, fmt.Errorf("%w: all %d input files have no rows", ErrEmptyFile, len(d.files))
+	}
+	return result, nil
+}
+
+// Sample returns n rows drawn uniformly from all files, in file order.
+func (d *Dataset) Sample(n int) ([]map[string]interface{}, error) {
+	if err := d.checkSchemas(); err != nil {
+		return nil, err
+	}
+	if len(d.files) == 0 {
+		return []map[string]interface{}{}, nil
+	}
+	if len(d.files) == 1 {
+		var rows []map[string]interface{}
+		err := d.with(d.files[0], func(r *ParquetReader) (err error) {
+			rows, err = r.Sample(n)
+			return err
+		})
+		return d.decorate(d.files[0], rows), err
+	}
+
+	counts := make([]int64, len(d.files))
+	total := int64(0)
+	i := 0
+	err := d.Each(func(path string, r *ParquetReader) error {
+		c, err := r.Count()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		counts[i] = c
+		total += c
+		i++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: all %d input files have no rows", ErrEmptyFile, len(d.files))
+	}
+
+	// Draw distinct global row indices and count how many fall in each file;
+	// sampling that many rows per file keeps the overall sample uniform.
+	perFile := make([]int, len(d.files))
+	if int64(n) >= total {
+		for i, c := range counts {
+			perFile[i] = int(c)
+		}
+	} else {
+		indices := make([]int64, 0, n)
+		seen := make(map[int64]bool, n)
+		for len(indices) < n {
+			idx := rand.Int63n(total)
+			if !seen[idx] {
+				seen[idx] = true
+				indices = append(indices, idx)
+			}
+		}
+		sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+		file, offset := 0, int64(0)
+		for _, idx := range indices {
+			for idx >= offset+counts[file] {
+				offset += counts[file]
+				file++
+			}
+			perFile[file]++
+		}
+	}
+
+	result := make([]map[string]interface{}, 0, n)
+	for i, f := range d.files {
+		if perFile[i] == 0 {
+			continue
+		}
+		err := d.with(f, func(r *ParquetReader) error {
+			rows, err := r.Sample(perFile[i])
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.path, err)
+			}
+			result = append(result, d.decorate(f, rows)...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// StreamAll streams the rows of every file in turn.
+func (d *Dataset) StreamAll(fn func(row map[string]interface{}) error) error {
+	if err := d.checkSchemas(); err != nil {
+		return err
+	}
+	for _, f := range d.files {
+		err := d.with(f, func(r *ParquetReader) error {
+			return r.StreamAll(func(row map[string]interface{}) error {
+				return fn(d.decorateRow(f, row))
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns the total number of (matching) rows across all files.
+func (d *Dataset) Count() (int64, error) {
+	total := int64(0)
+	err := d.Each(func(path string, r *ParquetReader) error {
+		c, err := r.Count()
+		if err != nil {
+			return err
+		}
+		total += c
+		return nil
+	})
+	return total, err
+}
+
+// Close releases the stdin buffer, if any.
+func (d *Dataset) Close() error {
+	if d == nil || d.stdin == nil {
+		return nil
+	}
+	return d.stdin.Close()
+}
+
+// ExpandPaths turns command-line inputs into a list of files. Glob patterns
+// are expanded, directories are searched recursively for *.parquet files
+// (skipping names starting with "." or "_", such as _SUCCESS markers), and
+// "-" is kept as is to stand for stdin.
+func ExpandPaths(inputs []string) ([]string, error) {
+	var files []string
+	for _, input := range inputs {
+		if input == "-" {
+			files = append(files, input)
+			continue
+		}
+		matches := []string{input}
+		if strings.ContainsAny(input, "*?[") {
+			var err error
+			matches, err = filepath.Glob(input)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %v", input, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q: %w", input, fs.ErrNotExist)
+			}
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil || !info.IsDir() {
+				files = append(files, m)
+				continue
+			}
+			found, err := parquetFilesIn(m)
+			if err != nil {
+				return nil, err
+			}
+			if len(found) == 0 {
+				return nil, fmt.Errorf("no Parquet files found in %s: %w", m, fs.ErrNotExist)
+			}
+			files = append(files, found...)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no input files")
+	}
+	return files, nil
+}
+
+func parquetFilesIn(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if path != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(name), ".parquet") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", dir, err)
+	}
+	return files, nil
+}
+
+// compatibleSchemas checks that rows of b can be printed alongside rows of
+// a: both must have the same leaf columns with the same types.
+func compatibleSchemas(a, b *parquet.Schema) error {
+	aCols, bCols := a.Columns(), b.Columns()
+	for _, path := range aCols {
+		if _, ok := b.Lookup(path...); !ok {
+			return fmt.Errorf("column %q is missing", strings.Join(path, "."))
+		}
+	}
+	for _, path := range bCols {
+		bLeaf, _ := b.Lookup(path...)
+		aLeaf, ok := a.Lookup(path...)
+		if !ok {
+			return fmt.Errorf("unexpected column %q", strings.Join(path, "."))
+		}
+		if at, bt := aLeaf.Node.Type(), bLeaf.Node.Type(); at.Kind() != bt.Kind() || at.String() != bt.String() {
+			return fmt.Errorf("column %q has type %s, expected %s", strings.Join(path, "."), bt, at)
+		}
+		if aLeaf.MaxRepetitionLevel != bLeaf.MaxRepetitionLevel {
+			return fmt.Errorf("column %q has a different nesting", strings.Join(path, "."))
+		}
+	}
+	return nil
+}
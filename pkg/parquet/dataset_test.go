@@ -0,0 +1,225 @@
+package parquet
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// splitFixture copies a fixture into a temp dir and splits it into n parts.
+func splitFixture(t *testing.T, name string, n int) string {
+	t.Helper()
+	dir := t.TempDir()
+	tmp := filepath.Join(dir, name)
+	copyFile(t, fixture(name), tmp)
+	if err := SplitParquetFile(tmp, n); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := os.Remove(tmp); err != nil {
+		t.Fatalf("failed to remove %s: %v", tmp, err)
+	}
+	return dir
+}
+
+// --- ExpandPaths ---
+
+func TestExpandPaths(t *testing.T) {
+	dir := splitFixture(t, "flat.parquet", 3)
+	os.WriteFile(filepath.Join(dir, "_SUCCESS"), nil, 0644)
+	os.MkdirAll(filepath.Join(dir, "_temporary"), 0755)
+	copyFile(t, fixture("flat.parquet"), filepath.Join(dir, "_temporary", "part.parquet"))
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	copyFile(t, fixture("flat.parquet"), filepath.Join(dir, "sub", "flat_9.parquet"))
+
+	t.Run("directory", func(t *testing.T) {
+		files, err := ExpandPaths([]string{dir})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{
+			filepath.Join(dir, "flat_1.parquet"),
+			filepath.Join(dir, "flat_2.parquet"),
+			filepath.Join(dir, "flat_3.parquet"),
+			filepath.Join(dir, "sub", "flat_9.parquet"),
+		}
+		if len(files) != len(want) {
+			t.Fatalf("expected %v, got %v", want, files)
+		}
+		for i := range want {
+			if files[i] != want[i] {
+				t.Errorf("file %d: got %s, want %s", i, files[i], want[i])
+			}
+		}
+	})
+
+	t.Run("glob", func(t *testing.T) {
+		files, err := ExpandPaths([]string{filepath.Join(dir, "flat_[12].parquet")})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 2 {
+			t.Errorf("expected 2 files, got %v", files)
+		}
+	})
+
+	t.Run("plain files and stdin pass through", func(t *testing.T) {
+		files, err := ExpandPaths([]string{"a.parquet", "-"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 2 || files[0] != "a.parquet" || files[1] != "-" {
+			t.Errorf("unexpected files %v", files)
+		}
+	})
+
+	t.Run("glob without matches", func(t *testing.T) {
+		if _, err := ExpandPaths([]string{filepath.Join(dir, "nope_*.parquet")}); err == nil {
+			t.Error("expected error for glob without matches")
+		}
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		if _, err := ExpandPaths([]string{t.TempDir()}); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected fs.ErrNotExist for directory without Parquet files, got %v", err)
+		}
+	})
+}
+
+// --- Dataset ---
+
+func TestDataset(t *testing.T) {
+	dir := splitFixture(t, "flat.parquet", 3)
+
+	t.Run("count", func(t *testing.T) {
+		ds, err := OpenDataset([]string{dir})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count, err := ds.Count()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 100 {
+			t.Errorf("expected 100 rows, got %d", count)
+		}
+	})
+
+	t.Run("head spans files", func(t *testing.T) {
+		ds, err := OpenDataset([]string{filepath.Join(dir, "*.parquet")})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, err := ds.Head(40)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 40 {
+			t.Fatalf("expected 40 rows, got %d", len(rows))
+		}
+		if rows[0]["id"] != "id_0" || rows[39]["id"] != "id_39" {
+			t.Errorf("unexpected first/last rows: %v, %v", rows[0]["id"], rows[39]["id"])
+		}
+	})
+
+	t.Run("tail spans files", func(t *testing.T) {
+		ds, err := OpenDataset([]string{dir})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, err := ds.Tail(40)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 40 {
+			t.Fatalf("expected 40 rows, got %d", len(rows))
+		}
+		if rows[0]["id"] != "id_60" || rows[39]["id"] != "id_99" {
+			t.Errorf("unexpected first/last rows: %v, %v", rows[0]["id"], rows[39]["id"])
+		}
+	})
+
+	t.Run("stream all files in order", func(t *testing.T) {
+		ds, err := OpenDataset([]string{dir})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []interface{}
+		err = ds.StreamAll(func(row map[string]interface{}) error {
+			ids = append(ids, row["id"])
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ids) != 100 || ids[99] != "id_99" {
+			t.Errorf("expected 100 rows ending with id_99, got %d", len(ids))
+		}
+	})
+
+	t.Run("sample is unique across files", func(t *testing.T) {
+		ds, err := OpenDataset([]string{dir})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, err := ds.Sample(30)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 30 {
+			t.Fatalf("expected 30 rows, got %d", len(rows))
+		}
+		seen := make(map[interface{}]bool)
+		for _, row := range rows {
+			if seen[row["id"]] {
+				t.Errorf("duplicate id: %v", row["id"])
+			}
+			seen[row["id"]] = true
+		}
+	})
+
+	t.Run("filter across files", func(t *testing.T) {
+		ds, err := OpenDataset([]string{dir}, WithFilter("age = 20"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count, err := ds.Count()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("expected 2 matching rows, got %d", count)
+		}
+	})
+
+	t.Run("incompatible schemas", func(t *testing.T) {
+		ds, err := OpenDataset([]string{fixture("flat.parquet"), fixture("nested_struct.parquet")})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := ds.Head(5); err == nil {
+			t.Error("expected schema compatibility error")
+		}
+	})
+
+	t.Run("projection makes schemas compatible", func(t *testing.T) {
+		ds, err := OpenDataset([]string{fixture("flat.parquet"), fixture("nested_struct.parquet")}, WithColumns("id"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, err := ds.Tail(3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rows[2]["id"] != "id_49" {
+			t.Errorf("last row should be id_49, got %v", rows[2]["id"])
+		}
+	})
+
+	t.Run("stdin cannot be combined", func(t *testing.T) {
+		if _, err := OpenDataset([]string{"-", fixture("flat.parquet")}); err == nil {
+			t.Error("expected error when combining stdin with files")
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the readers. They are usually wrapped with details, so
+// test for them with errors.Is.
+var (
+	// ErrNotParquet means the input does not start with the PAR1 magic bytes.
+	ErrNotParquet = errors.New("not a Parquet file")
+	// ErrEmptyFile means the file is valid but has no rows.
+	ErrEmptyFile = errors.New("file is empty")
+	// ErrTruncated means the file ends before its footer is complete, as
+	// happens with interrupted copies or files still being written.
+	ErrTruncated = errors.New("file is truncated")
+	// ErrCorrupt means the file metadata or data could not be decoded.
+	ErrCorrupt = errors.New("file is corrupt")
+	// ErrInvalidFilter means a filter expression could not be parsed or
+	// refers to unknown columns.
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrNoSchema means the file metadata holds no schema.
+	ErrNoSchema = errors.New("schema is nil")
+	// ErrInvalidReader is returned by the methods of a nil or closed reader.
+	ErrInvalidReader = errors.New("invalid reader: reader is not initialized properly")
+)
+
+// CorruptRowError reports a row that could not be reconstructed from its
+// column values. It matches ErrCorrupt with errors.Is.
+type CorruptRowError struct {
+	// Row is the index of the row in the file, counting from 0.
+	Row int64
+	Err error
+}
+
+func (e *CorruptRowError) Error() string {
+	return fmt.Sprintf("corrupt row %d: %v", e.Row, e.Err)
+}
+
+func (e *CorruptRowError) Unwrap() error {
+	return e.Err
+}
+
+func (e *CorruptRowError) Is(target error) bool {
+	return target == ErrCorrupt
+}
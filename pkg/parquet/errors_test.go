@@ -0,0 +1,148 @@
+package parquet
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	cases := []struct {
+		name string
+		path string
+		want error
+	}{
+		{"missing file", filepath.Join(dir, "missing.parquet"), fs.ErrNotExist},
+		{"not parquet", write("text.parquet", []byte("hello, world\n")), ErrNotParquet},
+		{"zero bytes", write("zero.parquet", nil), ErrTruncated},
+		{"too small", write("tiny.parquet", []byte("PAR1abcd")), ErrTruncated},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewParquetReader(tc.path)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	t.Run("empty file", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("empty.parquet"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		if _, err := r.Head(1); !errors.Is(err, ErrEmptyFile) {
+			t.Errorf("Head: expected ErrEmptyFile, got %v", err)
+		}
+		if _, err := r.Tail(1); !errors.Is(err, ErrEmptyFile) {
+			t.Errorf("Tail: expected ErrEmptyFile, got %v", err)
+		}
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		_, err := NewParquetReader(fixture("flat.parquet"), WithFilter("age >"))
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
+	t.Run("nil reader", func(t *testing.T) {
+		var r *ParquetReader
+		if _, err := r.Count(); !errors.Is(err, ErrInvalidReader) {
+			t.Errorf("expected ErrInvalidReader, got %v", err)
+		}
+	})
+}
+
+func TestCorruptRowError(t *testing.T) {
+	cause := errors.New("bad definition level")
+	var err error = &CorruptRowError{Row: 42, Err: cause}
+
+	if !errors.Is(err, ErrCorrupt) {
+		t.Error("CorruptRowError should match ErrCorrupt")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("CorruptRowError should unwrap to its cause")
+	}
+	var rowErr *CorruptRowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 42 {
+		t.Errorf("errors.As failed: %v", err)
+	}
+	if err.Error() != "corrupt row 42: bad definition level" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestParseErrorPolicy(t *testing.T) {
+	for input, want := range map[string]ErrorPolicy{"fail": FailOnError, "skip": SkipOnError, " NULL ": NullOnError} {
+		got, err := ParseErrorPolicy(input)
+		if err != nil || got != want {
+			t.Errorf("ParseErrorPolicy(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseErrorPolicy("ignore"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
+func TestBadRowPolicy(t *testing.T) {
+	schema := parquet.NewSchema("test", parquet.Group{
+		"id":   parquet.Int(64),
+		"name": parquet.Optional(parquet.String()),
+	})
+	cause := errors.New("bad definition level")
+
+	t.Run("fail", func(t *testing.T) {
+		r := &ParquetReader{}
+		row, err := r.badRow(schema, 7, cause)
+		var rowErr *CorruptRowError
+		if row != nil || !errors.As(err, &rowErr) || rowErr.Row != 7 {
+			t.Errorf("expected a CorruptRowError for row 7, got %v, %v", row, err)
+		}
+		if len(r.BadRows()) != 0 {
+			t.Errorf("fail should not record bad rows, got %v", r.BadRows())
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		r := &ParquetReader{onError: SkipOnError}
+		for _, index := range []int64{3, 9} {
+			if row, err := r.badRow(schema, index, cause); row != nil || err != nil {
+				t.Errorf("expected the row to be dropped, got %v, %v", row, err)
+			}
+		}
+		if !reflect.DeepEqual(r.BadRows(), []int64{3, 9}) {
+			t.Errorf("expected bad rows [3 9], got %v", r.BadRows())
+		}
+	})
+
+	t.Run("null", func(t *testing.T) {
+		r := &ParquetReader{onError: NullOnError}
+		row, err := r.badRow(schema, 4, cause)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]interface{}{"id": nil, "name": nil}
+		if !reflect.DeepEqual(row, want) {
+			t.Errorf("expected %v, got %v", want, row)
+		}
+		if !reflect.DeepEqual(r.BadRows(), []int64{4}) {
+			t.Errorf("expected bad rows [4], got %v", r.BadRows())
+		}
+	})
+}
@@ -0,0 +1,902 @@
+package parquet
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Filter is a parsed row predicate such as
+//
+//	age > 30 AND info.city = 'Paris' AND name LIKE 'user_%'
+//
+// Supported operators are =, !=, <>, <, <=, >, >=, [NOT] LIKE, [NOT] IN (...),
+// IS [NOT] NULL, combined with AND, OR, NOT and parentheses. Column names use
+// dotted paths for nested struct fields. Comparisons against null never match.
+// Decimals, read as strings, compare with numbers by value.
+type Filter struct {
+	source string
+	root   expr
+}
+
+// ParseFilter parses a predicate expression.
+func ParseFilter(s string) (*Filter, error) {
+	tokens, err := lexFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+	return &Filter{source: s, root: root}, nil
+}
+
+// String returns the expression the filter was parsed from.
+func (f *Filter) String() string {
+	return f.source
+}
+
+// Match reports whether a reconstructed row satisfies the filter.
+func (f *Filter) Match(row map[string]interface{}) bool {
+	return f.root.eval(row)
+}
+
+// Columns returns the column paths referenced by the filter.
+func (f *Filter) Columns() [][]string {
+	var paths [][]string
+	seen := make(map[string]bool)
+	f.root.walk(func(path []string) {
+		key := strings.Join(path, ".")
+		if !seen[key] {
+			seen[key] = true
+			paths = append(paths, path)
+		}
+	})
+	return paths
+}
+
+// bind evaluates the predicates that only reference columns in values and
+// simplifies the rest of the expression around them. Predicates with a
+// quoted literal are evaluated against text instead, the same values as
+// written, when given. The returned filter's root is a *constExpr when the
+// outcome no longer depends on the row.
+func (f *Filter) bind(values, text map[string]interface{}) *Filter {
+	return &Filter{source: f.source, root: bindExpr(f.root, values, text)}
+}
+
+// constant reports whether the filter's outcome is known without a row.
+func (f *Filter) constant() (value, ok bool) {
+	c, ok := f.root.(*constExpr)
+	if !ok {
+		return false, false
+	}
+	return c.value, true
+}
+
+func bindExpr(e expr, values, text map[string]interface{}) expr {
+	switch e := e.(type) {
+	case *andExpr:
+		left, right := bindExpr(e.left, values, text), bindExpr(e.right, values, text)
+		if c, ok := left.(*constExpr); ok {
+			if !c.value {
+				return c
+			}
+			return right
+		}
+		if c, ok := right.(*constExpr); ok {
+			if !c.value {
+				return c
+			}
+			return left
+		}
+		return &andExpr{left, right}
+	case *orExpr:
+		left, right := bindExpr(e.left, values, text), bindExpr(e.right, values, text)
+		if c, ok := left.(*constExpr); ok {
+			if c.value {
+				return c
+			}
+			return right
+		}
+		if c, ok := right.(*constExpr); ok {
+			if c.value {
+				return c
+			}
+			return left
+		}
+		return &orExpr{left, right}
+	case *notExpr:
+		inner := bindExpr(e.inner, values, text)
+		if c, ok := inner.(*constExpr); ok {
+			return &constExpr{!c.value}
+		}
+		return &notExpr{inner}
+	case *constExpr:
+		return e
+	}
+	bound := true
+	e.walk(func(path []string) {
+		if _, ok := values[path[0]]; !ok || len(path) != 1 {
+			bound = false
+		}
+	})
+	if !bound {
+		return e
+	}
+	if text != nil && quoted(e) {
+		return &constExpr{e.eval(text)}
+	}
+	return &constExpr{e.eval(values)}
+}
+
+// quoted reports whether a predicate compares a column with a quoted string.
+func quoted(e expr) bool {
+	switch e := e.(type) {
+	case *cmpExpr:
+		_, ok := e.value.(string)
+		return ok
+	case *inExpr:
+		for _, v := range e.values {
+			if _, ok := v.(string); ok {
+				return true
+			}
+		}
+	case *likeExpr:
+		return true
+	}
+	return false
+}
+
+// expr is a node of the filter syntax tree. mayMatch is evaluated against
+// row-group statistics and must only return false when no row can match.
+type expr interface {
+	eval(row map[string]interface{}) bool
+	mayMatch(stats statsFunc) bool
+	walk(fn func(path []string))
+}
+
+type andExpr struct{ left, right expr }
+
+func (e *andExpr) eval(row map[string]interface{}) bool { return e.left.eval(row) && e.right.eval(row) }
+func (e *andExpr) mayMatch(s statsFunc) bool            { return e.left.mayMatch(s) && e.right.mayMatch(s) }
+func (e *andExpr) walk(fn func([]string))               { e.left.walk(fn); e.right.walk(fn) }
+
+type orExpr struct{ left, right expr }
+
+func (e *orExpr) eval(row map[string]interface{}) bool { return e.left.eval(row) || e.right.eval(row) }
+func (e *orExpr) mayMatch(s statsFunc) bool            { return e.left.mayMatch(s) || e.right.mayMatch(s) }
+func (e *orExpr) walk(fn func([]string))               { e.left.walk(fn); e.right.walk(fn) }
+
+type notExpr struct{ inner expr }
+
+func (e *notExpr) eval(row map[string]interface{}) bool { return !e.inner.eval(row) }
+func (e *notExpr) mayMatch(statsFunc) bool              { return true }
+func (e *notExpr) walk(fn func([]string))               { e.inner.walk(fn) }
+
+// constExpr is a subexpression whose outcome is already known, such as a
+// predicate on partition columns bound to the values of one file.
+type constExpr struct{ value bool }
+
+func (e *constExpr) eval(map[string]interface{}) bool { return e.value }
+func (e *constExpr) mayMatch(statsFunc) bool          { return e.value }
+func (e *constExpr) walk(func([]string))              {}
+
+type cmpExpr struct {
+	path  []string
+	op    string
+	value interface{}
+}
+
+func (e *cmpExpr) eval(row map[string]interface{}) bool {
+	v := lookupPath(row, e.path)
+	if v == nil || e.value == nil {
+		return false
+	}
+	c, ok := compareValues(v, e.value)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func (e *cmpExpr) mayMatch(stats statsFunc) bool {
+	st := stats(e.path)
+	if st == nil || !st.hasMinMax || e.value == nil {
+		return true
+	}
+	cmin, ok1 := compareValues(st.min, e.value)
+	cmax, ok2 := compareValues(st.max, e.value)
+	if !ok1 || !ok2 {
+		return true
+	}
+	switch e.op {
+	case "=":
+		return cmin <= 0 && cmax >= 0
+	case "!=":
+		return !(cmin == 0 && cmax == 0)
+	case "<":
+		return cmin < 0
+	case "<=":
+		return cmin <= 0
+	case ">":
+		return cmax > 0
+	case ">=":
+		return cmax >= 0
+	}
+	return true
+}
+
+func (e *cmpExpr) walk(fn func([]string)) { fn(e.path) }
+
+type nullExpr struct {
+	path []string
+	not  bool
+}
+
+func (e *nullExpr) eval(row map[string]interface{}) bool {
+	return (lookupPath(row, e.path) == nil) != e.not
+}
+
+func (e *nullExpr) mayMatch(stats statsFunc) bool {
+	st := stats(e.path)
+	if st == nil {
+		return true
+	}
+	if e.not {
+		return st.numValues == 0 || st.nullCount < st.numValues
+	}
+	// without a null count, or min/max when every value is null, nulls
+	// cannot be ruled out
+	return !st.hasNullCount || !st.hasMinMax || st.nullCount > 0
+}
+
+func (e *nullExpr) walk(fn func([]string)) { fn(e.path) }
+
+type likeExpr struct {
+	path    []string
+	pattern string
+	re      *regexp.Regexp
+	not     bool
+}
+
+func (e *likeExpr) eval(row map[string]interface{}) bool {
+	s, ok := stringValue(lookupPath(row, e.path))
+	if !ok {
+		return false
+	}
+	return e.re.MatchString(s) != e.not
+}
+
+func (e *likeExpr) mayMatch(stats statsFunc) bool {
+	if e.not {
+		return true
+	}
+	prefix := likePrefix(e.pattern)
+	if prefix == "" {
+		return true
+	}
+	st := stats(e.path)
+	if st == nil || !st.hasMinMax {
+		return true
+	}
+	min, ok1 := stringValue(st.min)
+	max, ok2 := stringValue(st.max)
+	if !ok1 || !ok2 {
+		return true
+	}
+	if max < prefix {
+		return false
+	}
+	return min <= prefix || strings.HasPrefix(min, prefix)
+}
+
+func (e *likeExpr) walk(fn func([]string)) { fn(e.path) }
+
+type inExpr struct {
+	path   []string
+	values []interface{}
+	not    bool
+}
+
+func (e *inExpr) eval(row map[string]interface{}) bool {
+	v := lookupPath(row, e.path)
+	if v == nil {
+		return false
+	}
+	for _, want := range e.values {
+		if c, ok := compareValues(v, want); ok && c == 0 {
+			return !e.not
+		}
+	}
+	return e.not
+}
+
+func (e *inExpr) mayMatch(stats statsFunc) bool {
+	if e.not {
+		return true
+	}
+	for _, want := range e.values {
+		if (&cmpExpr{path: e.path, op: "=", value: want}).mayMatch(stats) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *inExpr) walk(fn func([]string)) { fn(e.path) }
+
+// walkComparisons calls fn with the column and value of every comparison of
+// e with a value, including each value of IN lists.
+func walkComparisons(e expr, fn func(path []string, value interface{}) error) error {
+	switch e := e.(type) {
+	case *andExpr:
+		if err := walkComparisons(e.left, fn); err != nil {
+			return err
+		}
+		return walkComparisons(e.right, fn)
+	case *orExpr:
+		if err := walkComparisons(e.left, fn); err != nil {
+			return err
+		}
+		return walkComparisons(e.right, fn)
+	case *notExpr:
+		return walkComparisons(e.inner, fn)
+	case *cmpExpr:
+		return fn(e.path, e.value)
+	case *inExpr:
+		for _, v := range e.values {
+			if err := fn(e.path, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkComparisons rejects comparisons of numbers with columns that are not
+// read as numbers, such as strings, timestamps and dates, which no row would
+// match. Decimals are read as strings, but compare with numbers by value.
+func checkComparisons(schema *parquet.Schema, f *Filter, rawTypes bool) error {
+	return walkComparisons(f.root, func(path []string, value interface{}) error {
+		if _, ok := floatValue(value); !ok {
+			return nil
+		}
+		var node parquet.Node = schema
+		for _, name := range path {
+			if node = fieldByName(node, name); node == nil || !node.Leaf() && (isListNode(node) || isMapNode(node)) {
+				return nil
+			}
+		}
+		if node.Leaf() && !comparesWithNumbers(node.Type(), rawTypes) {
+			return fmt.Errorf("%w: column %s does not hold numbers and cannot be compared with %v, quote the value",
+				ErrInvalidFilter, strings.Join(path, "."), value)
+		}
+		return nil
+	})
+}
+
+// comparesWithNumbers reports whether the values of a leaf of type t are
+// read as numbers, or as decimal strings.
+func comparesWithNumbers(t parquet.Type, rawTypes bool) bool {
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	switch t.Kind() {
+	case parquet.Boolean, parquet.Int96:
+		return false
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		return !rawTypes && lt != nil && lt.Decimal != nil
+	}
+	return rawTypes || lt == nil || (lt.Timestamp == nil && lt.Date == nil && lt.Time == nil)
+}
+
+// lookupPath resolves a dotted path through nested struct and map values.
+func lookupPath(row map[string]interface{}, path []string) interface{} {
+	var cur interface{} = row
+	for _, name := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[name]
+	}
+	return cur
+}
+
+// compareValues orders two scalar values. The second result is false when
+// the values are not comparable (e.g. a string and a number).
+func compareValues(a, b interface{}) (int, bool) {
+	if ai, ok := intValue(a); ok {
+		if bi, ok := intValue(b); ok {
+			return compareOrdered(ai, bi), true
+		}
+	}
+	if af, ok := floatValue(a); ok {
+		if bf, ok := floatValue(b); ok {
+			if math.IsNaN(af) || math.IsNaN(bf) {
+				return 0, false
+			}
+			return compareOrdered(af, bf), true
+		}
+	}
+	if as, ok := stringValue(a); ok {
+		if bs, ok := stringValue(b); ok {
+			return strings.Compare(as, bs), true
+		}
+	}
+	// decimals are rendered as text, which compares with a number by the
+	// value it spells
+	if ar, ok := ratValue(a); ok {
+		if br, ok := ratValue(b); ok {
+			return ar.Cmp(br), true
+		}
+	}
+	if ab, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			switch {
+			case ab == bb:
+				return 0, true
+			case bb:
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func compareOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func intValue(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		if n <= math.MaxInt64 {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+func floatValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case uint64:
+		return float64(n), true
+	}
+	if i, ok := intValue(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+// ratValue returns the exact value of a number, or of a string spelling a
+// decimal number.
+func ratValue(v interface{}) (*big.Rat, bool) {
+	if s, ok := v.(string); ok {
+		if strings.Contains(s, "/") {
+			return nil, false
+		}
+		return new(big.Rat).SetString(strings.TrimSpace(s))
+	}
+	if i, ok := intValue(v); ok {
+		return new(big.Rat).SetInt64(i), true
+	}
+	if f, ok := floatValue(v); ok && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		// the shortest decimal that reads back as f, as written in a filter
+		return new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return nil, false
+}
+
+func stringValue(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case []byte:
+		return string(s), true
+	}
+	return "", false
+}
+
+// likeRegexp translates a SQL LIKE pattern (% and _ wildcards, backslash
+// escapes) into an anchored regular expression.
+func likeRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// likePrefix returns the literal prefix of a LIKE pattern before its first wildcard.
+func likePrefix(pattern string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%' || r == '_':
+			return b.String()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// --- lexer ---
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (t token) keyword(kw string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, kw)
+}
+
+func lexFilter(s string) ([]token, error) {
+	var tokens []token
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokComma, ",", i})
+			i++
+		case r == '\'':
+			start := i
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string starting at position %d", start)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						b.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{tokString, b.String(), start})
+		case r == '`' || r == '"':
+			start := i
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated identifier starting at position %d", start)
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[i+1 : end]), start})
+			i = end + 1
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) && strings.ContainsRune("=>", runes[i+1]) {
+				op += string(runes[i+1])
+			}
+			switch op {
+			case "=", "==", "!=", "<>", "<", "<=", ">", ">=":
+			default:
+				return nil, fmt.Errorf("unknown operator %q at position %d", op, start)
+			}
+			tokens = append(tokens, token{tokOp, op, start})
+			i += len([]rune(op))
+		case unicode.IsDigit(r) || ((r == '-' || r == '.') && i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.')):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE", runes[i]) ||
+				((runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start:i]), start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+// --- parser ---
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (expr, error) {
+	if p.peek().keyword("NOT") {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{inner}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected \")\" at position %d, got %s", tok.pos, tok)
+		}
+		return e, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *filterParser) parsePredicate() (expr, error) {
+	tok := p.next()
+	if tok.kind != tokIdent || isReservedWord(tok.text) {
+		return nil, fmt.Errorf("expected column name at position %d, got %s", tok.pos, tok)
+	}
+	path := strings.Split(tok.text, ".")
+
+	op := p.next()
+	switch {
+	case op.kind == tokOp:
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		name := op.text
+		switch name {
+		case "==":
+			name = "="
+		case "<>":
+			name = "!="
+		}
+		return &cmpExpr{path: path, op: name, value: value}, nil
+	case op.keyword("IS"):
+		not := false
+		if p.peek().keyword("NOT") {
+			p.next()
+			not = true
+		}
+		if tok := p.next(); !tok.keyword("NULL") {
+			return nil, fmt.Errorf("expected NULL at position %d, got %s", tok.pos, tok)
+		}
+		return &nullExpr{path: path, not: not}, nil
+	case op.keyword("NOT"):
+		next := p.next()
+		switch {
+		case next.keyword("LIKE"):
+			return p.parseLike(path, true)
+		case next.keyword("IN"):
+			return p.parseIn(path, true)
+		}
+		return nil, fmt.Errorf("expected LIKE or IN at position %d, got %s", next.pos, next)
+	case op.keyword("LIKE"):
+		return p.parseLike(path, false)
+	case op.keyword("IN"):
+		return p.parseIn(path, false)
+	}
+	return nil, fmt.Errorf("expected operator at position %d, got %s", op.pos, op)
+}
+
+func (p *filterParser) parseLike(path []string, not bool) (expr, error) {
+	tok := p.next()
+	if tok.kind != tokString {
+		return nil, fmt.Errorf("expected string pattern at position %d, got %s", tok.pos, tok)
+	}
+	re, err := likeRegexp(tok.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LIKE pattern %q: %v", tok.text, err)
+	}
+	return &likeExpr{path: path, pattern: tok.text, re: re, not: not}, nil
+}
+
+func (p *filterParser) parseIn(path []string, not bool) (expr, error) {
+	if tok := p.next(); tok.kind != tokLParen {
+		return nil, fmt.Errorf("expected \"(\" at position %d, got %s", tok.pos, tok)
+	}
+	var values []interface{}
+	for {
+		v, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		tok := p.next()
+		if tok.kind == tokRParen {
+			break
+		}
+		if tok.kind != tokComma {
+			return nil, fmt.Errorf("expected \",\" or \")\" at position %d, got %s", tok.pos, tok)
+		}
+	}
+	return &inExpr{path: path, values: values, not: not}, nil
+}
+
+func (p *filterParser) parseLiteral() (interface{}, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokString:
+		return tok.text, nil
+	case tok.kind == tokNumber:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return f, nil
+	case tok.keyword("TRUE"):
+		return true, nil
+	case tok.keyword("FALSE"):
+		return false, nil
+	case tok.keyword("NULL"):
+		return nil, nil
+	}
+	return nil, fmt.Errorf("expected literal value at position %d, got %s", tok.pos, tok)
+}
+
+func isReservedWord(s string) bool {
+	switch strings.ToUpper(s) {
+	case "AND", "OR", "NOT", "IS", "NULL", "LIKE", "IN", "TRUE", "FALSE":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,400 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// --- ParseFilter / Match ---
+
+func TestFilterMatch(t *testing.T) {
+	row := map[string]interface{}{
+		"id":     "id_7",
+		"name":   "user_7",
+		"age":    int32(42),
+		"score":  0.7,
+		"active": false,
+		"info": map[string]interface{}{
+			"city": "Paris",
+			"zip":  nil,
+		},
+	}
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"age > 30", true},
+		{"age >= 42 AND age <= 42", true},
+		{"age < 30", false},
+		{"age = 42.0", true},
+		{"score > 0.5", true},
+		{"name = 'user_7'", true},
+		{"name != 'user_7'", false},
+		{"name <> 'user_8'", true},
+		{"name LIKE 'user_%'", true},
+		{"name LIKE 'user__'", true},
+		{"name NOT LIKE 'user_%'", false},
+		{"info.city = 'Paris'", true},
+		{"info.zip IS NULL", true},
+		{"info.city IS NOT NULL", true},
+		{"missing IS NULL", true},
+		{"missing = 1", false},
+		{"age IN (1, 2, 42)", true},
+		{"age NOT IN (1, 2)", true},
+		{"active = false", true},
+		{"age > 30 AND info.city = 'Paris' AND name LIKE 'user_%'", true},
+		{"age < 30 OR info.city = 'Paris'", true},
+		{"NOT (age < 30 OR info.city = 'Paris')", false},
+		{"name = 'it''s'", false},
+		{"age > 'abc'", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := ParseFilter(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			if got := f.Match(row); got != tc.want {
+				t.Errorf("Match() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFilterLogicalTypes(t *testing.T) {
+	// decimals and timestamps are rendered as strings before rows are matched
+	row := map[string]interface{}{
+		"price": "123.45",
+		"at":    "2024-01-02T03:04:05Z",
+	}
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"price > 100", true},
+		{"price < 100", false},
+		{"price = 123.45", true},
+		{"price >= 123.450", true},
+		{"price IN (1, 123.45)", true},
+		{"price > 99.5", true},
+		{"at > '2024-01-01'", true},
+		{"at < '2024-01-02'", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := ParseFilter(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			if got := f.Match(row); got != tc.want {
+				t.Errorf("Match = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	root := orderedGroup{}
+	root.add("price", parquet.Decimal(2, 9, parquet.Int32Type))
+	root.add("amount", parquet.Decimal(2, 20, parquet.FixedLenByteArrayType(9)))
+	root.add("at", parquet.Timestamp(parquet.Millisecond))
+	root.add("name", parquet.String())
+	root.add("n", parquet.Int(64))
+	schema := parquet.NewSchema("t", root)
+	checks := []struct {
+		expr     string
+		rawTypes bool
+		valid    bool
+	}{
+		{"price > 100", false, true},
+		{"amount > 100 AND n IN (1, 2)", false, true},
+		{"at > '2024-01-01'", false, true},
+		{"at > 5", false, false},
+		{"NOT name = 1", false, false},
+		{"n = 1 OR name IN ('a', 2)", false, false},
+		{"at > 5", true, true},
+		{"amount > 100", true, false},
+	}
+	for _, tc := range checks {
+		f, err := ParseFilter(tc.expr)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		err = checkComparisons(schema, f, tc.rawTypes)
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.expr, err)
+		}
+		if !tc.valid && !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", tc.expr, err)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"age >",
+		"age > 1 AND",
+		"(age > 1",
+		"age ~ 1",
+		"name LIKE 5",
+		"name = 'unterminated",
+		"age IS 5",
+		"AND = 1",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseFilter(expr); err == nil {
+				t.Errorf("expected parse error for %q", expr)
+			}
+		})
+	}
+}
+
+func TestFilterColumns(t *testing.T) {
+	f, err := ParseFilter("age > 1 AND (info.city = 'x' OR age < 0)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cols := f.Columns()
+	if len(cols) != 2 {
+		t.Fatalf("expected 2 columns, got %v", cols)
+	}
+	if len(cols[1]) != 2 || cols[1][0] != "info" || cols[1][1] != "city" {
+		t.Errorf("unexpected nested column path %v", cols[1])
+	}
+}
+
+func TestFilterMayMatch(t *testing.T) {
+	stats := func(path []string) *columnStats {
+		if len(path) == 1 && path[0] == "value" {
+			return &columnStats{min: int64(30), max: int64(59), hasMinMax: true, hasNullCount: true, numValues: 30}
+		}
+		if len(path) == 1 && path[0] == "id" {
+			return &columnStats{min: "id_30", max: "id_59", hasMinMax: true, hasNullCount: true, numValues: 30}
+		}
+		if len(path) == 1 && path[0] == "legacy" {
+			// written without a null count
+			return &columnStats{min: int64(1), max: int64(9), hasMinMax: true, numValues: 30}
+		}
+		return nil
+	}
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"value = 40", true},
+		{"value = 60", false},
+		{"value > 59", false},
+		{"value >= 59", true},
+		{"value < 30", false},
+		{"value <= 30", true},
+		{"value IN (1, 2, 3)", false},
+		{"value IN (1, 45)", true},
+		{"value IS NULL", false},
+		{"value IS NOT NULL", true},
+		{"legacy IS NULL", true},
+		{"legacy IS NOT NULL", true},
+		{"id LIKE 'id_4%'", true},
+		{"id LIKE 'id_9%'", true}, // _ is a wildcard: only "id" is a fixed prefix
+		{"id LIKE 'id9%'", false},
+		{"id LIKE 'a%'", false},
+		{"value = 60 OR id = 'id_40'", true},
+		{"value = 60 AND id = 'id_40'", false},
+		{"NOT value = 40", true},
+		{"other = 1", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := ParseFilter(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			if got := f.root.mayMatch(stats); got != tc.want {
+				t.Errorf("mayMatch() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// --- WithFilter ---
+
+func TestWithFilter(t *testing.T) {
+	t.Run("head", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("flat.parquet"), WithFilter("age > 60 AND name LIKE 'user_4%'"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Head(3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("expected 3 rows, got %d", len(rows))
+		}
+		if rows[0]["id"] != "id_41" {
+			t.Errorf("first match should be id_41, got %v", rows[0]["id"])
+		}
+	})
+
+	t.Run("tail", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("multi_rowgroup.parquet"), WithFilter("value < 50"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Tail(2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 2 || rows[0]["id"] != "id_48" || rows[1]["id"] != "id_49" {
+			t.Errorf("expected id_48, id_49, got %v", rows)
+		}
+	})
+
+	t.Run("count", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("flat.parquet"), WithFilter("active = true"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		count, err := r.Count()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 50 {
+			t.Errorf("expected 50 matching rows, got %d", count)
+		}
+	})
+
+	t.Run("sample", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("flat.parquet"), WithFilter("age = 20"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Sample(5)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Errorf("expected the 2 matching rows, got %d", len(rows))
+		}
+	})
+
+	t.Run("nested and nullable", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("nullable.parquet"), WithFilter("info.city IS NULL"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		count, err := r.Count()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 3 {
+			t.Errorf("expected 3 rows with null info.city, got %d", count)
+		}
+	})
+
+	t.Run("filter column outside projection", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("nested_struct.parquet"), WithColumns("id"), WithFilter("info.address.city = 'city_1'"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Head(100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 10 {
+			t.Fatalf("expected 10 rows, got %d", len(rows))
+		}
+		if _, ok := rows[0]["info"]; ok {
+			t.Error("filter-only column 'info' should be dropped from output")
+		}
+		if rows[0]["id"] != "id_1" {
+			t.Errorf("first match should be id_1, got %v", rows[0]["id"])
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("flat.parquet"), WithFilter("age > 1000"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Head(10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 0 {
+			t.Errorf("expected no rows, got %d", len(rows))
+		}
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		if _, err := NewParquetReader(fixture("flat.parquet"), WithFilter("age >")); err == nil {
+			t.Fatal("expected error for invalid expression")
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		if _, err := NewParquetReader(fixture("flat.parquet"), WithFilter("nope = 1")); err == nil {
+			t.Fatal("expected error for unknown column")
+		}
+	})
+}
+
+func TestRowGroupPruning(t *testing.T) {
+	cases := []struct {
+		expr string
+		keep int
+	}{
+		{"value >= 0", 3},
+		{"value >= 60", 1},
+		{"value = 45", 1},
+		{"value < 30 OR value > 80", 2},
+		{"value > 100", 0},
+		{"id = 'id_5'", 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			r, err := NewParquetReader(fixture("multi_rowgroup.parquet"), WithFilter(tc.expr))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer r.Close()
+			if len(r.keepGroups) != tc.keep {
+				t.Errorf("expected %d row groups after pruning, got %d", tc.keep, len(r.keepGroups))
+			}
+		})
+	}
+}
+
+func TestFooterNullCounts(t *testing.T) {
+	var buf bytes.Buffer
+	w := parquet.NewWriter(&buf, parquet.NewSchema("t", parquet.Group{"v": parquet.Optional(parquet.Int(64))}))
+	if err := w.Write(map[string]interface{}{"v": nil}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	data := buf.Bytes()
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	counts := footerNullCounts(data[len(data)-8-footerSize : len(data)-8])
+	if len(counts) != 1 || len(counts[0]) != 1 || !counts[0][0] {
+		t.Errorf("expected one column chunk with a null count, got %v", counts)
+	}
+	if counts := footerNullCounts([]byte{0xff}); counts != nil {
+		t.Errorf("expected no counts of a broken footer, got %v", counts)
+	}
+}
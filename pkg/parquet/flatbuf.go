@@ -0,0 +1,183 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// A minimal FlatBuffers encoder for the Arrow IPC metadata. Objects are laid
+// out front to back: a table is written before the tables, vectors and
+// strings it refers to, so every offset points forward as the format
+// requires.
+
+// fbObject is a table, vector or string. writeTo appends it to b and returns
+// the position offsets to it must point at.
+type fbObject interface {
+	writeTo(b *fbBuilder) int
+}
+
+type fbBuilder struct {
+	buf []byte
+}
+
+func (b *fbBuilder) align(n int) {
+	for len(b.buf)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbBuilder) putUint32(v uint32) {
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, v)
+}
+
+// fbFinish encodes root as a complete buffer, padded to 8 bytes.
+func fbFinish(root fbObject) []byte {
+	b := &fbBuilder{buf: make([]byte, 4, 512)}
+	pos := root.writeTo(b)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	b.align(8)
+	return b.buf
+}
+
+// fbTable is a table given as its fields, in any order.
+type fbTable []fbField
+
+// fbField is a scalar field or a reference to another object, stored in the
+// vtable slot of the field in the schema.
+type fbField struct {
+	slot   int
+	scalar []byte
+	ref    fbObject
+}
+
+func (f fbField) size() int {
+	if f.ref != nil {
+		return 4
+	}
+	return len(f.scalar)
+}
+
+func fbBool(slot int, v bool) fbField {
+	if v {
+		return fbField{slot: slot, scalar: []byte{1}}
+	}
+	return fbField{slot: slot, scalar: []byte{0}}
+}
+
+func fbUint8(slot int, v uint8) fbField {
+	return fbField{slot: slot, scalar: []byte{v}}
+}
+
+func fbInt16(slot int, v int16) fbField {
+	return fbField{slot: slot, scalar: binary.LittleEndian.AppendUint16(nil, uint16(v))}
+}
+
+func fbInt32(slot int, v int32) fbField {
+	return fbField{slot: slot, scalar: binary.LittleEndian.AppendUint32(nil, uint32(v))}
+}
+
+func fbInt64(slot int, v int64) fbField {
+	return fbField{slot: slot, scalar: binary.LittleEndian.AppendUint64(nil, uint64(v))}
+}
+
+func fbRef(slot int, obj fbObject) fbField {
+	return fbField{slot: slot, ref: obj}
+}
+
+func (t fbTable) writeTo(b *fbBuilder) int {
+	// Lay the fields out after the vtable offset, largest first so that
+	// they need no padding between them.
+	fields := append(fbTable{}, t...)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].size() > fields[j].size() })
+	offsets := make([]int, len(fields))
+	size, slots := 4, 0
+	for i, f := range fields {
+		n := f.size()
+		for size%n != 0 {
+			size++
+		}
+		offsets[i] = size
+		size += n
+		if f.slot+1 > slots {
+			slots = f.slot + 1
+		}
+	}
+
+	b.align(2)
+	vtable := len(b.buf)
+	slotOffsets := make([]uint16, slots)
+	for i, f := range fields {
+		slotOffsets[f.slot] = uint16(offsets[i])
+	}
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(4+2*slots))
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(size))
+	for _, off := range slotOffsets {
+		b.buf = binary.LittleEndian.AppendUint16(b.buf, off)
+	}
+
+	// Start the table on 8 bytes so that fields aligned within the table are
+	// aligned in the buffer too.
+	b.align(8)
+	start := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[start:], uint32(int32(start-vtable)))
+	for i, f := range fields {
+		if f.ref == nil {
+			copy(b.buf[start+offsets[i]:], f.scalar)
+		}
+	}
+	for i, f := range fields {
+		if f.ref != nil {
+			at := start + offsets[i]
+			pos := f.ref.writeTo(b)
+			binary.LittleEndian.PutUint32(b.buf[at:], uint32(pos-at))
+		}
+	}
+	return start
+}
+
+// fbVector is a vector of tables or strings.
+type fbVector []fbObject
+
+func (v fbVector) writeTo(b *fbBuilder) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.putUint32(uint32(len(v)))
+	b.buf = append(b.buf, make([]byte, 4*len(v))...)
+	for i, obj := range v {
+		at := pos + 4 + 4*i
+		target := obj.writeTo(b)
+		binary.LittleEndian.PutUint32(b.buf[at:], uint32(target-at))
+	}
+	return pos
+}
+
+// fbStructVector is a vector of n structs of 8-byte aligned fields, already
+// encoded in data.
+type fbStructVector struct {
+	n    int
+	data []byte
+}
+
+func (v fbStructVector) writeTo(b *fbBuilder) int {
+	b.align(4)
+	if (len(b.buf)+4)%8 != 0 {
+		b.buf = append(b.buf, 0, 0, 0, 0)
+	}
+	pos := len(b.buf)
+	b.putUint32(uint32(v.n))
+	b.buf = append(b.buf, v.data...)
+	return pos
+}
+
+// fbString is a null-terminated UTF-8 string.
+type fbString string
+
+func (s fbString) writeTo(b *fbBuilder) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.putUint32(uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
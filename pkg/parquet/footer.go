@@ -0,0 +1,77 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// A Parquet file starts with the PAR1 magic and ends with its footer (the
+// Thrift-encoded file metadata), the footer length as a 4-byte little-endian
+// integer and the PAR1 magic again.
+const (
+	parquetMagic = "PAR1"
+	trailerSize  = 8
+	minFileSize  = len(parquetMagic) + trailerSize
+)
+
+// checkTrailer validates the end of a file of the given size and returns the
+// offset at which its footer starts. Files cut short by an interrupted copy
+// or still being written lack the trailing magic, which is reported as
+// ErrTruncated instead of the decoder's own errors.
+func checkTrailer(file io.ReaderAt, size int64) (int64, error) {
+	if size < int64(minFileSize) {
+		return 0, fmt.Errorf("%w: %d bytes is too small to hold a Parquet footer, at least %d bytes are missing",
+			ErrTruncated, size, int64(minFileSize)-size)
+	}
+
+	trailer := make([]byte, trailerSize)
+	if _, err := file.ReadAt(trailer, size-trailerSize); err != nil && err != io.EOF {
+		return 0, fmt.Errorf("failed to read file trailer: %v", err)
+	}
+	if string(trailer[4:]) != parquetMagic {
+		return 0, fmt.Errorf("%w: the file ends after %d bytes without the PAR1 magic, so its footer is missing "+
+			"(interrupted copy or file still being written?)", ErrTruncated, size)
+	}
+
+	footerSize := int64(binary.LittleEndian.Uint32(trailer[:4]))
+	if footerSize == 0 {
+		return 0, fmt.Errorf("%w: the footer length is 0", ErrCorrupt)
+	}
+	footerStart := size - trailerSize - footerSize
+	if footerStart < int64(len(parquetMagic)) {
+		return 0, fmt.Errorf("%w: the %d-byte footer should start at byte %d, which does not fit in the %d-byte file: %d bytes are missing",
+			ErrTruncated, footerSize, footerStart, size, int64(len(parquetMagic))-footerStart)
+	}
+	return footerStart, nil
+}
+
+// checkColumnChunks verifies that the column chunks listed in the footer lie
+// between the header magic and the footer starting at footerStart.
+func checkColumnChunks(metadata *format.FileMetaData, footerStart int64) error {
+	if metadata == nil {
+		return nil
+	}
+	for i := range metadata.RowGroups {
+		for _, chunk := range metadata.RowGroups[i].Columns {
+			if chunk.FilePath != "" {
+				// stored in another file
+				continue
+			}
+			md := &chunk.MetaData
+			start := md.DataPageOffset
+			if md.DictionaryPageOffset > 0 && md.DictionaryPageOffset < start {
+				start = md.DictionaryPageOffset
+			}
+			end := start + md.TotalCompressedSize
+			if start < int64(len(parquetMagic)) || md.TotalCompressedSize < 0 || end > footerStart {
+				return fmt.Errorf("%w: column %s of row group %d spans bytes %d to %d, outside the data section (bytes %d to %d)",
+					ErrCorrupt, strings.Join(md.PathInSchema, "."), i, start, end, len(parquetMagic), footerStart)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// withTrailer appends a footer length and the PAR1 magic to data.
+func withTrailer(data []byte, footerSize uint32) []byte {
+	trailer := binary.LittleEndian.AppendUint32(nil, footerSize)
+	return append(append(append([]byte{}, data...), trailer...), parquetMagic...)
+}
+
+func TestCheckTrailer(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    []byte
+		want    error
+		message string
+		start   int64
+	}{
+		{"too small", []byte("PAR1abc"), ErrTruncated, "at least 5 bytes are missing", 0},
+		{"missing magic", []byte("PAR1 some row data that was cut"), ErrTruncated, "without the PAR1 magic", 0},
+		{"empty footer", withTrailer([]byte("PAR1data"), 0), ErrCorrupt, "footer length is 0", 0},
+		{"footer too long", withTrailer([]byte("PAR1footer"), 100), ErrTruncated, "should start at byte -90", 0},
+		{"valid", withTrailer([]byte("PAR1datafooter"), 6), nil, "", 8},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, err := checkTrailer(bytes.NewReader(tc.data), int64(len(tc.data)))
+			if tc.want == nil {
+				if err != nil || start != tc.start {
+					t.Errorf("expected footer at %d, got %d, %v", tc.start, start, err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("expected %q in %q", tc.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestCheckColumnChunks(t *testing.T) {
+	chunk := func(offset, size int64) format.ColumnChunk {
+		return format.ColumnChunk{MetaData: format.ColumnMetaData{
+			PathInSchema:        []string{"user", "id"},
+			DataPageOffset:      offset,
+			TotalCompressedSize: size,
+		}}
+	}
+	metadata := &format.FileMetaData{RowGroups: []format.RowGroup{
+		{Columns: []format.ColumnChunk{chunk(4, 100), chunk(104, 50)}},
+	}}
+	if err := checkColumnChunks(metadata, 154); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := checkColumnChunks(metadata, 120)
+	if !errors.Is(err, ErrCorrupt) || !strings.Contains(err.Error(), "column user.id of row group 0") {
+		t.Errorf("expected ErrCorrupt for user.id, got %v", err)
+	}
+}
+
+func TestTruncatedFile(t *testing.T) {
+	data, err := os.ReadFile(fixture("flat.parquet"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	dir := t.TempDir()
+	for _, cut := range []int{1, 8, len(data) / 2} {
+		path := filepath.Join(dir, "partial.parquet")
+		if err := os.WriteFile(path, data[:len(data)-cut], 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := NewParquetReader(path); !errors.Is(err, ErrTruncated) {
+			t.Errorf("cut %d bytes: expected ErrTruncated, got %v", cut, err)
+		}
+	}
+}
@@ -0,0 +1,741 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// Generator makes random rows of a schema, following the generation settings
+// of the fields of its spec.
+type Generator struct {
+	schema *parquet.Schema
+	fields []*fieldGenerator
+	// row makes the rows of generators of NewRowGenerator
+	row  func(index int64, r *rand.Rand) interface{}
+	rand *rand.Rand
+	// index is the index in the dataset of the next row
+	index int64
+	// options are the writer options of the file, such as the codec and
+	// key-value metadata of the file a profile was taken from
+	options []parquet.WriterOption
+	// shard rebuilds the field generators that keep state across rows for
+	// a shard starting at the row first
+	shard func(first int64) []*fieldGenerator
+}
+
+// Defaults of the generation settings of a FieldSpec.
+const (
+	defaultNullRatio = 0.1
+	defaultMinLength = 5
+	defaultMaxLength = 15
+	defaultMaxItems  = 5
+)
+
+var (
+	defaultMinTimestamp = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaultMaxTimestamp = time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
+	defaultMinDate      = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaultMaxDate      = time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// NewGenerator returns a generator of the rows of spec drawing from rng.
+// Each field takes these settings:
+//
+//   - min and max bound numbers, decimals, dates, times and timestamps
+//     (0 to 1000 for numbers, 2020 to 2025 for timestamps)
+//   - values lists the values to pick from, of any leaf type
+//   - null_ratio is the share of nulls of optional fields (0.1)
+//   - min_length and max_length bound the length of strings and byte arrays
+//     (5 to 15) and the number of items of lists and maps (0 to 5)
+func NewGenerator(spec *SchemaSpec, rng *rand.Rand) (*Generator, error) {
+	schema, err := spec.Schema()
+	if err != nil {
+		return nil, err
+	}
+	g := &Generator{schema: schema, rand: rng}
+	for i := range spec.Fields {
+		f, err := newFieldGenerator(&spec.Fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %s: %v", spec.Fields[i].Name, err)
+		}
+		g.fields = append(g.fields, f)
+	}
+	return g, nil
+}
+
+// NewRowGenerator returns a generator of the rows row makes, given the index
+// of each row in the dataset and a source to draw from. Rows are maps of the
+// physical values of schema, or pointers to structs of it.
+func NewRowGenerator(schema *parquet.Schema, row func(index int64, r *rand.Rand) interface{}, rng *rand.Rand) *Generator {
+	return &Generator{schema: schema, row: row, rand: rng}
+}
+
+// Schema returns the schema of the rows.
+func (g *Generator) Schema() *parquet.Schema {
+	return g.schema
+}
+
+// Row returns the next random row. Unless g comes from NewRowGenerator, it is
+// a map of the physical values of the schema.
+func (g *Generator) Row() interface{} {
+	index := g.index
+	g.index++
+	if g.row != nil {
+		return g.row(index, g.rand)
+	}
+	row := make(map[string]interface{}, len(g.fields))
+	for _, f := range g.fields {
+		row[f.name] = f.generate(g.rand)
+	}
+	return row
+}
+
+// Shard returns a generator of the rows of g from the index first on,
+// drawing from rng, as for one file of a dataset written in parallel. g may be
+// sharded many times, concurrently; the shards share nothing that changes.
+func (g *Generator) Shard(first int64, rng *rand.Rand) *Generator {
+	shard := *g
+	shard.rand = rng
+	shard.index = first
+	if g.shard != nil {
+		shard.fields = g.shard(first)
+	}
+	return &shard
+}
+
+// GenerateFile writes n random rows of g to w as a Parquet file, with the
+// row group size and compression of opts.
+func GenerateFile(w io.Writer, g *Generator, n int64, opts ...ImportOption) error {
+	cfg := newImportConfig(opts)
+	writer := parquet.NewWriter(w, cfg.writerOptions(g.schema, g.options...)...)
+	for i := int64(0); i < n; i++ {
+		if err := writer.Write(g.Row()); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// ShardSeed returns the seed of the source shard i of a dataset generated
+// with seed draws from.
+func ShardSeed(seed int64, i int) int64 {
+	return int64(splitmix64(uint64(seed) + uint64(i)))
+}
+
+// generateBlockRows is the largest number of rows of a block, the rows of a
+// file made at once by one worker.
+const generateBlockRows = 8192
+
+// GenerateShards writes a dataset of len(rows) files in parallel: rows[i]
+// rows of g to the file create(i) returns, which is closed once written, with
+// up to workers files written at once. The rows of a file are made by up to
+// workers goroutines at once, in blocks of the row group size of opts, or of
+// 8192 rows if smaller, and written in order, so that a single file is made
+// in parallel too. Block j of shard i draws from a source seeded with
+// ShardSeed(ShardSeed(seed, i), j) and starts at the row after those before
+// it, so that the files depend on g, seed, rows and opts only. Once a file
+// fails, the files not yet started are skipped and the first error returned.
+func GenerateShards(g *Generator, seed int64, rows []int64, workers int, create func(i int) (io.WriteCloser, error), opts ...ImportOption) error {
+	if workers < 1 {
+		workers = 1
+	}
+	cfg := newImportConfig(opts)
+	block := int64(generateBlockRows)
+	if cfg.rowGroupSize > 0 && cfg.rowGroupSize < block {
+		block = cfg.rowGroupSize
+	}
+	pool := &blockPool{
+		workers: make(chan struct{}, workers),
+		blocks:  make(chan struct{}, 2*workers),
+	}
+	firsts := make([]int64, len(rows))
+	for i := 1; i < len(rows); i++ {
+		firsts[i] = firsts[i-1] + rows[i-1]
+	}
+
+	errs := make([]error, len(rows))
+	var failed int32
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(rows); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if atomic.LoadInt32(&failed) != 0 {
+					continue
+				}
+				shard := shardWriter{g: g, seed: ShardSeed(seed, i), first: firsts[i], block: block, pool: pool}
+				if err := shard.generate(rows[i], func() (io.WriteCloser, error) { return create(i) }, cfg); err != nil {
+					errs[i] = err
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	for i := range rows {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shardWriter writes the rows of g of one file of a dataset from the row
+// first on, in blocks of block rows made by the workers of pool.
+type shardWriter struct {
+	g     *Generator
+	seed  int64
+	first int64
+	block int64
+	pool  *blockPool
+}
+
+// generate writes n rows to the file create returns and closes it.
+func (s *shardWriter) generate(n int64, create func() (io.WriteCloser, error), cfg *importConfig) error {
+	w, err := create()
+	if err != nil {
+		return err
+	}
+	err = s.write(w, n, cfg)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (s *shardWriter) write(w io.Writer, n int64, cfg *importConfig) error {
+	writer := parquet.NewWriter(w, cfg.writerOptions(s.g.schema, s.g.options...)...)
+	// the blocks started and not yet written, in order
+	var pending []chan []parquet.Row
+	var start int64
+	for j := 0; start < n || len(pending) > 0; {
+		// start blocks while there is room for them, waiting for room only
+		// when there is nothing to write meanwhile
+		if start < n && s.pool.acquire(len(pending) == 0) {
+			size := s.block
+			if n-start < size {
+				size = n - start
+			}
+			shard := s.g.Shard(s.first+start, rand.New(rand.NewSource(ShardSeed(s.seed, j))))
+			pending = append(pending, s.pool.generate(shard, size))
+			start += size
+			j++
+			continue
+		}
+		rows := <-pending[0]
+		pending = pending[1:]
+		_, err := writer.WriteRows(rows)
+		s.pool.release()
+		if err != nil {
+			for _, c := range pending {
+				<-c
+				s.pool.release()
+			}
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// blockPool bounds the blocks of rows of a dataset made at once to the
+// number of its workers, and those held in memory to twice as many.
+type blockPool struct {
+	workers chan struct{}
+	blocks  chan struct{}
+}
+
+// acquire reserves the room of a block, waiting for it if wait is set, and
+// reports whether it did.
+func (p *blockPool) acquire(wait bool) bool {
+	if wait {
+		p.blocks <- struct{}{}
+		return true
+	}
+	select {
+	case p.blocks <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees the room of a block once written.
+func (p *blockPool) release() {
+	<-p.blocks
+}
+
+// generate makes n rows of g in the background, as rows of its schema, and
+// sends them on the returned channel.
+func (p *blockPool) generate(g *Generator, n int64) chan []parquet.Row {
+	c := make(chan []parquet.Row, 1)
+	go func() {
+		p.workers <- struct{}{}
+		rows := make([]parquet.Row, n)
+		for i := range rows {
+			rows[i] = g.schema.Deconstruct(nil, g.Row())
+		}
+		<-p.workers
+		c <- rows
+	}()
+	return c
+}
+
+// sizeSampleRows is the number of rows RowsForSize writes to measure a row.
+const sizeSampleRows = 10000
+
+// RowsForSize estimates how many rows of g make a Parquet file of about size
+// bytes with opts, from the size of a file of sample rows drawn with seed.
+// It is at least 1.
+func RowsForSize(g *Generator, size, seed int64, opts ...ImportOption) (int64, error) {
+	var empty, sample byteCounter
+	if err := GenerateFile(&empty, g.Shard(0, rand.New(rand.NewSource(seed))), 0, opts...); err != nil {
+		return 0, err
+	}
+	if err := GenerateFile(&sample, g.Shard(0, rand.New(rand.NewSource(seed))), sizeSampleRows, opts...); err != nil {
+		return 0, err
+	}
+	perRow := float64(sample-empty) / sizeSampleRows
+	if perRow <= 0 {
+		return 1, nil
+	}
+	rows := int64(float64(size-int64(empty)) / perRow)
+	if rows < 1 {
+		rows = 1
+	}
+	return rows, nil
+}
+
+// byteCounter is a writer counting the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// fieldGenerator makes the values of a field, a list element or a map
+// value: structs from fields, lists from elem, maps from key and value, and
+// leaves from leaf.
+type fieldGenerator struct {
+	name      string
+	nullRatio float64
+	fields    []*fieldGenerator
+	elem      *fieldGenerator
+	key       *fieldGenerator
+	value     *fieldGenerator
+	// minItems and maxItems bound the number of items of lists and maps,
+	// unless lengths lists the numbers to pick from
+	minItems, maxItems int
+	lengths            []int
+	// leaf makes the values of leaves, or of any node whose values are
+	// picked whole
+	leaf func(r *rand.Rand) interface{}
+}
+
+func newFieldGenerator(f *FieldSpec) (*fieldGenerator, error) {
+	node, err := f.Node()
+	if err != nil {
+		return nil, err
+	}
+	g := &fieldGenerator{name: f.Name}
+	if f.NullRatio != nil {
+		if !f.Optional {
+			return nil, fmt.Errorf("null_ratio needs an optional field")
+		}
+		if *f.NullRatio < 0 || *f.NullRatio > 1 {
+			return nil, fmt.Errorf("null_ratio %g is not between 0 and 1", *f.NullRatio)
+		}
+		g.nullRatio = *f.NullRatio
+	} else if f.Optional {
+		g.nullRatio = defaultNullRatio
+	}
+	if node.Leaf() {
+		g.leaf, err = newLeafGenerator(f, parquet.Required(node))
+		return g, err
+	}
+
+	if f.Min != nil || f.Max != nil || len(f.Values) > 0 {
+		return nil, fmt.Errorf("min, max and values apply to leaf fields")
+	}
+	switch {
+	case isListNode(node):
+		if g.elem, err = newFieldGenerator(f.Element); err != nil {
+			return nil, fmt.Errorf("element: %v", err)
+		}
+	case isMapNode(node):
+		if g.key, err = newFieldGenerator(f.Key); err != nil {
+			return nil, fmt.Errorf("key: %v", err)
+		}
+		if lt := f.Key.leafType(); lt == nil || lt.UTF8 == nil && lt.Enum == nil || f.Key.Optional {
+			return nil, fmt.Errorf("key: map keys must be required strings")
+		}
+		if g.value, err = newFieldGenerator(f.Value); err != nil {
+			return nil, fmt.Errorf("value: %v", err)
+		}
+	default:
+		if f.MinLength != nil || f.MaxLength != nil {
+			return nil, fmt.Errorf("min_length and max_length apply to strings, byte arrays, lists and maps")
+		}
+		for i := range f.Fields {
+			field, err := newFieldGenerator(&f.Fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", f.Fields[i].Name, err)
+			}
+			g.fields = append(g.fields, field)
+		}
+		return g, nil
+	}
+	g.minItems, g.maxItems, err = f.lengths(0, defaultMaxItems)
+	return g, err
+}
+
+func (g *fieldGenerator) generate(r *rand.Rand) interface{} {
+	if g.nullRatio > 0 && r.Float64() < g.nullRatio {
+		return nil
+	}
+	switch {
+	case g.leaf != nil:
+		return g.leaf(r)
+	case g.elem != nil:
+		items := make([]interface{}, g.items(r))
+		for i := range items {
+			items[i] = g.elem.generate(r)
+		}
+		return items
+	case g.value != nil:
+		n := g.items(r)
+		m := make(map[string]interface{}, n)
+		// keys drawn from few values may not make n distinct ones
+		for tries := 0; len(m) < n && tries < 10*n; tries++ {
+			key := g.key.generate(r)
+			if b, ok := bytesValue(key); ok {
+				key = string(b)
+			}
+			m[fmt.Sprint(key)] = g.value.generate(r)
+		}
+		return m
+	}
+	row := make(map[string]interface{}, len(g.fields))
+	for _, f := range g.fields {
+		row[f.name] = f.generate(r)
+	}
+	return row
+}
+
+// items returns the number of items of a list or map.
+func (g *fieldGenerator) items(r *rand.Rand) int {
+	if len(g.lengths) > 0 {
+		return g.lengths[r.Intn(len(g.lengths))]
+	}
+	return g.minItems + r.Intn(g.maxItems-g.minItems+1)
+}
+
+// leafType returns the logical type of a leaf field, nil for other fields.
+func (f *FieldSpec) leafType() *format.LogicalType {
+	node, err := ParseTypeName(f.Type)
+	if err != nil {
+		return nil
+	}
+	return node.Type().LogicalType()
+}
+
+// lengths returns the bounds set by MinLength and MaxLength, or the defaults.
+func (f *FieldSpec) lengths(min, max int) (int, int, error) {
+	if f.MinLength != nil {
+		min = *f.MinLength
+		if f.MaxLength == nil && max < min {
+			max = min
+		}
+	}
+	if f.MaxLength != nil {
+		max = *f.MaxLength
+		if f.MinLength == nil && min > max {
+			min = max
+		}
+	}
+	if min < 0 || max < min {
+		return 0, 0, fmt.Errorf("invalid lengths %d to %d", min, max)
+	}
+	return min, max, nil
+}
+
+// newLeafGenerator returns a function making random physical values of node,
+// a required leaf.
+func newLeafGenerator(f *FieldSpec, node parquet.Node) (func(r *rand.Rand) interface{}, error) {
+	t := node.Type()
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = &format.LogicalType{}
+	}
+	text := lt.UTF8 != nil || lt.Enum != nil || lt.Json != nil
+	variable := t.Kind() == parquet.ByteArray && lt.Decimal == nil
+	if (f.MinLength != nil || f.MaxLength != nil) && !variable {
+		return nil, fmt.Errorf("min_length and max_length apply to strings, byte arrays, lists and maps")
+	}
+	if len(f.Values) > 0 {
+		if f.Min != nil || f.Max != nil || f.MinLength != nil || f.MaxLength != nil {
+			return nil, fmt.Errorf("values cannot be combined with min, max or lengths")
+		}
+		choices := make([]interface{}, len(f.Values))
+		for i, v := range f.Values {
+			choice, err := csvValue(node, string(v))
+			if err != nil {
+				return nil, fmt.Errorf("values: %v", err)
+			}
+			choices[i] = choice
+		}
+		return func(r *rand.Rand) interface{} { return choices[r.Intn(len(choices))] }, nil
+	}
+	if (f.Min != nil || f.Max != nil) && (variable || t.Kind() == parquet.Boolean || lt.UUID != nil ||
+		t.Kind() == parquet.FixedLenByteArray && lt.Decimal == nil) {
+		return nil, fmt.Errorf("min and max apply to numbers, decimals, dates, times and timestamps")
+	}
+
+	switch {
+	case variable:
+		min, max, err := f.lengths(defaultMinLength, defaultMaxLength)
+		if err != nil {
+			return nil, err
+		}
+		return func(r *rand.Rand) interface{} {
+			n := min + r.Intn(max-min+1)
+			switch {
+			case lt.Json != nil:
+				return []byte(fmt.Sprintf(`{"value":%q}`, randomText(r, n)))
+			case text:
+				return []byte(randomText(r, n))
+			}
+			b := make([]byte, n)
+			r.Read(b)
+			return b
+		}, nil
+	case lt.UUID != nil:
+		return func(r *rand.Rand) interface{} {
+			b := make([]byte, 16)
+			r.Read(b)
+			b[6] = b[6]&0x0f | 0x40 // version 4
+			b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+			return b
+		}, nil
+	case lt.Decimal != nil:
+		return decimalGenerator(f, t, lt.Decimal)
+	case t.Kind() == parquet.FixedLenByteArray:
+		return func(r *rand.Rand) interface{} {
+			b := make([]byte, t.Length())
+			r.Read(b)
+			return b
+		}, nil
+	case t.Kind() == parquet.Boolean:
+		return func(r *rand.Rand) interface{} { return r.Intn(2) == 1 }, nil
+	case t.Kind() == parquet.Float || t.Kind() == parquet.Double:
+		min, max, err := f.floatRange(0, 1000)
+		if err != nil {
+			return nil, err
+		}
+		return func(r *rand.Rand) interface{} {
+			v := min + r.Float64()*(max-min)
+			if t.Kind() == parquet.Float {
+				return float32(v)
+			}
+			return v
+		}, nil
+	}
+
+	// the rest are integers: dates, times, timestamps and plain ones
+	var lo, hi int64
+	var err error
+	switch {
+	case lt.Date != nil:
+		lo, hi, err = f.intRange(defaultMinDate.Unix()/86400, defaultMaxDate.Unix()/86400, func(s string) (int64, error) {
+			d, err := parseDate(s)
+			return d.Unix() / 86400, err
+		})
+	case lt.Time != nil:
+		tick, _ := unitDuration(lt.Time.Unit)
+		lo, hi, err = f.intRange(0, int64((24*time.Hour-1)/tick), func(s string) (int64, error) {
+			d, err := parseTimeOfDay(s)
+			return int64(d / tick), err
+		})
+	case lt.Timestamp != nil:
+		tick, _ := unitDuration(lt.Timestamp.Unit)
+		lo, hi, err = f.intRange(ticksOf(defaultMinTimestamp, tick), ticksOf(defaultMaxTimestamp, tick), func(s string) (int64, error) {
+			ts, err := parseTimestamp(normalizeCSVTimestamp(s))
+			return ticksOf(ts, tick), err
+		})
+	default:
+		typeMin, typeMax := integerBounds(t)
+		lo, hi, err = f.intRange(clamp(0, typeMin, typeMax), clamp(1000, typeMin, typeMax), func(s string) (int64, error) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err == nil && (n < typeMin || n > typeMax) {
+				err = fmt.Errorf("%d is out of range for %s", n, f.Type)
+			}
+			return n, err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(r *rand.Rand) interface{} {
+		v, _ := physicalInt(t, randomInt(r, lo, hi))
+		return v
+	}, nil
+}
+
+// intRange returns the bounds set by Min and Max, parsed by parse, or the
+// defaults.
+func (f *FieldSpec) intRange(min, max int64, parse func(string) (int64, error)) (int64, int64, error) {
+	var err error
+	if f.Min != nil {
+		if min, err = parse(string(*f.Min)); err != nil {
+			return 0, 0, fmt.Errorf("min: %v", err)
+		}
+		if f.Max == nil && max < min {
+			max = min
+		}
+	}
+	if f.Max != nil {
+		if max, err = parse(string(*f.Max)); err != nil {
+			return 0, 0, fmt.Errorf("max: %v", err)
+		}
+		if f.Min == nil && min > max {
+			min = max
+		}
+	}
+	if max < min {
+		return 0, 0, fmt.Errorf("max %s is less than min %s", *f.Max, *f.Min)
+	}
+	return min, max, nil
+}
+
+// floatRange returns the bounds set by Min and Max, or the defaults.
+func (f *FieldSpec) floatRange(min, max float64) (float64, float64, error) {
+	var err error
+	if f.Min != nil {
+		if min, err = strconv.ParseFloat(string(*f.Min), 64); err != nil {
+			return 0, 0, fmt.Errorf("min: invalid number %q", *f.Min)
+		}
+		if f.Max == nil && max < min {
+			max = min
+		}
+	}
+	if f.Max != nil {
+		if max, err = strconv.ParseFloat(string(*f.Max), 64); err != nil {
+			return 0, 0, fmt.Errorf("max: invalid number %q", *f.Max)
+		}
+		if f.Min == nil && min > max {
+			min = max
+		}
+	}
+	if max < min || math.IsInf(max-min, 0) || math.IsNaN(max-min) {
+		return 0, 0, fmt.Errorf("invalid range %g to %g", min, max)
+	}
+	return min, max, nil
+}
+
+// decimalGenerator makes decimals between Min and Max, 0 and 1000 by
+// default, of the physical type t.
+func decimalGenerator(f *FieldSpec, t parquet.Type, d *format.DecimalType) (func(r *rand.Rand) interface{}, error) {
+	scale := int(d.Scale)
+	// the largest unscaled value, all nines
+	largest := new(big.Int).Sub(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Precision)), nil), big.NewInt(1))
+	thousand := new(big.Int).Mul(big.NewInt(1000), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	if thousand.Cmp(largest) > 0 {
+		thousand = largest
+	}
+	if !largest.IsInt64() {
+		largest = big.NewInt(math.MaxInt64)
+	}
+	lo, hi, err := f.intRange(0, thousand.Int64(), func(s string) (int64, error) {
+		n, err := parseDecimal(s, scale)
+		if err != nil {
+			return 0, err
+		}
+		if new(big.Int).Abs(n).Cmp(largest) > 0 {
+			return 0, fmt.Errorf("decimal %q is out of range for %s", s, f.Type)
+		}
+		return n.Int64(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	size := 0
+	if t.Kind() == parquet.FixedLenByteArray {
+		size = t.Length()
+	}
+	return func(r *rand.Rand) interface{} {
+		n := randomInt(r, lo, hi)
+		if t.Kind() == parquet.Int32 || t.Kind() == parquet.Int64 {
+			v, _ := physicalInt(t, n)
+			return v
+		}
+		b, _ := decimalBytes(big.NewInt(n), size)
+		return b
+	}, nil
+}
+
+// integerBounds returns the range of the integers of t.
+func integerBounds(t parquet.Type) (int64, int64) {
+	bits, signed := 64, true
+	if lt := t.LogicalType(); lt != nil && lt.Integer != nil {
+		bits, signed = int(lt.Integer.BitWidth), lt.Integer.IsSigned
+	} else if t.Kind() == parquet.Int32 {
+		bits = 32
+	}
+	switch {
+	case !signed && bits == 64:
+		return 0, math.MaxInt64
+	case !signed:
+		return 0, 1<<bits - 1
+	}
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
+
+func clamp(n, min, max int64) int64 {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// randomInt returns a random integer between lo and hi inclusive.
+func randomInt(r *rand.Rand, lo, hi int64) int64 {
+	span := uint64(hi-lo) + 1
+	if span == 0 {
+		// the whole int64 range
+		return int64(r.Uint64())
+	}
+	return lo + int64(r.Uint64()%span)
+}
+
+const randomTextLetters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// randomText returns n random lowercase letters and digits.
+func randomText(r *rand.Rand, n int) string {
+	var b strings.Builder
+	b.Grow(n)
+	for i := 0; i < n; i++ {
+		b.WriteByte(randomTextLetters[r.Intn(len(randomTextLetters))])
+	}
+	return b.String()
+}
@@ -0,0 +1,245 @@
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestGenerator(t *testing.T) {
+	spec, err := ParseSchemaSpec([]byte(`
+fields:
+  - {name: id, type: int32, min: 10, max: 20}
+  - {name: score, type: double, min: -1.5, max: 1.5}
+  - {name: status, type: string, values: [active, closed]}
+  - {name: code, type: string, min_length: 3, max_length: 3}
+  - {name: price, type: "decimal(9,2)", min: "1.00", max: "2.50"}
+  - {name: day, type: date, min: 2024-01-01, max: 2024-01-31}
+  - {name: at, type: "timestamp[ms]", min: "2024-01-01 00:00:00", max: "2024-01-02 00:00:00"}
+  - {name: token, type: uuid}
+  - name: note
+    type: string
+    optional: true
+    null_ratio: 0.5
+  - name: tags
+    type: list
+    min_length: 1
+    max_length: 3
+    element: {type: string, values: [a, b]}
+  - name: attrs
+    type: map
+    max_length: 2
+    key: {type: string}
+    value: {type: int64, optional: true, null_ratio: 0}
+  - name: info
+    type: struct
+    fields:
+      - {name: city, type: enum, values: [Paris, Oslo]}
+`))
+	if err != nil {
+		t.Fatalf("ParseSchemaSpec: %v", err)
+	}
+	g, err := NewGenerator(spec, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	if fieldByName(g.Schema(), "info") == nil || fieldByName(g.Schema(), "tags") == nil {
+		t.Fatalf("unexpected schema")
+	}
+
+	nulls := 0
+	for i := 0; i < 1000; i++ {
+		row := g.Row().(map[string]interface{})
+		if id := row["id"].(int32); id < 10 || id > 20 {
+			t.Fatalf("id %d out of range", id)
+		}
+		if score := row["score"].(float64); score < -1.5 || score > 1.5 {
+			t.Fatalf("score %g out of range", score)
+		}
+		if s := string(row["status"].([]byte)); s != "active" && s != "closed" {
+			t.Fatalf("unexpected status %q", s)
+		}
+		if n := len(row["code"].([]byte)); n != 3 {
+			t.Fatalf("code of length %d", n)
+		}
+		if price := row["price"].(int32); price < 100 || price > 250 {
+			t.Fatalf("price %d out of range", price)
+		}
+		if day := row["day"].(int32); day < 19723 || day > 19753 {
+			t.Fatalf("day %d out of range", day)
+		}
+		if at := row["at"].(int64); at < 1704067200000 || at > 1704153600000 {
+			t.Fatalf("at %d out of range", at)
+		}
+		if token := row["token"].([]byte); len(token) != 16 || token[6]>>4 != 4 {
+			t.Fatalf("token %x is not a version 4 UUID", token)
+		}
+		if row["note"] == nil {
+			nulls++
+		}
+		tags := row["tags"].([]interface{})
+		if len(tags) < 1 || len(tags) > 3 {
+			t.Fatalf("%d tags", len(tags))
+		}
+		attrs := row["attrs"].(map[string]interface{})
+		if len(attrs) > 2 {
+			t.Fatalf("%d attrs", len(attrs))
+		}
+		for _, v := range attrs {
+			if v == nil {
+				t.Fatalf("attrs: unexpected null")
+			}
+		}
+		if city := string(row["info"].(map[string]interface{})["city"].([]byte)); city != "Paris" && city != "Oslo" {
+			t.Fatalf("unexpected city %q", city)
+		}
+	}
+	if nulls < 400 || nulls > 600 {
+		t.Errorf("got %d null notes in 1000 rows, want about 500", nulls)
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	for data, want := range map[string]string{
+		`[{"name": "a", "type": "int8", "max": 200}]`:                                          "a: max: 200 is out of range for int8",
+		`[{"name": "a", "type": "int32", "min": 5, "max": 1}]`:                                 "a: max 1 is less than min 5",
+		`[{"name": "a", "type": "string", "min": 1}]`:                                          "a: min and max apply to numbers",
+		`[{"name": "a", "type": "int32", "null_ratio": 0.5}]`:                                  "a: null_ratio needs an optional field",
+		`[{"name": "a", "type": "int32", "optional": true, "null_ratio": 2}]`:                  "a: null_ratio 2 is not between 0 and 1",
+		`[{"name": "a", "type": "bool", "max_length": 2}]`:                                     "a: min_length and max_length apply to",
+		`[{"name": "a", "type": "date", "values": ["yesterday"]}]`:                             `a: values: invalid date "yesterday"`,
+		`[{"name": "a", "type": "decimal(4,2)", "max": "100.00"}]`:                             "a: max: decimal \"100.00\" is out of range",
+		`[{"name": "a", "type": "map", "key": {"type": "int32"}, "value": {"type": "int32"}}]`: "a: key: map keys must be required strings",
+		`[{"name": "a", "type": "list", "element": {"type": "int32", "min": "x"}}]`:            "a: element: min:",
+	} {
+		spec, err := ParseSchemaSpec([]byte(data))
+		if err == nil {
+			_, err = NewGenerator(spec, rand.New(rand.NewSource(1)))
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", data, err, want)
+		}
+	}
+}
+
+func TestGeneratorShard(t *testing.T) {
+	spec, err := ParseSchemaSpec([]byte(`[{"name": "n", "type": "int64"}, {"name": "s", "type": "string", "optional": true}]`))
+	if err != nil {
+		t.Fatalf("ParseSchemaSpec: %v", err)
+	}
+	g, err := NewGenerator(spec, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	a := g.Shard(100, rand.New(rand.NewSource(ShardSeed(7, 1))))
+	b := g.Shard(100, rand.New(rand.NewSource(ShardSeed(7, 1))))
+	for i := 0; i < 100; i++ {
+		if ra, rb := a.Row(), b.Row(); !reflect.DeepEqual(ra, rb) {
+			t.Fatalf("row %d: %v != %v", i, ra, rb)
+		}
+	}
+	if ShardSeed(7, 0) == ShardSeed(7, 1) || ShardSeed(7, 0) == ShardSeed(8, 0) {
+		t.Errorf("expected distinct shard seeds")
+	}
+
+	rows := NewRowGenerator(nil, func(index int64, r *rand.Rand) interface{} { return index }, nil)
+	shard := rows.Shard(5, nil)
+	if i := shard.Row(); i != int64(5) {
+		t.Errorf("expected row 5, got %v", i)
+	}
+	if i := shard.Row(); i != int64(6) {
+		t.Errorf("expected row 6, got %v", i)
+	}
+	if i := rows.Row(); i != int64(0) {
+		t.Errorf("expected row 0, got %v", i)
+	}
+}
+
+type closingBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closingBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestGenerateShards(t *testing.T) {
+	spec, err := ParseSchemaSpec([]byte(`[{"name": "n", "type": "int64"}, {"name": "s", "type": "string", "optional": true}]`))
+	if err != nil {
+		t.Fatalf("ParseSchemaSpec: %v", err)
+	}
+	g, err := NewGenerator(spec, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	generate := func(workers int) []*closingBuffer {
+		files := make([]*closingBuffer, 4)
+		for i := range files {
+			files[i] = &closingBuffer{}
+		}
+		err := GenerateShards(g, 42, []int64{300, 300, 200, 0}, workers, func(i int) (io.WriteCloser, error) {
+			return files[i], nil
+		}, WithRowGroupSize(100))
+		if err != nil {
+			t.Fatalf("GenerateShards: %v", err)
+		}
+		return files
+	}
+	serial, parallel := generate(1), generate(3)
+	for i := range serial {
+		if !serial[i].closed || !parallel[i].closed {
+			t.Errorf("file %d was not closed", i)
+		}
+		if !bytes.Equal(serial[i].Bytes(), parallel[i].Bytes()) {
+			t.Errorf("file %d differs between runs", i)
+		}
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(serial[2].Bytes()), int64(serial[2].Len()))
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if n := f.NumRows(); n != 200 {
+		t.Errorf("expected 200 rows, got %d", n)
+	}
+
+	// a single file is made in blocks by several workers alike
+	single := func(workers int) *closingBuffer {
+		file := &closingBuffer{}
+		err := GenerateShards(g, 42, []int64{1000}, workers, func(int) (io.WriteCloser, error) {
+			return file, nil
+		}, WithRowGroupSize(100))
+		if err != nil {
+			t.Fatalf("GenerateShards: %v", err)
+		}
+		return file
+	}
+	serial[0], parallel[0] = single(1), single(4)
+	if !bytes.Equal(serial[0].Bytes(), parallel[0].Bytes()) {
+		t.Errorf("single file differs between runs")
+	}
+	f, err = parquet.OpenFile(bytes.NewReader(parallel[0].Bytes()), int64(parallel[0].Len()))
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if n, groups := f.NumRows(), len(f.RowGroups()); n != 1000 || groups != 10 {
+		t.Errorf("expected 1000 rows in 10 row groups, got %d in %d", n, groups)
+	}
+
+	failing := func(i int) (io.WriteCloser, error) {
+		if i == 1 {
+			return nil, fmt.Errorf("no space left")
+		}
+		return &closingBuffer{}, nil
+	}
+	if err := GenerateShards(g, 42, []int64{1, 1, 1}, 2, failing); err == nil || err.Error() != "no space left" {
+		t.Errorf("expected the error of the second file, got %v", err)
+	}
+}
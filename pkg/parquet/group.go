@@ -0,0 +1,73 @@
+package parquet
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+	"github.com/parquet-go/parquet-go/encoding"
+)
+
+// orderedGroup is a group node whose fields keep the order they were added
+// in, where parquet.Group sorts them by name. Schemas built from a file, a
+// header or a spec use it so that columns stay in their source order.
+type orderedGroup []parquet.Field
+
+// groupField is a named field of an orderedGroup. Like the fields of a
+// parquet.Group, it reads its value from a map by name.
+type groupField struct {
+	parquet.Node
+	name string
+}
+
+func (f groupField) Name() string { return f.name }
+
+func (f groupField) Value(base reflect.Value) reflect.Value {
+	if base.Kind() == reflect.Interface {
+		if base.IsNil() {
+			return reflect.ValueOf(nil)
+		}
+		if base = base.Elem(); base.Kind() == reflect.Ptr && base.IsNil() {
+			return reflect.ValueOf(nil)
+		}
+	}
+	return base.MapIndex(reflect.ValueOf(&f.name).Elem())
+}
+
+// add appends a field, or replaces the field of the same name in place.
+func (g *orderedGroup) add(name string, node parquet.Node) {
+	for i, f := range *g {
+		if f.Name() == name {
+			(*g)[i] = groupField{node, name}
+			return
+		}
+	}
+	*g = append(*g, groupField{node, name})
+}
+
+// group returns the fields of g as a parquet.Group.
+func (g orderedGroup) group() parquet.Group {
+	group := make(parquet.Group, len(g))
+	for _, f := range g {
+		group[f.Name()] = f
+	}
+	return group
+}
+
+func (g orderedGroup) ID() int                     { return 0 }
+func (g orderedGroup) Type() parquet.Type          { return parquet.Group{}.Type() }
+func (g orderedGroup) Optional() bool              { return false }
+func (g orderedGroup) Repeated() bool              { return false }
+func (g orderedGroup) Required() bool              { return true }
+func (g orderedGroup) Leaf() bool                  { return false }
+func (g orderedGroup) Fields() []parquet.Field     { return g }
+func (g orderedGroup) Encoding() encoding.Encoding { return nil }
+func (g orderedGroup) Compression() compress.Codec { return nil }
+func (g orderedGroup) GoType() reflect.Type        { return g.group().GoType() }
+
+func (g orderedGroup) String() string {
+	var b strings.Builder
+	parquet.PrintSchema(&b, "", g)
+	return b.String()
+}
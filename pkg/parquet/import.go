@@ -0,0 +1,413 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+)
+
+// ImportOption configures how records are converted to Parquet.
+type ImportOption func(*importConfig)
+
+type importConfig struct {
+	inferRows    int
+	schema       *parquet.Schema
+	rowGroupSize int64
+	codec        compress.Codec
+	// types maps the columns whose inferred type is overridden to a type
+	// name (see ParseTypeName)
+	types map[string]string
+
+	// CSV input
+	delimiter  rune
+	quote      rune
+	header     headerMode
+	nullValues []string
+}
+
+// DefaultInferRows is the number of leading records a schema is inferred
+// from.
+const DefaultInferRows = 1000
+
+func newImportConfig(opts []ImportOption) *importConfig {
+	cfg := &importConfig{inferRows: DefaultInferRows, delimiter: ',', quote: '"'}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithInferRows infers the schema from the first n records, or from all of
+// them when n is 0. They are held in memory until the schema is known.
+func WithInferRows(n int) ImportOption {
+	return func(c *importConfig) {
+		c.inferRows = n
+	}
+}
+
+// WithImportSchema writes the records with schema instead of inferring one.
+func WithImportSchema(schema *parquet.Schema) ImportOption {
+	return func(c *importConfig) {
+		c.schema = schema
+	}
+}
+
+// WithRowGroupSize starts a new row group every n rows.
+func WithRowGroupSize(n int64) ImportOption {
+	return func(c *importConfig) {
+		c.rowGroupSize = n
+	}
+}
+
+// WithCompression compresses the pages of the file with codec (see
+// ParseCompression).
+func WithCompression(codec compress.Codec) ImportOption {
+	return func(c *importConfig) {
+		c.codec = codec
+	}
+}
+
+// WithColumnType gives a top-level column the type named typ (see
+// ParseTypeName) instead of the inferred one, e.g. "timestamp[ms]".
+func WithColumnType(column, typ string) ImportOption {
+	return func(c *importConfig) {
+		if c.types == nil {
+			c.types = make(map[string]string)
+		}
+		c.types[column] = typ
+	}
+}
+
+// CompressionCodecs lists the codecs ParseCompression accepts.
+var CompressionCodecs = []string{"none", "snappy", "gzip", "brotli", "zstd", "lz4"}
+
+// ParseCompression returns the codec of a name in CompressionCodecs.
+func ParseCompression(name string) (compress.Codec, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "none", "uncompressed":
+		return &parquet.Uncompressed, nil
+	case "snappy":
+		return &parquet.Snappy, nil
+	case "gzip":
+		return &parquet.Gzip, nil
+	case "brotli":
+		return &parquet.Brotli, nil
+	case "zstd":
+		return &parquet.Zstd, nil
+	case "lz4", "lz4raw", "lz4_raw":
+		return &parquet.Lz4Raw, nil
+	}
+	return nil, fmt.Errorf("unknown compression %q, expected one of %s", name, strings.Join(CompressionCodecs, ", "))
+}
+
+// writerOptions returns the options of the writer of an imported file. The
+// options of c come after extra, so that they override it.
+func (c *importConfig) writerOptions(schema *parquet.Schema, extra ...parquet.WriterOption) []parquet.WriterOption {
+	opts := append([]parquet.WriterOption{schema}, extra...)
+	if c.rowGroupSize > 0 {
+		opts = append(opts, parquet.MaxRowsPerRowGroup(c.rowGroupSize))
+	}
+	if c.codec != nil {
+		opts = append(opts, parquet.Compression(c.codec))
+	}
+	return opts
+}
+
+// ImportJSONL reads JSON Lines, one object per line, and writes them to w as
+// a Parquet file. Unless a schema is given, it is inferred from the leading
+// records (see WithInferRows): nested objects become structs, arrays lists,
+// and fields missing from some records optional. It returns the number of
+// rows written.
+func ImportJSONL(r io.Reader, w io.Writer, opts ...ImportOption) (int64, error) {
+	cfg := newImportConfig(opts)
+	br := bufio.NewReader(r)
+	line := 0
+	// the order of the fields, recorded until the schema is inferred
+	var order *fieldOrder
+	if cfg.schema == nil {
+		order = &fieldOrder{}
+	}
+	next := func() (map[string]interface{}, int, error) {
+		for {
+			data, err := br.ReadBytes('\n')
+			if len(data) == 0 && err != nil {
+				if err == io.EOF {
+					return nil, line, nil
+				}
+				return nil, line, err
+			}
+			line++
+			data = bytes.TrimSpace(data)
+			if len(data) == 0 {
+				continue
+			}
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			var record map[string]interface{}
+			if err := dec.Decode(&record); err != nil || record == nil {
+				return nil, line, fmt.Errorf("line %d: expected a JSON object", line)
+			}
+			if dec.More() {
+				return nil, line, fmt.Errorf("line %d: expected one JSON object per line", line)
+			}
+			if order != nil {
+				order.scan(json.NewDecoder(bytes.NewReader(data)))
+			}
+			return record, line, nil
+		}
+	}
+	infer := func(records []map[string]interface{}) *parquet.Schema {
+		schema := inferSchema(records, order)
+		order = nil
+		return schema
+	}
+	return importRecords(w, cfg, next, infer, importRow)
+}
+
+// recordSource returns the next record of an input and the line it starts
+// on, or a nil record at the end of the input.
+type recordSource func() (map[string]interface{}, int, error)
+
+// importRecords writes the records of next to w as a Parquet file, each
+// converted to the physical values of the schema by convert. Unless a schema
+// is given, infer builds one from the leading records, which are held in
+// memory until it is known.
+func importRecords(w io.Writer, cfg *importConfig, next recordSource,
+	infer func([]map[string]interface{}) *parquet.Schema,
+	convert func(parquet.Node, map[string]interface{}) (map[string]interface{}, error)) (int64, error) {
+	schema := cfg.schema
+	var buffered []map[string]interface{}
+	var bufferedLines []int
+	if schema == nil {
+		for cfg.inferRows == 0 || len(buffered) < cfg.inferRows {
+			record, line, err := next()
+			if err != nil {
+				return 0, err
+			}
+			if record == nil {
+				break
+			}
+			buffered = append(buffered, record)
+			bufferedLines = append(bufferedLines, line)
+		}
+		if len(buffered) == 0 {
+			return 0, fmt.Errorf("no records to infer a schema from")
+		}
+		schema = infer(buffered)
+	}
+	schema, err := cfg.applyTypes(schema)
+	if err != nil {
+		return 0, err
+	}
+
+	writer := parquet.NewWriter(w, cfg.writerOptions(schema)...)
+	var rows int64
+	write := func(record map[string]interface{}, line int) error {
+		row, err := convert(schema, record)
+		if err == nil {
+			err = writer.Write(row)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		rows++
+		return nil
+	}
+	for i, record := range buffered {
+		if err := write(record, bufferedLines[i]); err != nil {
+			return rows, err
+		}
+	}
+	for {
+		record, line, err := next()
+		if err != nil {
+			return rows, err
+		}
+		if record == nil {
+			break
+		}
+		if err := write(record, line); err != nil {
+			return rows, err
+		}
+	}
+	return rows, writer.Close()
+}
+
+// applyTypes replaces the types of the top-level fields set by
+// WithColumnType, keeping their repetition.
+func (c *importConfig) applyTypes(schema *parquet.Schema) (*parquet.Schema, error) {
+	if len(c.types) == 0 {
+		return schema, nil
+	}
+	var group orderedGroup
+	for _, field := range schema.Fields() {
+		group.add(field.Name(), field)
+	}
+	names := make([]string, 0, len(c.types))
+	for name := range c.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := fieldByName(schema, name)
+		if field == nil {
+			return nil, fmt.Errorf("cannot set the type of %s: no such column", name)
+		}
+		node, err := ParseTypeName(c.types[name])
+		if err != nil {
+			return nil, fmt.Errorf("cannot set the type of %s: %v", name, err)
+		}
+		if field.Optional() {
+			node = parquet.Optional(node)
+		}
+		group.add(name, node)
+	}
+	return parquet.NewSchema(schema.Name(), group), nil
+}
+
+// importRow converts a record to the physical values of the fields of node.
+// Records must not hold fields the schema does not have.
+func importRow(node parquet.Node, record map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(record))
+	for k := range record {
+		if fieldByName(node, k) == nil {
+			return nil, fmt.Errorf("field %s is not in the schema", k)
+		}
+	}
+	for _, field := range node.Fields() {
+		v, err := importValue(field, record[field.Name()])
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", field.Name(), err)
+		}
+		out[field.Name()] = v
+	}
+	return out, nil
+}
+
+// importValue converts a value decoded from JSON, or parsed from text, to
+// the physical value of node.
+func importValue(node parquet.Node, v interface{}) (interface{}, error) {
+	if v == nil {
+		if node.Required() {
+			return nil, fmt.Errorf("missing value")
+		}
+		return nil, nil
+	}
+	if node.Repeated() || isListNode(node) {
+		elem := listElement(node)
+		if node.Repeated() {
+			elem = parquet.Required(node)
+		}
+		items, ok := v.([]interface{})
+		if !ok || elem == nil {
+			return nil, fmt.Errorf("expected an array, got %s", jsonText(v))
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			stored, err := importValue(elem, item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			out[i] = stored
+		}
+		return out, nil
+	}
+	if isMapNode(node) {
+		_, value := mapKeyValue(node)
+		m, ok := v.(map[string]interface{})
+		if !ok || value == nil {
+			return nil, fmt.Errorf("expected an object, got %s", jsonText(v))
+		}
+		out := make(map[string]interface{}, len(m))
+		for k, item := range m {
+			stored, err := importValue(value, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			out[k] = stored
+		}
+		return out, nil
+	}
+	if !node.Leaf() {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object, got %s", jsonText(v))
+		}
+		return importRow(node, m)
+	}
+
+	t := node.Type()
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	if lt != nil && (lt.UTF8 != nil || lt.Enum != nil || lt.Json != nil) {
+		// strings hold the JSON text of other values
+		if s, ok := v.(string); ok && lt.Json == nil {
+			return []byte(s), nil
+		}
+		return []byte(jsonText(v)), nil
+	}
+	if n, ok := v.(json.Number); ok {
+		switch {
+		case lt != nil && lt.Decimal != nil:
+			v = string(n)
+		case t.Kind() == parquet.Float || t.Kind() == parquet.Double:
+			f, err := n.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s", n)
+			}
+			v = f
+		default:
+			i, err := strconv.ParseInt(string(n), 10, 64)
+			if err != nil {
+				f, ferr := n.Float64()
+				if ferr != nil || f != math.Trunc(f) || math.Abs(f) >= 1<<63 {
+					return nil, fmt.Errorf("expected an integer, got %s", n)
+				}
+				i = int64(f)
+			}
+			v = i
+		}
+	}
+	switch t.Kind() {
+	case parquet.Boolean:
+		if _, ok := v.(bool); !ok {
+			return nil, fmt.Errorf("expected a boolean, got %s", jsonText(v))
+		}
+		return v, nil
+	case parquet.Float:
+		if f, ok := v.(float64); ok {
+			return float32(f), nil
+		}
+		return nil, fmt.Errorf("expected a number, got %s", jsonText(v))
+	case parquet.Double:
+		if _, ok := v.(float64); !ok {
+			return nil, fmt.Errorf("expected a number, got %s", jsonText(v))
+		}
+		return v, nil
+	}
+	switch v.(type) {
+	case bool, map[string]interface{}, []interface{}:
+		return nil, fmt.Errorf("unexpected %s", jsonText(v))
+	}
+	return storedLeaf(t, v)
+}
+
+// jsonText returns the JSON text of a decoded value.
+func jsonText(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
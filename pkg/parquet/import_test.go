@@ -0,0 +1,210 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func decodeRecords(t *testing.T, lines ...string) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range lines {
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("decode %s: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestInferSchema(t *testing.T) {
+	schema := inferSchema(decodeRecords(t,
+		`{"id": 1, "score": 1, "name": "a", "tags": ["x"], "info": {"city": "Paris"}, "mixed": 1, "empty": []}`,
+		`{"id": 2, "score": 2.5, "name": null, "tags": [], "info": {"city": "Rome", "zip": "00100"}, "mixed": "b", "extra": true}`,
+	), nil)
+	field := func(name string) parquet.Node {
+		node := fieldByName(schema, name)
+		if node == nil {
+			t.Fatalf("no field %s", name)
+		}
+		return node
+	}
+
+	if n := field("id"); !n.Required() || n.Type().Kind() != parquet.Int64 {
+		t.Errorf("id: expected a required INT64")
+	}
+	if n := field("score"); n.Type().Kind() != parquet.Double {
+		t.Errorf("score: expected ints and floats to widen to DOUBLE")
+	}
+	if n := field("name"); !n.Optional() || n.Type().LogicalType() == nil || n.Type().LogicalType().UTF8 == nil {
+		t.Errorf("name: expected an optional string")
+	}
+	if n := field("mixed"); n.Type().LogicalType() == nil || n.Type().LogicalType().UTF8 == nil {
+		t.Errorf("mixed: expected conflicting kinds to widen to a string")
+	}
+	if n := field("extra"); !n.Optional() || n.Type().Kind() != parquet.Boolean {
+		t.Errorf("extra: expected an optional BOOLEAN")
+	}
+	if n := field("tags"); !isListNode(n) || listElement(n).Type().LogicalType().UTF8 == nil {
+		t.Errorf("tags: expected a list of strings")
+	}
+	if n := field("empty"); !isListNode(n) {
+		t.Errorf("empty: expected a list")
+	}
+	info := field("info")
+	if info.Leaf() || !fieldByName(info, "city").Required() || !fieldByName(info, "zip").Optional() {
+		t.Errorf("info: expected a struct with a required city and an optional zip")
+	}
+}
+
+func TestInferSchemaOrder(t *testing.T) {
+	lines := []string{
+		`{"zeta": 1, "alpha": {"y": 1, "x": [{"q": 1, "p": 2}]}, "mid": "a"}`,
+		`{"mid": "b", "beta": true, "alpha": {"w": 2, "y": 3}}`,
+	}
+	order := &fieldOrder{}
+	for _, line := range lines {
+		if err := order.scan(json.NewDecoder(strings.NewReader(line))); err != nil {
+			t.Fatalf("scan %s: %v", line, err)
+		}
+	}
+	schema := inferSchema(decodeRecords(t, lines...), order)
+	names := func(node parquet.Node) string {
+		var names []string
+		for _, field := range node.Fields() {
+			names = append(names, field.Name())
+		}
+		return strings.Join(names, ",")
+	}
+	alpha := fieldByName(schema, "alpha")
+	for _, tc := range []struct {
+		name string
+		node parquet.Node
+		want string
+	}{
+		{"top level", schema, "zeta,alpha,mid,beta"},
+		{"struct", alpha, "y,x,w"},
+		{"list element", listElement(fieldByName(alpha, "x")), "q,p"},
+	} {
+		if got := names(tc.node); got != tc.want {
+			t.Errorf("%s: got fields %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	cfg := newImportConfig([]ImportOption{WithColumnType("alpha", "string")})
+	typed, err := cfg.applyTypes(schema)
+	if err != nil {
+		t.Fatalf("applyTypes: %v", err)
+	}
+	if got := names(typed); got != "zeta,alpha,mid,beta" {
+		t.Errorf("applyTypes: got fields %s, want the inferred order", got)
+	}
+}
+
+func TestImportRow(t *testing.T) {
+	schema := parquet.NewSchema("schema", parquet.Group{
+		"id":    parquet.Int(32),
+		"at":    parquet.Optional(parquet.Timestamp(parquet.Millisecond)),
+		"price": parquet.Optional(parquet.Decimal(2, 9, parquet.Int32Type)),
+		"note":  parquet.Optional(parquet.String()),
+		"ratio": parquet.Optional(parquet.Leaf(parquet.FloatType)),
+		"tags":  parquet.Optional(parquet.List(parquet.String())),
+	})
+	records := decodeRecords(t, `{"id": 7, "at": "1970-01-01T00:00:01.5Z", "price": 12.34, "note": {"a": 1}, "ratio": 0.5, "tags": ["x", "y"]}`)
+	row, err := importRow(schema, records[0])
+	if err != nil {
+		t.Fatalf("importRow: %v", err)
+	}
+	want := map[string]interface{}{
+		"id":    int32(7),
+		"at":    int64(1500),
+		"price": int32(1234),
+		"note":  []byte(`{"a":1}`),
+		"ratio": float32(0.5),
+		"tags":  []interface{}{[]byte("x"), []byte("y")},
+	}
+	got, _ := json.Marshal(row)
+	wanted, _ := json.Marshal(want)
+	if string(got) != string(wanted) {
+		t.Errorf("got  %s\nwant %s", got, wanted)
+	}
+
+	for record, wantErr := range map[string]string{
+		`{"at": null}`:             "field id: missing value",
+		`{"id": 1.5}`:              "expected an integer",
+		`{"id": 1, "other": 2}`:    "field other is not in the schema",
+		`{"id": 1, "tags": "x"}`:   "expected an array",
+		`{"id": 1, "ratio": "x"}`:  "expected a number",
+		`{"id": 1, "at": "today"}`: "invalid timestamp",
+	} {
+		_, err := importRow(schema, decodeRecords(t, record)[0])
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: got error %v, want %q", record, err, wantErr)
+		}
+	}
+}
+
+func TestImportJSONLErrors(t *testing.T) {
+	cases := map[string]string{
+		"":                                   "no records",
+		"{\"a\": 1}\n[1]\n":                  "line 2: expected a JSON object",
+		"{\"a\": 1} {\"a\": 2}\n":            "line 1: expected one JSON object per line",
+		"{\"a\": 1}\n\n{\"a\": 2, \"b\": 3}": "line 3: field b is not in the schema",
+	}
+	for input, want := range cases {
+		_, err := ImportJSONL(strings.NewReader(input), &bytes.Buffer{}, WithInferRows(1))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: got error %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestImportJSONLRoundTrip(t *testing.T) {
+	input := `{"id": 1, "name": "a", "tags": ["x", "y"], "info": {"city": "Paris"}, "score": 0.5}
+{"id": 2, "name": null, "tags": [], "info": {"city": "Rome"}}
+{"id": 3, "tags": ["z"], "info": {"city": "Oslo"}, "score": 2}
+`
+	path := filepath.Join(t.TempDir(), "out.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := ImportJSONL(strings.NewReader(input), f, WithRowGroupSize(2), WithCompression(&parquet.Zstd))
+	f.Close()
+	if err != nil {
+		t.Fatalf("ImportJSONL: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("got %d rows, want 3", n)
+	}
+
+	ds, err := OpenDataset([]string{path})
+	if err != nil {
+		t.Fatalf("OpenDataset: %v", err)
+	}
+	defer ds.Close()
+	rows, err := ds.Head(10)
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := PrintJSON(rows, &buf, false); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":1,"info":{"city":"Paris"},"name":"a","score":0.5,"tags":["x","y"]}
+{"id":2,"info":{"city":"Rome"},"name":null,"score":null,"tags":[]}
+{"id":3,"info":{"city":"Oslo"},"name":null,"score":2,"tags":["z"]}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package parquet
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// inferKind is the kind of JSON values seen for a field.
+type inferKind int
+
+const (
+	// inferNull: only nulls, or nothing, seen so far
+	inferNull inferKind = iota
+	inferBool
+	inferInt
+	inferFloat
+	inferString
+	inferObject
+	inferArray
+)
+
+// inferredType accumulates the values seen for a field, a list element or a
+// record. Conflicting kinds widen: integers and floats to double, anything
+// else to a string holding the JSON text of values that are not strings.
+type inferredType struct {
+	kind inferKind
+	// optional is set once a null is seen, or once the field is missing
+	// from an object
+	optional bool
+	// fields holds the fields of objects
+	fields map[string]*inferredType
+	// objects counts the objects seen, to spot fields missing from some
+	objects int
+	elem    *inferredType
+}
+
+// observe widens t to hold v, a value decoded from JSON with UseNumber.
+func (t *inferredType) observe(v interface{}) {
+	kind := jsonKind(v)
+	if kind == inferNull {
+		t.optional = true
+		return
+	}
+	switch {
+	case t.kind == inferNull:
+		t.kind = kind
+	case t.kind == kind:
+	case (t.kind == inferInt || t.kind == inferFloat) && (kind == inferInt || kind == inferFloat):
+		t.kind = inferFloat
+	default:
+		t.kind = inferString
+	}
+	if t.kind != kind {
+		// widened: nested types no longer matter
+		t.fields, t.elem = nil, nil
+		return
+	}
+
+	switch kind {
+	case inferObject:
+		m := v.(map[string]interface{})
+		if t.fields == nil {
+			t.fields = make(map[string]*inferredType)
+		}
+		for k, item := range m {
+			f, ok := t.fields[k]
+			if !ok {
+				// missing from the objects seen before
+				f = &inferredType{optional: t.objects > 0}
+				t.fields[k] = f
+			}
+			f.observe(item)
+		}
+		for k, f := range t.fields {
+			if _, ok := m[k]; !ok {
+				f.optional = true
+			}
+		}
+		t.objects++
+	case inferArray:
+		if t.elem == nil {
+			t.elem = &inferredType{}
+		}
+		for _, item := range v.([]interface{}) {
+			t.elem.observe(item)
+		}
+	}
+}
+
+func jsonKind(v interface{}) inferKind {
+	switch v := v.(type) {
+	case bool:
+		return inferBool
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return inferInt
+		}
+		return inferFloat
+	case float64:
+		return inferFloat
+	case string:
+		return inferString
+	case map[string]interface{}:
+		return inferObject
+	case []interface{}:
+		return inferArray
+	}
+	return inferNull
+}
+
+// node returns the Parquet node of the values seen, with the fields of
+// objects in the order of order. Fields seen only as null are optional
+// strings; objects without fields are stored as JSON.
+func (t *inferredType) node(order *fieldOrder) parquet.Node {
+	var node parquet.Node
+	switch t.kind {
+	case inferBool:
+		node = parquet.Leaf(parquet.BooleanType)
+	case inferInt:
+		node = parquet.Int(64)
+	case inferFloat:
+		node = parquet.Leaf(parquet.DoubleType)
+	case inferObject:
+		if len(t.fields) == 0 {
+			node = parquet.JSON()
+		} else {
+			node = t.group(order)
+		}
+	case inferArray:
+		elem := t.elem
+		if elem == nil {
+			elem = &inferredType{}
+		}
+		node = parquet.List(elem.node(order.element()))
+	default:
+		node = parquet.String()
+	}
+	if t.optional || t.kind == inferNull {
+		node = parquet.Optional(node)
+	}
+	return node
+}
+
+// group returns the fields of an object in the order they were first seen,
+// as recorded by order. Fields it does not know follow, sorted by name.
+func (t *inferredType) group(order *fieldOrder) orderedGroup {
+	names := make([]string, 0, len(t.fields))
+	for _, name := range order.names() {
+		if _, ok := t.fields[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var rest []string
+	for name := range t.fields {
+		if order.field(name) == nil {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	var group orderedGroup
+	for _, name := range append(names, rest...) {
+		group.add(name, t.fields[name].node(order.field(name)))
+	}
+	return group
+}
+
+// inferSchema returns a schema holding records, JSON objects decoded with
+// UseNumber, with the fields in the order recorded by order. Fields missing
+// from some records are optional.
+func inferSchema(records []map[string]interface{}, order *fieldOrder) *parquet.Schema {
+	root := &inferredType{}
+	for _, record := range records {
+		root.observe(record)
+	}
+	return parquet.NewSchema("schema", root.group(order))
+}
+
+// fieldOrder records the order in which the fields of JSON objects are
+// first seen, at every nesting level, which decoding into maps loses. The
+// elements of arrays share one order. A nil order knows no fields.
+type fieldOrder struct {
+	order  []string
+	fields map[string]*fieldOrder
+	elem   *fieldOrder
+}
+
+// scan records the fields of the JSON value read from dec.
+func (o *fieldOrder) scan(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			name, _ := tok.(string)
+			f := o.fields[name]
+			if f == nil {
+				if o.fields == nil {
+					o.fields = make(map[string]*fieldOrder)
+				}
+				f = &fieldOrder{}
+				o.fields[name] = f
+				o.order = append(o.order, name)
+			}
+			if err := f.scan(dec); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		if o.elem == nil {
+			o.elem = &fieldOrder{}
+		}
+		for dec.More() {
+			if err := o.elem.scan(dec); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	// the closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+func (o *fieldOrder) names() []string {
+	if o == nil {
+		return nil
+	}
+	return o.order
+}
+
+func (o *fieldOrder) field(name string) *fieldOrder {
+	if o == nil {
+		return nil
+	}
+	return o.fields[name]
+}
+
+func (o *fieldOrder) element() *fieldOrder {
+	if o == nil {
+		return nil
+	}
+	return o.elem
+}
@@ -0,0 +1,209 @@
+package parquet
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NaNPolicy decides how NaN and infinite floats, which JSON cannot represent,
+// are written.
+type NaNPolicy int
+
+const (
+	// NaNAsNull writes null (the default).
+	NaNAsNull NaNPolicy = iota
+	// NaNAsString writes the strings "NaN", "Infinity" and "-Infinity".
+	NaNAsString
+)
+
+// ParseNaNPolicy parses "null" or "string".
+func ParseNaNPolicy(s string) (NaNPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "null":
+		return NaNAsNull, nil
+	case "string":
+		return NaNAsString, nil
+	}
+	return NaNAsNull, fmt.Errorf("unknown NaN policy %q, expected null or string", s)
+}
+
+// BinaryEncoding decides how binary values are written in JSON.
+type BinaryEncoding int
+
+const (
+	// BinaryBase64 writes standard base64 (the default).
+	BinaryBase64 BinaryEncoding = iota
+	// BinaryHex writes lowercase hexadecimal.
+	BinaryHex
+	// BinaryUTF8 writes the bytes as text, replacing invalid UTF-8.
+	BinaryUTF8
+)
+
+// ParseBinaryEncoding parses "base64", "hex" or "utf8".
+func ParseBinaryEncoding(s string) (BinaryEncoding, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "base64":
+		return BinaryBase64, nil
+	case "hex":
+		return BinaryHex, nil
+	case "utf8", "utf-8":
+		return BinaryUTF8, nil
+	}
+	return BinaryBase64, fmt.Errorf("unknown binary encoding %q, expected base64, hex or utf8", s)
+}
+
+// jsonEncoder encodes values as JSON. Unlike encoding/json it accepts NaN
+// and infinite floats, can quote 64-bit integers, which JavaScript cannot
+// hold exactly above 2^53, and writes binary values in a chosen encoding.
+type jsonEncoder struct {
+	nan           NaNPolicy
+	int64AsString bool
+	binary        BinaryEncoding
+}
+
+func newJSONEncoder(cfg *outputConfig) *jsonEncoder {
+	return &jsonEncoder{nan: cfg.nan, int64AsString: cfg.int64AsString, binary: cfg.binary}
+}
+
+// encode returns the JSON encoding of v.
+func (e *jsonEncoder) encode(v interface{}) ([]byte, error) {
+	return e.appendValue(nil, v)
+}
+
+func (e *jsonEncoder) appendValue(buf []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case bool:
+		return strconv.AppendBool(buf, v), nil
+	case string:
+		return appendJSONString(buf, v), nil
+	case int8, int16, int32, uint8, uint16, uint32:
+		return append(buf, fmt.Sprint(v)...), nil
+	case int:
+		return e.appendInt(buf, strconv.FormatInt(int64(v), 10)), nil
+	case int64:
+		return e.appendInt(buf, strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return e.appendInt(buf, strconv.FormatUint(v, 10)), nil
+	case float32:
+		return e.appendFloat(buf, float64(v), 32)
+	case float64:
+		return e.appendFloat(buf, v, 64)
+	case []byte:
+		return e.appendBinary(buf, v), nil
+	case OrderedRow:
+		buf = append(buf, '{')
+		for i, f := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, f.Name)
+			buf = append(buf, ':')
+			var err error
+			if buf, err = e.appendValue(buf, f.Value); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, '}'), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf = append(buf, '{')
+		for i, k := range keys {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, k)
+			buf = append(buf, ':')
+			var err error
+			if buf, err = e.appendValue(buf, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, '}'), nil
+	case []interface{}:
+		buf = append(buf, '[')
+		for i, item := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var err error
+			if buf, err = e.appendValue(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+	}
+
+	// fixed-length byte arrays, e.g. raw UUIDs
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b, _ := bytesValue(v)
+		return e.appendBinary(buf, b), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, data...), nil
+}
+
+func (e *jsonEncoder) appendInt(buf []byte, digits string) []byte {
+	if e.int64AsString {
+		return append(append(append(buf, '"'), digits...), '"')
+	}
+	return append(buf, digits...)
+}
+
+func (e *jsonEncoder) appendFloat(buf []byte, f float64, bits int) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		if e.nan == NaNAsNull {
+			return append(buf, "null"...), nil
+		}
+		switch {
+		case math.IsNaN(f):
+			return append(buf, `"NaN"`...), nil
+		case f > 0:
+			return append(buf, `"Infinity"`...), nil
+		}
+		return append(buf, `"-Infinity"`...), nil
+	}
+	// encoding/json picks the shortest form and the exponent notation
+	var data []byte
+	var err error
+	if bits == 32 {
+		data, err = json.Marshal(float32(f))
+	} else {
+		data, err = json.Marshal(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, data...), nil
+}
+
+func (e *jsonEncoder) appendBinary(buf []byte, b []byte) []byte {
+	switch e.binary {
+	case BinaryHex:
+		return append(hex.AppendEncode(append(buf, '"'), b), '"')
+	case BinaryUTF8:
+		return appendJSONString(buf, strings.ToValidUTF8(string(b), "�"))
+	}
+	return append(base64.StdEncoding.AppendEncode(append(buf, '"'), b), '"')
+}
+
+// appendJSONString appends s as a JSON string, escaped as encoding/json does.
+func appendJSONString(buf []byte, s string) []byte {
+	data, _ := json.Marshal(s)
+	return append(buf, data...)
+}
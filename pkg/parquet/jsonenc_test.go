@@ -0,0 +1,83 @@
+package parquet
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestJSONEncoder(t *testing.T) {
+	row := OrderedRow{
+		{"nan", math.NaN()},
+		{"inf", math.Inf(1)},
+		{"ninf", float32(math.Inf(-1))},
+		{"f", 0.1},
+		{"big", int64(9007199254740993)},
+		{"u", uint64(math.MaxUint64)},
+		{"small", int32(-7)},
+		{"bin", []byte("hi\xff")},
+		{"uuid", [2]byte{0xab, 0xcd}},
+		{"nested", []interface{}{map[string]interface{}{"b": math.NaN(), "a": "x"}}},
+	}
+	cases := []struct {
+		name string
+		enc  jsonEncoder
+		want string
+	}{
+		{
+			"defaults",
+			jsonEncoder{},
+			`{"nan":null,"inf":null,"ninf":null,"f":0.1,"big":9007199254740993,"u":18446744073709551615,` +
+				`"small":-7,"bin":"aGn/","uuid":"q80=","nested":[{"a":"x","b":null}]}`,
+		},
+		{
+			"strings",
+			jsonEncoder{nan: NaNAsString, int64AsString: true, binary: BinaryHex},
+			`{"nan":"NaN","inf":"Infinity","ninf":"-Infinity","f":0.1,"big":"9007199254740993","u":"18446744073709551615",` +
+				`"small":-7,"bin":"6869ff","uuid":"abcd","nested":[{"a":"x","b":"NaN"}]}`,
+		},
+		{
+			"utf8",
+			jsonEncoder{binary: BinaryUTF8},
+			`"bin":"hi�"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.enc.encode(row)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if !strings.Contains(string(got), tc.want) {
+				t.Errorf("got  %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONPolicies(t *testing.T) {
+	if p, err := ParseNaNPolicy("String"); err != nil || p != NaNAsString {
+		t.Errorf("ParseNaNPolicy: got %v, %v", p, err)
+	}
+	if _, err := ParseNaNPolicy("zero"); err == nil {
+		t.Error("ParseNaNPolicy: expected an error")
+	}
+	if e, err := ParseBinaryEncoding("utf-8"); err != nil || e != BinaryUTF8 {
+		t.Errorf("ParseBinaryEncoding: got %v, %v", e, err)
+	}
+	if _, err := ParseBinaryEncoding("base32"); err == nil {
+		t.Error("ParseBinaryEncoding: expected an error")
+	}
+}
+
+func TestPrintJSONWithNaN(t *testing.T) {
+	var buf bytes.Buffer
+	rows := []map[string]interface{}{{"x": math.NaN(), "id": int64(1)}}
+	if err := PrintJSON(rows, &buf, false, WithNaNPolicy(NaNAsString), WithInt64AsString()); err != nil {
+		t.Fatalf("PrintJSON: %v", err)
+	}
+	if got, want := buf.String(), `{"id":"1","x":"NaN"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,541 @@
+package parquet
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// Profile describes the values of a Parquet file, as far as NewLikeGenerator
+// needs to make rows of the same shape: the schema, codec and key-value
+// metadata of the file and, for every field of a sample of its rows, the
+// share of nulls, the range and distinct values of leaves and the numbers of
+// items of lists and maps.
+type Profile struct {
+	schema  *parquet.Schema
+	options []parquet.WriterOption
+	// rows is the number of rows sampled
+	rows   int
+	fields []*nodeProfile
+}
+
+// DefaultProfileRows is the number of rows ProfileFile samples.
+const DefaultProfileRows = 10000
+
+// Limits of what a nodeProfile keeps.
+const (
+	// profileSamples is the number of values kept to pick from
+	profileSamples = 10000
+	// profileDistinct is the number of distinct values counted
+	profileDistinct = 10000
+	// likeDictionary is the largest number of distinct values of a leaf
+	// whose values are picked from the ones seen rather than synthesized
+	likeDictionary = 1000
+)
+
+// ProfileFile profiles up to n rows of the Parquet file at path, spread over
+// the whole file, or all of them when n is 0. Sampling is repeatable: the
+// same file gives the same profile. The null counts and min/max statistics
+// of the footer refine those of the sample for top-level columns.
+func ProfileFile(path string, n int) (*Profile, error) {
+	r, err := NewParquetReader(path, WithRawTypes())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	schema := r.pfile.Schema()
+	metadata := r.pfile.Metadata()
+	// a fixed seed keeps profiles, and what is generated from them, repeatable
+	rng := rand.New(rand.NewSource(1))
+	var rows []map[string]interface{}
+	switch total := r.NumRows(); {
+	case total == 0:
+	case n == 0 || int64(n) >= total:
+		if rows, err = r.Head(int(total)); err != nil {
+			return nil, err
+		}
+	default:
+		// Floyd's algorithm draws n distinct indices
+		picked := make(map[int64]bool, n)
+		for j := total - int64(n); j < total; j++ {
+			i := rng.Int63n(j + 1)
+			if picked[i] {
+				i = j
+			}
+			picked[i] = true
+		}
+		picks := make([]int64, 0, n)
+		for i := range picked {
+			picks = append(picks, i)
+		}
+		if rows, err = r.rowsAt(picks); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := profileRows(schema, rows, rng)
+	if err != nil {
+		return nil, err
+	}
+	p.addFooterStats(metadata)
+
+	if len(metadata.RowGroups) > 0 && len(metadata.RowGroups[0].Columns) > 0 {
+		if c := parquet.LookupCompressionCodec(metadata.RowGroups[0].Columns[0].MetaData.Codec); c != nil {
+			p.options = append(p.options, parquet.Compression(c))
+		}
+	}
+	for _, kv := range metadata.KeyValueMetadata {
+		p.options = append(p.options, parquet.KeyValueMetadata(kv.Key, kv.Value))
+	}
+	return p, nil
+}
+
+// profileRows profiles rows of schema, as read with WithRawTypes.
+func profileRows(schema *parquet.Schema, rows []map[string]interface{}, rng *rand.Rand) (*Profile, error) {
+	p := &Profile{schema: schema, rows: len(rows)}
+	for _, field := range schema.Fields() {
+		p.fields = append(p.fields, newNodeProfile(field.Name(), field))
+	}
+	for _, row := range rows {
+		stored, err := storedRow(schema, row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to profile a row: %v", err)
+		}
+		for _, f := range p.fields {
+			f.observe(stored[f.name], rng)
+		}
+	}
+	return p, nil
+}
+
+// Schema returns the schema of the profiled file.
+func (p *Profile) Schema() *parquet.Schema {
+	return p.schema
+}
+
+// addFooterStats widens the ranges and sets the null shares of the top-level
+// leaves from the column chunk statistics of metadata.
+func (p *Profile) addFooterStats(metadata *format.FileMetaData) {
+	byName := make(map[string]*nodeProfile, len(p.fields))
+	for _, f := range p.fields {
+		byName[f.name] = f
+	}
+	for _, rg := range metadata.RowGroups {
+		for i := range rg.Columns {
+			md := &rg.Columns[i].MetaData
+			if len(md.PathInSchema) != 1 {
+				continue
+			}
+			f := byName[md.PathInSchema[0]]
+			if f == nil || !f.node.Leaf() || f.node.Repeated() {
+				continue
+			}
+			st := &md.Statistics
+			minBytes, maxBytes := st.MinValue, st.MaxValue
+			if minBytes == nil && maxBytes == nil && md.Type != format.ByteArray {
+				minBytes, maxBytes = st.Min, st.Max
+			}
+			if minBytes == nil && maxBytes == nil && st.NullCount == 0 {
+				// no statistics
+				continue
+			}
+			f.footerRows += rg.NumRows
+			f.footerNulls += st.NullCount
+			if lt := f.node.Type().LogicalType(); lt != nil && lt.Integer != nil && !lt.Integer.IsSigned {
+				// unsigned order does not match the stored values
+				continue
+			}
+			for _, b := range [][]byte{minBytes, maxBytes} {
+				if v, ok := decodeStatValue(md.Type, b); ok && b != nil {
+					f.widen(v)
+				}
+			}
+		}
+	}
+}
+
+// nodeProfile holds what was seen of the values of a node.
+type nodeProfile struct {
+	name string
+	node parquet.Node
+	// values counts the values seen, nulls included
+	values, nulls int
+	// footerRows and footerNulls count the rows and nulls of the column
+	// chunks with statistics, for top-level leaves
+	footerRows, footerNulls int64
+	// samples keeps non-null values of leaves, and of maps whose keys are
+	// not strings, to pick from
+	samples reservoir
+	// distinct holds up to profileDistinct distinct leaf values
+	distinct map[string]bool
+	// the range of numbers
+	hasRange           bool
+	minInt, maxInt     int64
+	minFloat, maxFloat float64
+	// lengths keeps numbers of items of lists and maps, or lengths of byte
+	// arrays
+	lengths          reservoir
+	fields           []*nodeProfile
+	elem, key, value *nodeProfile
+	// whole is set when values are kept whole in samples
+	whole bool
+}
+
+func newNodeProfile(name string, node parquet.Node) *nodeProfile {
+	p := &nodeProfile{name: name, node: node, distinct: make(map[string]bool)}
+	switch {
+	case node.Repeated():
+		p.elem = newNodeProfile("", parquet.Required(node))
+	case node.Leaf():
+	case isListNode(node):
+		p.elem = newNodeProfile("", listElement(node))
+	case isMapNode(node):
+		key, value := mapKeyValue(node)
+		p.key = newNodeProfile("", key)
+		p.value = newNodeProfile("", value)
+	default:
+		for _, field := range node.Fields() {
+			p.fields = append(p.fields, newNodeProfile(field.Name(), field))
+		}
+	}
+	return p
+}
+
+// observe adds a stored value of the node.
+func (p *nodeProfile) observe(v interface{}, rng *rand.Rand) {
+	p.values++
+	if v == nil {
+		p.nulls++
+		return
+	}
+	switch {
+	case p.elem != nil:
+		items, ok := v.([]interface{})
+		if !ok {
+			p.keepWhole(v, rng)
+			return
+		}
+		p.lengths.add(len(items), rng)
+		for _, item := range items {
+			p.elem.observe(item, rng)
+		}
+	case p.value != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			// keys other than strings: maps are picked whole
+			p.keepWhole(v, rng)
+			return
+		}
+		p.lengths.add(len(m), rng)
+		for k, item := range m {
+			p.key.observe([]byte(k), rng)
+			p.value.observe(item, rng)
+		}
+	case p.fields != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			p.keepWhole(v, rng)
+			return
+		}
+		for _, f := range p.fields {
+			f.observe(m[f.name], rng)
+		}
+	default:
+		p.samples.add(v, rng)
+		b, isBytes := bytesValue(v)
+		if isBytes {
+			p.lengths.add(len(b), rng)
+		}
+		if len(p.distinct) < profileDistinct {
+			if isBytes {
+				p.distinct[string(b)] = true
+			} else {
+				p.distinct[fmt.Sprint(v)] = true
+			}
+		}
+		p.widen(v)
+	}
+}
+
+func (p *nodeProfile) keepWhole(v interface{}, rng *rand.Rand) {
+	p.whole = true
+	p.samples.add(v, rng)
+}
+
+// widen extends the range of numbers to v.
+func (p *nodeProfile) widen(v interface{}) {
+	switch p.node.Type().Kind() {
+	case parquet.Int32, parquet.Int64:
+		n, ok := intValue(v)
+		if !ok {
+			return
+		}
+		if !p.hasRange || n < p.minInt {
+			p.minInt = n
+		}
+		if !p.hasRange || n > p.maxInt {
+			p.maxInt = n
+		}
+	case parquet.Float, parquet.Double:
+		f, ok := floatValue(v)
+		if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+			return
+		}
+		if !p.hasRange || f < p.minFloat {
+			p.minFloat = f
+		}
+		if !p.hasRange || f > p.maxFloat {
+			p.maxFloat = f
+		}
+	default:
+		return
+	}
+	p.hasRange = true
+}
+
+// nullRatio returns the share of nulls, from the footer when it tells.
+func (p *nodeProfile) nullRatio() float64 {
+	switch {
+	case p.footerRows > 0:
+		return float64(p.footerNulls) / float64(p.footerRows)
+	case p.values > 0:
+		return float64(p.nulls) / float64(p.values)
+	}
+	return 0
+}
+
+// reservoir keeps a uniform sample of up to profileSamples of the items
+// added to it.
+type reservoir struct {
+	items []interface{}
+	seen  int
+}
+
+func (s *reservoir) add(v interface{}, rng *rand.Rand) {
+	s.seen++
+	if len(s.items) < profileSamples {
+		s.items = append(s.items, v)
+	} else if i := rng.Intn(s.seen); i < profileSamples {
+		s.items[i] = v
+	}
+}
+
+// NewLikeGenerator returns a generator of rows like those of the profiled
+// file, for a file of n rows drawing from rng. The schema, codec and
+// key-value metadata are the file's. Leaves with few distinct values pick
+// from the values seen, as often as they were seen; others draw numbers
+// within the range seen, and strings and byte arrays of the lengths seen,
+// from a pool sized to keep the share of distinct values. Lists and maps
+// have the numbers of items seen, and every field the share of nulls seen.
+func NewLikeGenerator(p *Profile, n int64, rng *rand.Rand) *Generator {
+	g := &Generator{schema: p.schema, rand: rng, options: p.options}
+	// scale turns the counts of the sample into those of the file
+	scale := 1.0
+	if p.rows > 0 {
+		scale = float64(n) / float64(p.rows)
+	}
+	// every shard salts its pools alike, so that they hold the same values
+	seed := rng.Int63()
+	g.shard = func(first int64) []*fieldGenerator {
+		c := &likeContext{scale: scale, rows: p.rows, first: first, salts: rand.New(rand.NewSource(seed))}
+		var fields []*fieldGenerator
+		for _, f := range p.fields {
+			fields = append(fields, f.generator(c))
+		}
+		return fields
+	}
+	g.fields = g.shard(0)
+	return g
+}
+
+// likeContext holds what the generators of the fields of a profile are built
+// from: the scale of the file to the sample, the number of rows of the
+// sample, the index in the file of the first row to generate, and the source
+// of the salts of the pools of values.
+type likeContext struct {
+	scale float64
+	rows  int
+	first int64
+	salts *rand.Rand
+}
+
+// valuesBefore returns about how many of the values of a node seen n times in
+// the sample come before the first row.
+func (c *likeContext) valuesBefore(n int) uint64 {
+	if c.rows == 0 {
+		return 0
+	}
+	return uint64(float64(c.first) * float64(n) / float64(c.rows))
+}
+
+func (p *nodeProfile) generator(c *likeContext) *fieldGenerator {
+	g := &fieldGenerator{name: p.name}
+	if p.node.Optional() {
+		g.nullRatio = p.nullRatio()
+	}
+	switch {
+	case p.whole && len(p.samples.items) > 0:
+		g.leaf = pickFrom(p.samples.items)
+		return g
+	case p.elem != nil || p.value != nil:
+		for _, n := range p.lengths.items {
+			g.lengths = append(g.lengths, n.(int))
+		}
+		if len(g.lengths) == 0 {
+			g.lengths = []int{0}
+		}
+		if p.elem != nil {
+			g.elem = p.elem.generator(c)
+		} else {
+			g.key = p.key.generator(c)
+			g.key.nullRatio = 0
+			g.value = p.value.generator(c)
+		}
+		return g
+	case p.fields != nil:
+		for _, f := range p.fields {
+			g.fields = append(g.fields, f.generator(c))
+		}
+		return g
+	}
+	g.leaf = p.leafGenerator(c)
+	return g
+}
+
+// leafGenerator returns a function making values like the ones seen of a
+// leaf, or random values of its type when none were seen.
+func (p *nodeProfile) leafGenerator(c *likeContext) func(r *rand.Rand) interface{} {
+	node := parquet.Required(p.node)
+	nonNull := p.values - p.nulls
+	if len(p.samples.items) == 0 {
+		leaf, err := newLeafGenerator(&FieldSpec{}, node)
+		if err != nil {
+			return func(*rand.Rand) interface{} { return nil }
+		}
+		return leaf
+	}
+	distinct := len(p.distinct)
+	if distinct <= likeDictionary && distinct*2 <= nonNull {
+		return pickFrom(p.samples.items)
+	}
+
+	// values are drawn from a pool of about as many distinct values as the
+	// sample had, scaled to the file; each is made from a hash of its index
+	unique := distinct == nonNull
+	pool := uint64(math.Max(1, math.Round(float64(distinct)*c.scale)))
+	salt := c.salts.Uint64()
+	draw := func(r *rand.Rand) uint64 {
+		if unique {
+			return splitmix64(r.Uint64())
+		}
+		return splitmix64(salt + uint64(r.Int63n(int64(pool))))
+	}
+	var lengths []int
+	for _, n := range p.lengths.items {
+		lengths = append(lengths, n.(int))
+	}
+
+	t := node.Type()
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	if lt == nil {
+		lt = &format.LogicalType{}
+	}
+	switch {
+	case (t.Kind() == parquet.Int32 || t.Kind() == parquet.Int64) && p.hasRange:
+		lo, hi := p.minInt, p.maxInt
+		span := uint64(hi-lo) + 1
+		if unique {
+			// unique integers, such as ids, count up through the range
+			step := uint64(1)
+			if rows := uint64(float64(nonNull) * c.scale); rows > 0 && span/rows > 1 {
+				step = span / rows
+			}
+			// a shard counts on from the values of the rows before it
+			next := step * c.valuesBefore(nonNull)
+			return func(*rand.Rand) interface{} {
+				v := next
+				next += step
+				if span != 0 {
+					v %= span
+				}
+				stored, _ := physicalInt(t, lo+int64(v))
+				return stored
+			}
+		}
+		return func(r *rand.Rand) interface{} {
+			v := draw(r)
+			if span != 0 {
+				v %= span
+			}
+			stored, _ := physicalInt(t, lo+int64(v))
+			return stored
+		}
+	case (t.Kind() == parquet.Float || t.Kind() == parquet.Double) && p.hasRange:
+		lo, hi := p.minFloat, p.maxFloat
+		return func(r *rand.Rand) interface{} {
+			v := lo + float64(draw(r)>>11)/(1<<53)*(hi-lo)
+			if t.Kind() == parquet.Float {
+				return float32(v)
+			}
+			return v
+		}
+	case t.Kind() == parquet.ByteArray && lt.Json == nil && lt.Decimal == nil && len(lengths) > 0:
+		text := lt.UTF8 != nil || lt.Enum != nil
+		return func(r *rand.Rand) interface{} {
+			h := draw(r)
+			return hashBytes(h, lengths[h%uint64(len(lengths))], text)
+		}
+	case lt.UUID != nil:
+		return func(r *rand.Rand) interface{} {
+			b := hashBytes(draw(r), 16, false)
+			b[6] = b[6]&0x0f | 0x40
+			b[8] = b[8]&0x3f | 0x80
+			return b
+		}
+	case t.Kind() == parquet.FixedLenByteArray && lt.Decimal == nil && lt.Float16 == nil:
+		return func(r *rand.Rand) interface{} {
+			return hashBytes(draw(r), t.Length(), false)
+		}
+	}
+	// booleans, INT96, JSON, decimals in bytes and the like
+	return pickFrom(p.samples.items)
+}
+
+// pickFrom returns a function picking one of values at random.
+func pickFrom(values []interface{}) func(r *rand.Rand) interface{} {
+	return func(r *rand.Rand) interface{} {
+		return values[r.Intn(len(values))]
+	}
+}
+
+// splitmix64 scrambles x into a well-mixed 64-bit hash.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// hashBytes returns n bytes derived from h, lowercase letters and digits
+// when text is set.
+func hashBytes(h uint64, n int, text bool) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		if i%8 == 0 {
+			h = splitmix64(h)
+		}
+		c := byte(h >> (8 * (i % 8)))
+		if text {
+			c = randomTextLetters[int(c)%len(randomTextLetters)]
+		}
+		b[i] = c
+	}
+	return b
+}
@@ -0,0 +1,159 @@
+package parquet
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestLikeGenerator(t *testing.T) {
+	schema := parquet.NewSchema("schema", parquet.Group{
+		"id":     parquet.Int(64),
+		"status": parquet.Optional(parquet.String()),
+		"score":  parquet.Leaf(parquet.DoubleType),
+		"code":   parquet.Int(32),
+		"name":   parquet.String(),
+		"tags":   parquet.List(parquet.String()),
+		"info":   parquet.Group{"city": parquet.Enum()},
+	})
+	var rows []map[string]interface{}
+	for i := 0; i < 1000; i++ {
+		status := interface{}([]string{"a", "a", "a", "a", "a", "a", "a", "b", "b", "c"}[i%10])
+		if i%20 == 0 {
+			status = nil
+		}
+		tags := []interface{}{}
+		for j := 0; j < i%4; j++ {
+			tags = append(tags, "t")
+		}
+		rows = append(rows, map[string]interface{}{
+			"id":     int64(1000 + i),
+			"status": status,
+			"score":  float64(i%997) / 100,
+			"code":   int32(i % 800 * 1000),
+			"name":   fmt.Sprintf("user%d", 10+i%90),
+			"tags":   tags,
+			"info":   map[string]interface{}{"city": []string{"Paris", "Oslo"}[i%2]},
+		})
+	}
+	rng := rand.New(rand.NewSource(1))
+	p, err := profileRows(schema, rows, rng)
+	if err != nil {
+		t.Fatalf("profileRows: %v", err)
+	}
+
+	const n = 10000
+	g := NewLikeGenerator(p, n, rng)
+	if g.Schema() != schema {
+		t.Errorf("expected the schema of the profile")
+	}
+	statuses := map[string]int{}
+	codes := map[int32]bool{}
+	ids := map[int64]bool{}
+	for i := 0; i < n; i++ {
+		row := g.Row().(map[string]interface{})
+		id := row["id"].(int64)
+		if id < 1000 || id > 1999 {
+			t.Fatalf("id %d out of range", id)
+		}
+		ids[id] = true
+		if s, ok := row["status"].([]byte); ok {
+			statuses[string(s)]++
+		} else if row["status"] != nil {
+			t.Fatalf("unexpected status %v", row["status"])
+		} else {
+			statuses["null"]++
+		}
+		if score := row["score"].(float64); score < 0 || score > 9.96 {
+			t.Fatalf("score %g out of range", score)
+		}
+		codes[row["code"].(int32)] = true
+		if name := row["name"].([]byte); len(name) != 6 {
+			t.Fatalf("name %q of another length than the ones seen", name)
+		}
+		if tags := row["tags"].([]interface{}); len(tags) > 3 {
+			t.Fatalf("%d tags", len(tags))
+		}
+		if city := string(row["info"].(map[string]interface{})["city"].([]byte)); city != "Paris" && city != "Oslo" {
+			t.Fatalf("unexpected city %q", city)
+		}
+	}
+
+	if len(ids) != 1000 {
+		t.Errorf("got %d distinct ids, want the 1000 of the range", len(ids))
+	}
+	if len(statuses) != 4 {
+		t.Errorf("got statuses %v, want a, b, c and nulls", statuses)
+	}
+	for status, want := range map[string]float64{"null": 0.05, "a": 0.665, "b": 0.19, "c": 0.095} {
+		if share := float64(statuses[status]) / n; share < want-0.03 || share > want+0.03 {
+			t.Errorf("%s: got a share of %.3f, want about %.3f", status, share, want)
+		}
+	}
+	// 800 distinct codes in 1000 rows: about 8000 in a pool for 10000 rows
+	if len(codes) < 4000 || len(codes) > 8000 {
+		t.Errorf("got %d distinct codes, want about 5700", len(codes))
+	}
+}
+
+func TestLikeGeneratorWithoutRows(t *testing.T) {
+	schema := parquet.NewSchema("schema", parquet.Group{
+		"id": parquet.Int(32),
+		"at": parquet.Optional(parquet.Timestamp(parquet.Millisecond)),
+		"xs": parquet.List(parquet.Leaf(parquet.DoubleType)),
+	})
+	p, err := profileRows(schema, nil, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("profileRows: %v", err)
+	}
+	row := NewLikeGenerator(p, 10, rand.New(rand.NewSource(1))).Row().(map[string]interface{})
+	if _, ok := row["id"].(int32); !ok {
+		t.Errorf("id: got %T, want a random int32", row["id"])
+	}
+	if xs := row["xs"].([]interface{}); len(xs) != 0 {
+		t.Errorf("xs: got %d items, want none", len(xs))
+	}
+}
+
+func TestLikeGeneratorShards(t *testing.T) {
+	schema := parquet.NewSchema("schema", parquet.Group{
+		"id":   parquet.Int(64),
+		"code": parquet.Int(32),
+	})
+	var rows []map[string]interface{}
+	for i := 0; i < 1000; i++ {
+		rows = append(rows, map[string]interface{}{"id": int64(10 * i), "code": int32(i % 500)})
+	}
+	p, err := profileRows(schema, rows, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("profileRows: %v", err)
+	}
+	g := NewLikeGenerator(p, 1000, rand.New(rand.NewSource(1)))
+
+	// ids count on across shards as they do in one file
+	var ids, sharded []interface{}
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, g.Row().(map[string]interface{})["id"])
+	}
+	for _, s := range []struct{ first, n int64 }{{0, 400}, {400, 600}} {
+		shard := g.Shard(s.first, rand.New(rand.NewSource(s.first)))
+		for i := int64(0); i < s.n; i++ {
+			sharded = append(sharded, shard.Row().(map[string]interface{})["id"])
+		}
+	}
+	if !reflect.DeepEqual(ids, sharded) {
+		t.Errorf("sharded ids differ from those of one file")
+	}
+
+	// shards drawing alike make the same rows
+	a := g.Shard(100, rand.New(rand.NewSource(3)))
+	b := g.Shard(100, rand.New(rand.NewSource(3)))
+	for i := 0; i < 100; i++ {
+		if ra, rb := a.Row(), b.Row(); !reflect.DeepEqual(ra, rb) {
+			t.Fatalf("row %d: %v != %v", i, ra, rb)
+		}
+	}
+}
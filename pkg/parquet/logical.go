@@ -0,0 +1,348 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// julianDayOfUnixEpoch is the Julian day number of 1970-01-01, the origin of
+// the day count stored in INT96 timestamps.
+const julianDayOfUnixEpoch = 2440588
+
+// renderLogicalTypes replaces the physical values of a reconstructed row by
+// the values their logical types describe, in place:
+//
+//   - TIMESTAMP and INT96 become RFC 3339 strings at the precision of their
+//     unit, with a Z suffix only when they are adjusted to UTC
+//   - DATE becomes an ISO 8601 date and TIME a time of day
+//   - DECIMAL becomes an exact decimal string
+//   - UUID becomes its canonical 8-4-4-4-12 form
+//   - unsigned INTEGER types are read as unsigned, FLOAT16 as a float
+//   - STRING, ENUM and JSON byte arrays become strings
+//
+// Values of other types are left as they are.
+func renderLogicalTypes(schema *parquet.Schema, row map[string]interface{}) {
+	renderGroup(schema, row)
+}
+
+func renderGroup(node parquet.Node, m map[string]interface{}) {
+	for _, field := range node.Fields() {
+		if v, ok := m[field.Name()]; ok && v != nil {
+			m[field.Name()] = renderNode(field, v)
+		}
+	}
+}
+
+func renderNode(node parquet.Node, v interface{}) interface{} {
+	if items, ok := v.([]interface{}); ok && node.Repeated() {
+		// repeated fields without a LIST annotation come back as slices
+		for i, item := range items {
+			items[i] = renderValue(node, item)
+		}
+		return items
+	}
+	return renderValue(node, v)
+}
+
+func renderValue(node parquet.Node, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch {
+	case node.Leaf():
+		return renderLeaf(node.Type(), v)
+	case isListNode(node):
+		elem := listElement(node)
+		if items, ok := v.([]interface{}); ok && elem != nil {
+			for i, item := range items {
+				items[i] = renderNode(elem, item)
+			}
+		}
+	case isMapNode(node):
+		_, value := mapKeyValue(node)
+		if m, ok := v.(map[string]interface{}); ok && value != nil {
+			for k, item := range m {
+				if item != nil {
+					m[k] = renderNode(value, item)
+				}
+			}
+		}
+	default:
+		if m, ok := v.(map[string]interface{}); ok {
+			renderGroup(node, m)
+		}
+	}
+	return v
+}
+
+// renderedNode returns a value of node rendered by its logical type like
+// renderNode, but as a copy, and leaves the strings of values that were
+// already rendered as they are.
+func renderedNode(node parquet.Node, v interface{}) interface{} {
+	if items, ok := v.([]interface{}); ok && node.Repeated() {
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = renderedValue(node, item)
+		}
+		return out
+	}
+	return renderedValue(node, v)
+}
+
+func renderedValue(node parquet.Node, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if _, rendered := v.(string); rendered {
+		return v
+	}
+	switch {
+	case node.Leaf():
+		return renderLeaf(node.Type(), v)
+	case isListNode(node):
+		elem := listElement(node)
+		if items, ok := v.([]interface{}); ok && elem != nil {
+			out := make([]interface{}, len(items))
+			for i, item := range items {
+				out[i] = renderedNode(elem, item)
+			}
+			return out
+		}
+	case isMapNode(node):
+		_, value := mapKeyValue(node)
+		if m, ok := v.(map[string]interface{}); ok && value != nil {
+			out := make(map[string]interface{}, len(m))
+			for k, item := range m {
+				out[k] = renderedNode(value, item)
+			}
+			return out
+		}
+	default:
+		if m, ok := v.(map[string]interface{}); ok {
+			out := make(map[string]interface{}, len(m))
+			for k, item := range m {
+				out[k] = item
+			}
+			for _, field := range node.Fields() {
+				if item, ok := m[field.Name()]; ok {
+					out[field.Name()] = renderedNode(field, item)
+				}
+			}
+			return out
+		}
+	}
+	return v
+}
+
+func renderLeaf(t parquet.Type, v interface{}) interface{} {
+	if i96, ok := v.(deprecated.Int96); ok {
+		return formatInt96(i96)
+	}
+
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	if lt == nil {
+		return v
+	}
+
+	switch {
+	case lt.Timestamp != nil:
+		if n, ok := intValue(v); ok {
+			return formatTimestamp(n, lt.Timestamp.Unit, lt.Timestamp.IsAdjustedToUTC)
+		}
+	case lt.Date != nil:
+		if n, ok := intValue(v); ok {
+			return time.Unix(n*86400, 0).UTC().Format("2006-01-02")
+		}
+	case lt.Time != nil:
+		if n, ok := intValue(v); ok {
+			return formatTime(n, lt.Time.Unit, lt.Time.IsAdjustedToUTC)
+		}
+	case lt.Decimal != nil:
+		if unscaled, ok := decimalUnscaled(v); ok {
+			return formatDecimal(unscaled, int(lt.Decimal.Scale))
+		}
+	case lt.UUID != nil:
+		if b, ok := bytesValue(v); ok && len(b) == 16 {
+			h := hex.EncodeToString(b)
+			return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+		}
+	case lt.Integer != nil && !lt.Integer.IsSigned:
+		switch n := v.(type) {
+		case int32:
+			return uint32(n)
+		case int64:
+			return uint64(n)
+		}
+	case lt.Float16 != nil:
+		if b, ok := bytesValue(v); ok && len(b) == 2 {
+			return float16ToFloat32(binary.LittleEndian.Uint16(b))
+		}
+	case lt.UTF8 != nil || lt.Enum != nil || lt.Json != nil:
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+	}
+	return v
+}
+
+// logicalTypeOf maps the legacy converted types of older writers to their
+// logical type equivalent. DECIMAL is omitted: its scale lives in the schema
+// element, and writers that set it also set the logical type.
+func logicalTypeOf(ct *deprecated.ConvertedType) *format.LogicalType {
+	if ct == nil {
+		return nil
+	}
+	switch *ct {
+	case deprecated.UTF8:
+		return &format.LogicalType{UTF8: &format.StringType{}}
+	case deprecated.Enum:
+		return &format.LogicalType{Enum: &format.EnumType{}}
+	case deprecated.Json:
+		return &format.LogicalType{Json: &format.JsonType{}}
+	case deprecated.Date:
+		return &format.LogicalType{Date: &format.DateType{}}
+	case deprecated.TimeMillis:
+		return &format.LogicalType{Time: &format.TimeType{IsAdjustedToUTC: true, Unit: format.TimeUnit{Millis: &format.MilliSeconds{}}}}
+	case deprecated.TimeMicros:
+		return &format.LogicalType{Time: &format.TimeType{IsAdjustedToUTC: true, Unit: format.TimeUnit{Micros: &format.MicroSeconds{}}}}
+	case deprecated.TimestampMillis:
+		return &format.LogicalType{Timestamp: &format.TimestampType{IsAdjustedToUTC: true, Unit: format.TimeUnit{Millis: &format.MilliSeconds{}}}}
+	case deprecated.TimestampMicros:
+		return &format.LogicalType{Timestamp: &format.TimestampType{IsAdjustedToUTC: true, Unit: format.TimeUnit{Micros: &format.MicroSeconds{}}}}
+	case deprecated.Uint8, deprecated.Uint16, deprecated.Uint32, deprecated.Uint64:
+		return &format.LogicalType{Integer: &format.IntType{IsSigned: false}}
+	}
+	return nil
+}
+
+// unitDuration returns the duration of one tick of unit and the layout of
+// its fractional seconds.
+func unitDuration(unit format.TimeUnit) (time.Duration, string) {
+	switch {
+	case unit.Millis != nil:
+		return time.Millisecond, ".999"
+	case unit.Micros != nil:
+		return time.Microsecond, ".999999"
+	}
+	return time.Nanosecond, ".999999999"
+}
+
+func formatTimestamp(n int64, unit format.TimeUnit, utc bool) string {
+	tick, fraction := unitDuration(unit)
+	perSecond := int64(time.Second / tick)
+	sec, frac := n/perSecond, n%perSecond
+	if frac < 0 {
+		sec, frac = sec-1, frac+perSecond
+	}
+	t := time.Unix(sec, frac*int64(tick)).UTC()
+	layout := "2006-01-02T15:04:05" + fraction
+	if utc {
+		layout += "Z07:00"
+	}
+	return t.Format(layout)
+}
+
+func formatTime(n int64, unit format.TimeUnit, utc bool) string {
+	tick, fraction := unitDuration(unit)
+	t := time.Unix(0, 0).UTC().Add(time.Duration(n) * tick)
+	layout := "15:04:05" + fraction
+	if utc {
+		layout += "Z07:00"
+	}
+	return t.Format(layout)
+}
+
+// formatInt96 decodes the legacy Impala/Spark timestamp layout: nanoseconds
+// of the day in the first 8 bytes, then the Julian day.
+func formatInt96(v deprecated.Int96) string {
+	nanos := int64(uint64(v[1])<<32 | uint64(v[0]))
+	days := int64(v[2]) - julianDayOfUnixEpoch
+	t := time.Unix(days*86400, nanos).UTC()
+	return t.Format("2006-01-02T15:04:05.999999999Z07:00")
+}
+
+// decimalUnscaled returns the unscaled integer of a DECIMAL value, stored
+// either as INT32/INT64 or as big-endian two's complement bytes. Strings are
+// refused: they are decimals already rendered, not their bytes.
+func decimalUnscaled(v interface{}) (*big.Int, bool) {
+	if _, ok := v.(string); ok {
+		return nil, false
+	}
+	if n, ok := intValue(v); ok {
+		return big.NewInt(n), true
+	}
+	b, ok := bytesValue(v)
+	if !ok || len(b) == 0 {
+		return nil, false
+	}
+	n := new(big.Int).SetBytes(b)
+	if b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return n, true
+}
+
+func formatDecimal(unscaled *big.Int, scale int) string {
+	if scale <= 0 {
+		return new(big.Int).Mul(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil)).String()
+	}
+	digits := new(big.Int).Abs(unscaled).String()
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	s := digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	if unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// bytesValue returns the bytes of a BYTE_ARRAY or FIXED_LEN_BYTE_ARRAY value.
+func bytesValue(v interface{}) ([]byte, bool) {
+	switch b := v.(type) {
+	case []byte:
+		return b, true
+	case string:
+		return []byte(b), true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return b, true
+	}
+	return nil, false
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+	switch {
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	case exp == 0 && frac == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// subnormal: normalize the fraction
+		e := uint32(127 - 15 + 1)
+		for frac&0x400 == 0 {
+			frac <<= 1
+			e--
+		}
+		return math.Float32frombits(sign | e<<23 | (frac&0x3ff)<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+}
@@ -0,0 +1,203 @@
+package parquet
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// --- formatting helpers ---
+
+func TestFormatDecimal(t *testing.T) {
+	cases := []struct {
+		unscaled string
+		scale    int
+		want     string
+	}{
+		{"1234567", 2, "12345.67"},
+		{"-5", 2, "-0.05"},
+		{"5", 3, "0.005"},
+		{"0", 2, "0.00"},
+		{"42", 0, "42"},
+		{"42", -2, "4200"},
+		{"-12345678901234567890123456789", 10, "-1234567890123456789.0123456789"},
+	}
+	for _, tc := range cases {
+		n, _ := new(big.Int).SetString(tc.unscaled, 10)
+		if got := formatDecimal(n, tc.scale); got != tc.want {
+			t.Errorf("formatDecimal(%s, %d) = %s, want %s", tc.unscaled, tc.scale, got, tc.want)
+		}
+	}
+}
+
+func TestDecimalUnscaled(t *testing.T) {
+	n, ok := decimalUnscaled([]byte{0xff, 0xfb})
+	if !ok || n.Int64() != -5 {
+		t.Errorf("two's complement bytes: got %v", n)
+	}
+	n, ok = decimalUnscaled([]byte{0x00, 0x12, 0xd6, 0x87})
+	if !ok || n.Int64() != 1234567 {
+		t.Errorf("positive bytes: got %v", n)
+	}
+	n, ok = decimalUnscaled(int32(-7))
+	if !ok || n.Int64() != -7 {
+		t.Errorf("int32: got %v", n)
+	}
+	if n, ok = decimalUnscaled("12.34"); ok {
+		t.Errorf("rendered string: got %v", n)
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	millis := format.TimeUnit{Millis: &format.MilliSeconds{}}
+	micros := format.TimeUnit{Micros: &format.MicroSeconds{}}
+	nanos := format.TimeUnit{Nanos: &format.NanoSeconds{}}
+
+	cases := []struct {
+		n    int64
+		unit format.TimeUnit
+		utc  bool
+		want string
+	}{
+		{1704164645123, millis, true, "2024-01-02T03:04:05.123Z"},
+		{1704164645123456, micros, true, "2024-01-02T03:04:05.123456Z"},
+		{1704164645123456789, nanos, true, "2024-01-02T03:04:05.123456789Z"},
+		{1704164645000000, micros, true, "2024-01-02T03:04:05Z"},
+		{1704164645123456, micros, false, "2024-01-02T03:04:05.123456"},
+		{-1500, millis, true, "1969-12-31T23:59:58.5Z"},
+	}
+	for _, tc := range cases {
+		if got := formatTimestamp(tc.n, tc.unit, tc.utc); got != tc.want {
+			t.Errorf("formatTimestamp(%d) = %s, want %s", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestFormatInt96(t *testing.T) {
+	// 2024-01-02T03:04:05.123456Z: Julian day 2460312, 11045123456000ns into the day
+	nanos := uint64(11045123456000)
+	v := deprecated.Int96{uint32(nanos), uint32(nanos >> 32), 2460312}
+	if got := formatInt96(v); got != "2024-01-02T03:04:05.123456Z" {
+		t.Errorf("formatInt96() = %s", got)
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	cases := map[uint16]float32{
+		0x3c00: 1,
+		0xc000: -2,
+		0x3555: 0.33325195,
+		0x0001: 5.9604645e-08,
+		0x0000: 0,
+	}
+	for h, want := range cases {
+		if got := float16ToFloat32(h); got != want {
+			t.Errorf("float16ToFloat32(%#04x) = %v, want %v", h, got, want)
+		}
+	}
+}
+
+// --- rendering ---
+
+func TestLogicalTypes(t *testing.T) {
+	r, err := NewParquetReader(fixture("logical_types.parquet"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+	rows, err := r.Head(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []map[string]interface{}{
+		{
+			"ts_ms":    "2024-01-02T03:04:05.123Z",
+			"ts_us":    "2024-01-02T03:04:05.123456Z",
+			"ts_ns":    "2024-01-02T03:04:05.123456Z",
+			"ts_local": "2024-01-02T03:04:05.123456",
+			"day":      "2024-01-02",
+			"price":    "12345.67",
+			"amount":   "1234567890123456789012345678.0123456789",
+			"uid":      "12345678-1234-5678-1234-567812345678",
+			"counter":  uint32(4000000000),
+		},
+		{
+			"ts_ms":   "1964-01-17T03:04:05.123Z",
+			"day":     "1969-12-31",
+			"price":   "-0.05",
+			"amount":  "-1.0000000000",
+			"uid":     "00000000-0000-0000-0000-000000000000",
+			"counter": uint32(1),
+		},
+	}
+	for i, fields := range want {
+		for name, v := range fields {
+			if rows[i][name] != v {
+				t.Errorf("row %d %s: got %v (%T), want %v", i, name, rows[i][name], rows[i][name], v)
+			}
+		}
+	}
+	if s, _ := rows[0]["time_us"].(string); !strings.HasPrefix(s, "03:04:05.123456") {
+		t.Errorf("time_us: got %v", rows[0]["time_us"])
+	}
+	for name, v := range rows[2] {
+		if name != "id" && v != nil {
+			t.Errorf("row 2 %s: expected null, got %v", name, v)
+		}
+	}
+}
+
+func TestInt96Timestamps(t *testing.T) {
+	r, err := NewParquetReader(fixture("int96.parquet"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+	rows, err := r.Head(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows[0]["ts"] != "2024-01-02T03:04:05.123456Z" {
+		t.Errorf("ts: got %v", rows[0]["ts"])
+	}
+	if rows[1]["ts"] != "1960-06-01T00:00:00Z" {
+		t.Errorf("ts before the epoch: got %v", rows[1]["ts"])
+	}
+}
+
+func TestWithRawTypes(t *testing.T) {
+	r, err := NewParquetReader(fixture("logical_types.parquet"), WithRawTypes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+	rows, err := r.Head(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows[0]["ts_ms"] != int64(1704164645123) {
+		t.Errorf("ts_ms should stay an integer, got %v (%T)", rows[0]["ts_ms"], rows[0]["ts_ms"])
+	}
+	if _, ok := rows[0]["uid"].(string); ok {
+		t.Error("uid should not be rendered as a string")
+	}
+}
+
+func TestFilterOnRenderedValues(t *testing.T) {
+	r, err := NewParquetReader(fixture("logical_types.parquet"), WithFilter("ts_us >= '2000-01-01' AND day = '2024-01-02'"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+	count, err := r.Count()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 matching row, got %d", count)
+	}
+}
@@ -0,0 +1,214 @@
+package parquet
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// markdownWriter writes rows as a GitHub-flavoured Markdown table, with
+// numeric columns aligned right.
+type markdownWriter struct {
+	w         *bufio.Writer
+	flat      *flattener
+	columns   []flatColumn
+	nullValue string
+	started   bool
+}
+
+func newMarkdownWriter(w io.Writer, columns []Column, cfg *outputConfig) *markdownWriter {
+	flat := newFlattener(columns, cfg)
+	return &markdownWriter{
+		w:         bufio.NewWriter(w),
+		flat:      flat,
+		columns:   flat.flatColumns(),
+		nullValue: displayNull(cfg),
+	}
+}
+
+func (w *markdownWriter) writeHeader() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.w.WriteString("|")
+	for _, col := range w.columns {
+		w.w.WriteString(" " + escapeMarkdown(col.name) + " |")
+	}
+	w.w.WriteString("\n|")
+	for _, col := range w.columns {
+		if isNumericNode(col.node) {
+			w.w.WriteString(" ---: |")
+		} else {
+			w.w.WriteString(" --- |")
+		}
+	}
+	w.w.WriteString("\n")
+}
+
+func (w *markdownWriter) WriteRow(row map[string]interface{}) error {
+	w.writeHeader()
+	for _, values := range w.flat.flatten(row) {
+		w.w.WriteString("|")
+		for _, col := range w.columns {
+			text, err := formatCell(values[col.name], w.nullValue)
+			if err != nil {
+				return err
+			}
+			w.w.WriteString(" " + escapeMarkdown(text) + " |")
+		}
+		if _, err := w.w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *markdownWriter) Flush() error {
+	w.writeHeader()
+	return w.w.Flush()
+}
+
+// escapeMarkdown keeps a value inside its table cell.
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>").Replace(s)
+}
+
+// htmlWriter writes rows as a self-contained HTML document holding one
+// table, whose header gives the type of every column.
+type htmlWriter struct {
+	w         *bufio.Writer
+	flat      *flattener
+	columns   []flatColumn
+	nullValue string
+	started   bool
+}
+
+func newHTMLWriter(w io.Writer, columns []Column, cfg *outputConfig) *htmlWriter {
+	flat := newFlattener(columns, cfg)
+	return &htmlWriter{
+		w:         bufio.NewWriter(w),
+		flat:      flat,
+		columns:   flat.flatColumns(),
+		nullValue: displayNull(cfg),
+	}
+}
+
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>pq</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 14px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+th small { font-weight: normal; color: #57606a; }
+td.num { text-align: right; }
+td.null { color: #8c959f; font-style: italic; }
+</style>
+</head>
+<body>
+<table>
+`
+
+func (w *htmlWriter) writeHeader() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.w.WriteString(htmlHead)
+	w.w.WriteString("<thead>\n<tr>")
+	for _, col := range w.columns {
+		w.w.WriteString("<th>" + html.EscapeString(col.name) + "<br><small>" + html.EscapeString(nodeTypeName(col.node)) + "</small></th>")
+	}
+	w.w.WriteString("</tr>\n</thead>\n<tbody>\n")
+}
+
+func (w *htmlWriter) WriteRow(row map[string]interface{}) error {
+	w.writeHeader()
+	for _, values := range w.flat.flatten(row) {
+		w.w.WriteString("<tr>")
+		for _, col := range w.columns {
+			v := values[col.name]
+			text, err := formatCell(v, w.nullValue)
+			if err != nil {
+				return err
+			}
+			switch {
+			case v == nil:
+				w.w.WriteString(`<td class="null">`)
+			case isNumber(v):
+				w.w.WriteString(`<td class="num">`)
+			default:
+				w.w.WriteString("<td>")
+			}
+			w.w.WriteString(html.EscapeString(text) + "</td>")
+		}
+		if _, err := w.w.WriteString("</tr>\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *htmlWriter) Flush() error {
+	w.writeHeader()
+	w.w.WriteString("</tbody>\n</table>\n</body>\n</html>\n")
+	return w.w.Flush()
+}
+
+// displayNull returns the text shown for nulls in formats meant to be read
+// by people.
+func displayNull(cfg *outputConfig) string {
+	if cfg.nullValue != "" {
+		return cfg.nullValue
+	}
+	return "NULL"
+}
+
+// isNumericNode reports whether a column holds integers or floating point
+// numbers, as opposed to values rendered as text such as decimals or dates.
+func isNumericNode(node parquet.Node) bool {
+	if !node.Leaf() || node.Repeated() {
+		return false
+	}
+	lt := node.Type().LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(node.Type().ConvertedType())
+	}
+	if lt != nil && lt.Integer == nil {
+		return false
+	}
+	switch node.Type().Kind() {
+	case parquet.Int32, parquet.Int64, parquet.Float, parquet.Double:
+		return true
+	}
+	return false
+}
+
+// nodeTypeName describes the type of node, e.g. "INT64", "list<STRING>" or
+// "map<STRING, DOUBLE>".
+func nodeTypeName(node parquet.Node) string {
+	switch {
+	case node.Repeated():
+		return "repeated " + nodeTypeName(parquet.Required(node))
+	case isListNode(node):
+		if elem := listElement(node); elem != nil {
+			return "list<" + nodeTypeName(elem) + ">"
+		}
+		return "list"
+	case isMapNode(node):
+		if key, value := mapKeyValue(node); key != nil && value != nil {
+			return "map<" + nodeTypeName(key) + ", " + nodeTypeName(value) + ">"
+		}
+		return "map"
+	case node.Leaf():
+		return node.Type().String()
+	}
+	return "struct"
+}
@@ -0,0 +1,78 @@
+package parquet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestMarkupWriters(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Node: parquet.Int(64)},
+		{Name: "name", Node: parquet.Optional(parquet.String())},
+		{Name: "tags", Node: parquet.List(parquet.String())},
+	}
+	rows := []map[string]interface{}{
+		{"id": int64(1), "name": "a|b <i>", "tags": []interface{}{"x"}},
+		{"id": int64(2), "name": nil, "tags": nil},
+	}
+	write := func(t *testing.T, format string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		w, err := NewRowWriter(&buf, format, columns)
+		if err != nil {
+			t.Fatalf("NewRowWriter: %v", err)
+		}
+		for _, row := range rows {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatalf("WriteRow: %v", err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		return buf.String()
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		want := "" +
+			"| id | name | tags |\n" +
+			"| ---: | --- | --- |\n" +
+			"| 1 | a\\|b <i> | [\"x\"] |\n" +
+			"| 2 | NULL | NULL |\n"
+		if got := write(t, "markdown"); got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		got := write(t, "html")
+		for _, want := range []string{
+			"<!DOCTYPE html>",
+			"<th>id<br><small>",
+			"<th>tags<br><small>list&lt;",
+			`<td class="num">1</td><td>a|b &lt;i&gt;</td><td>[&#34;x&#34;]</td>`,
+			`<td class="null">NULL</td>`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected %q in:\n%s", want, got)
+			}
+		}
+		if !strings.HasSuffix(got, "</html>\n") {
+			t.Errorf("document is not closed:\n%s", got)
+		}
+	})
+
+	t.Run("html without rows", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, _ := NewRowWriter(&buf, "html", columns)
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		if !strings.Contains(buf.String(), "<tbody>\n</tbody>") {
+			t.Errorf("expected an empty table body:\n%s", buf.String())
+		}
+	})
+}
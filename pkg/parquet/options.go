@@ -0,0 +1,145 @@
+package parquet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReaderOption configures optional behaviour of a ParquetReader.
+type ReaderOption func(*readerConfig)
+
+type readerConfig struct {
+	columns  [][]string
+	where    string
+	rawTypes bool
+	onError  ErrorPolicy
+
+	// set by Dataset: a filter already bound to partition values, and a
+	// projection made up of virtual columns only
+	filter         *Filter
+	minimalColumns bool
+
+	spoolMemory int64
+	spoolMax    int64
+	spoolDir    string
+}
+
+func newReaderConfig(opts []ReaderOption) *readerConfig {
+	cfg := &readerConfig{spoolMemory: DefaultSpoolMemoryLimit}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithColumns restricts decoding to the given columns. Paths use dots to
+// address nested fields, e.g. "info.address.city". Selecting a struct, list or
+// map keeps its whole subtree; paths may also continue through list elements
+// and map values ("items.name", "data.score").
+func WithColumns(paths ...string) ReaderOption {
+	return func(c *readerConfig) {
+		for _, p := range paths {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			c.columns = append(c.columns, strings.Split(p, "."))
+		}
+	}
+}
+
+// WithFilter only yields rows matching the given predicate expression (see
+// ParseFilter). Row groups whose footer statistics rule out any match are
+// skipped without being decoded.
+func WithFilter(where string) ReaderOption {
+	return func(c *readerConfig) {
+		c.where = strings.TrimSpace(where)
+	}
+}
+
+// WithRawTypes returns values as stored, without interpreting their logical
+// types: timestamps and dates stay integers, decimals unscaled integers or
+// bytes, UUIDs bytes.
+func WithRawTypes() ReaderOption {
+	return func(c *readerConfig) {
+		c.rawTypes = true
+	}
+}
+
+// ErrorPolicy decides what happens to rows that cannot be reconstructed from
+// their column values.
+type ErrorPolicy int
+
+const (
+	// FailOnError stops reading with a *CorruptRowError (the default).
+	FailOnError ErrorPolicy = iota
+	// SkipOnError drops the row.
+	SkipOnError
+	// NullOnError replaces the row by a row whose top-level fields are null.
+	NullOnError
+)
+
+// ParseErrorPolicy parses "fail", "skip" or "null".
+func ParseErrorPolicy(s string) (ErrorPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "fail":
+		return FailOnError, nil
+	case "skip":
+		return SkipOnError, nil
+	case "null":
+		return NullOnError, nil
+	}
+	return FailOnError, fmt.Errorf("unknown error policy %q, expected fail, skip or null", s)
+}
+
+// WithErrorPolicy sets how unreadable rows are handled. Rows skipped or
+// replaced by nulls are reported by ParquetReader.BadRows.
+func WithErrorPolicy(p ErrorPolicy) ReaderOption {
+	return func(c *readerConfig) {
+		c.onError = p
+	}
+}
+
+// withBoundFilter replaces the WithFilter expression by an already parsed
+// filter; nil disables filtering.
+func withBoundFilter(f *Filter) ReaderOption {
+	return func(c *readerConfig) {
+		c.where = ""
+		c.filter = f
+	}
+}
+
+// withColumnPaths replaces the WithColumns projection. An empty list means
+// that no file column is wanted; a single column is then decoded to keep
+// track of rows.
+func withColumnPaths(paths [][]string) ReaderOption {
+	return func(c *readerConfig) {
+		c.columns = paths
+		c.minimalColumns = len(paths) == 0
+	}
+}
+
+// WithSpoolMemoryLimit sets how many bytes of a non-seekable input are
+// buffered in memory before spilling to a temporary file.
+func WithSpoolMemoryLimit(n int64) ReaderOption {
+	return func(c *readerConfig) {
+		if n >= 0 {
+			c.spoolMemory = n
+		}
+	}
+}
+
+// WithSpoolMaxSize rejects non-seekable inputs larger than n bytes instead of
+// spooling them. Zero means no limit.
+func WithSpoolMaxSize(n int64) ReaderOption {
+	return func(c *readerConfig) {
+		c.spoolMax = n
+	}
+}
+
+// WithSpoolDir sets the directory for spool files (default os.TempDir()).
+func WithSpoolDir(dir string) ReaderOption {
+	return func(c *readerConfig) {
+		c.spoolDir = dir
+	}
+}
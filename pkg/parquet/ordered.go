@@ -0,0 +1,105 @@
+package parquet
+
+import (
+	"sort"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// OrderedRow is a row, or a struct value, whose fields keep the order of the
+// schema. It encodes to a JSON object with the fields in that order, where a
+// map would have them sorted by name.
+type OrderedRow []OrderedField
+
+// OrderedField is a named value of an OrderedRow.
+type OrderedField struct {
+	Name  string
+	Value interface{}
+}
+
+// MarshalJSON encodes the row as a JSON object with its fields in order.
+// NaN and infinite floats become null.
+func (r OrderedRow) MarshalJSON() ([]byte, error) {
+	return (&jsonEncoder{}).encode(r)
+}
+
+// OrderRow returns row with its columns in the order given, and the fields of
+// nested structs in schema order. Map entries are left in a map; keys that
+// are not columns follow the columns, sorted by name.
+func OrderRow(columns []Column, row map[string]interface{}) OrderedRow {
+	ordered := make(OrderedRow, 0, len(row))
+	known := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		known[col.Name] = true
+		if v, ok := row[col.Name]; ok {
+			ordered = append(ordered, OrderedField{col.Name, orderValue(col.Node, v)})
+		}
+	}
+	return append(ordered, extraFields(row, known)...)
+}
+
+// orderValue replaces the structs of a value of node by OrderedRows.
+func orderValue(node parquet.Node, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch {
+	case node.Repeated():
+		return orderItems(parquet.Required(node), v)
+	case node.Leaf():
+		return v
+	case isListNode(node):
+		if elem := listElement(node); elem != nil {
+			return orderItems(elem, v)
+		}
+		return v
+	case isMapNode(node):
+		_, value := mapKeyValue(node)
+		m, ok := v.(map[string]interface{})
+		if !ok || value == nil {
+			return v
+		}
+		out := make(map[string]interface{}, len(m))
+		for k, item := range m {
+			out[k] = orderValue(value, item)
+		}
+		return out
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	ordered := make(OrderedRow, 0, len(m))
+	known := make(map[string]bool, len(m))
+	for _, field := range node.Fields() {
+		known[field.Name()] = true
+		if item, ok := m[field.Name()]; ok {
+			ordered = append(ordered, OrderedField{field.Name(), orderValue(field, item)})
+		}
+	}
+	return append(ordered, extraFields(m, known)...)
+}
+
+func orderItems(elem parquet.Node, v interface{}) interface{} {
+	items, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	out := make([]interface{}, len(items))
+	for i, item := range items {
+		out[i] = orderValue(elem, item)
+	}
+	return out
+}
+
+// extraFields returns the entries of m whose names are not known, sorted.
+func extraFields(m map[string]interface{}, known map[string]bool) []OrderedField {
+	var extra []OrderedField
+	for name, v := range m {
+		if !known[name] {
+			extra = append(extra, OrderedField{name, v})
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Name < extra[j].Name })
+	return extra
+}
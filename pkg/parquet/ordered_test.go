@@ -0,0 +1,118 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestOrderRow(t *testing.T) {
+	info := parquet.Optional(parquet.Group{
+		"zip":  parquet.String(),
+		"city": parquet.String(),
+	})
+	columns := []Column{
+		{Name: "id", Node: parquet.Int(64)},
+		{Name: "name", Node: parquet.String()},
+		{Name: "info", Node: info},
+		{Name: "history", Node: parquet.List(info)},
+		{Name: "attrs", Node: parquet.Map(parquet.String(), info)},
+	}
+	// Group sorts its fields by name: city comes before zip in schema order.
+	// Columns keep the order given and keys that are not columns go last.
+	row := map[string]interface{}{
+		"name":    "a",
+		"id":      int64(1),
+		"extra":   true,
+		"info":    map[string]interface{}{"zip": "75001", "city": "Paris"},
+		"history": []interface{}{map[string]interface{}{"zip": "1", "city": "x"}, nil},
+		"attrs":   map[string]interface{}{"k": map[string]interface{}{"zip": "2", "city": "y"}},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, "json", columns)
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	if err := w.WriteRow(row); err != nil {
+		t.Fatalf("WriteRow: %v", err)
+	}
+	want := `{"id":1,"name":"a","info":{"city":"Paris","zip":"75001"},` +
+		`"history":[{"city":"x","zip":"1"},null],"attrs":{"k":{"city":"y","zip":"2"}},"extra":true}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	buf.Reset()
+	w, _ = NewRowWriter(&buf, "json", columns, WithPretty())
+	w.WriteRow(map[string]interface{}{"name": "b", "id": int64(2)})
+	want = "{\n  \"id\": 2,\n  \"name\": \"b\"\n}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("pretty: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestOrderRowFromFile(t *testing.T) {
+	ds, err := OpenDataset([]string{fixture("flat.parquet")})
+	if err != nil {
+		t.Fatalf("OpenDataset: %v", err)
+	}
+	defer ds.Close()
+	columns, err := ds.Columns()
+	if err != nil {
+		t.Fatalf("Columns: %v", err)
+	}
+	rows, err := ds.Head(1)
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	ordered := OrderRow(columns, rows[0])
+	if len(ordered) != len(columns) {
+		t.Fatalf("got %d fields, want %d", len(ordered), len(columns))
+	}
+	for i, col := range columns {
+		if ordered[i].Name != col.Name {
+			t.Errorf("field %d: got %s, want %s", i, ordered[i].Name, col.Name)
+		}
+	}
+}
+
+func TestReaderPrintJSONOrder(t *testing.T) {
+	r, err := NewParquetReader(fixture("flat.parquet"))
+	if err != nil {
+		t.Fatalf("NewParquetReader: %v", err)
+	}
+	defer r.Close()
+	rows, err := r.Head(1)
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := r.PrintJSON(rows, &buf, false); err != nil {
+		t.Fatalf("PrintJSON: %v", err)
+	}
+
+	// the keys of the object, in the order written
+	dec := json.NewDecoder(&buf)
+	dec.Token()
+	var keys []string
+	for dec.More() {
+		key, _ := dec.Token()
+		keys = append(keys, key.(string))
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+	}
+	columns := r.Columns()
+	if len(keys) != len(columns) {
+		t.Fatalf("got keys %v, want %d columns", keys, len(columns))
+	}
+	for i, col := range columns {
+		if keys[i] != col.Name {
+			t.Errorf("key %d: got %s, want %s", i, keys[i], col.Name)
+		}
+	}
+}
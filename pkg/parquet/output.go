@@ -0,0 +1,269 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Column is a top-level column of the rows returned by a reader or dataset.
+type Column struct {
+	Name string
+	// Node describes the values of the column. Partition columns are
+	// optional INT64 or STRING leaves.
+	Node parquet.Node
+}
+
+// Columns returns the columns of the rows the reader returns: the selected
+// columns in the order they were selected, or every column in file order.
+func (r *ParquetReader) Columns() []Column {
+	if r == nil || r.reader == nil {
+		return nil
+	}
+	schema := r.outputSchema()
+	columns := make([]Column, 0, len(schema.Fields()))
+	for _, field := range schema.Fields() {
+		columns = append(columns, Column{Name: field.Name(), Node: field})
+	}
+	if len(r.selected) > 0 {
+		rank := make(map[string]int)
+		for i, path := range r.selected {
+			if _, ok := rank[path[0]]; !ok {
+				rank[path[0]] = i
+			}
+		}
+		sort.SliceStable(columns, func(i, j int) bool {
+			return rank[columns[i].Name] < rank[columns[j].Name]
+		})
+	}
+	return columns
+}
+
+// outputSchema describes the rows the reader returns, with the columns in
+// file order.
+func (r *ParquetReader) outputSchema() parquet.Node {
+	var schema parquet.Node = r.reader.Schema()
+	if r.outputPaths != nil {
+		if projected, err := projectSchema(r.pfile.Schema(), r.outputPaths); err == nil {
+			schema = projected
+		}
+	}
+	return schema
+}
+
+// RowWriter writes rows in an output format. Flush must be called once all
+// rows are written.
+type RowWriter interface {
+	WriteRow(row map[string]interface{}) error
+	Flush() error
+}
+
+// OutputOption configures a RowWriter.
+type OutputOption func(*outputConfig)
+
+type outputConfig struct {
+	pretty         bool
+	nullValue      string
+	explode        bool
+	explodeColumns []string
+	flatten        bool
+	maxWidth       int
+	wrap           bool
+	dimNulls       bool
+	nan            NaNPolicy
+	int64AsString  bool
+	binary         BinaryEncoding
+	dialect        SQLDialect
+	table          string
+	batchSize      int
+}
+
+// WithPretty indents JSON output over several lines per row.
+func WithPretty() OutputOption {
+	return func(c *outputConfig) {
+		c.pretty = true
+	}
+}
+
+// WithNullValue sets the text written for null values in CSV and TSV output
+// (empty by default) and in tables, Markdown and HTML (NULL by default).
+func WithNullValue(s string) OutputOption {
+	return func(c *outputConfig) {
+		c.nullValue = s
+	}
+}
+
+// WithExplode turns every element of a list, and every entry of a map, into
+// a row of its own in tabular output, instead of writing the whole list or
+// map as JSON in a single cell. It cannot be combined with
+// WithExplodeColumns, which explodes only some lists.
+func WithExplode() OutputOption {
+	return func(c *outputConfig) {
+		c.explode = true
+	}
+}
+
+// WithExplodeColumns writes a record for every element of the list columns
+// at paths (dotted for lists inside structs), repeating the other fields.
+// Empty and null lists give one record with a null element.
+func WithExplodeColumns(paths ...string) OutputOption {
+	return func(c *outputConfig) {
+		c.explodeColumns = append(c.explodeColumns, paths...)
+	}
+}
+
+// WithFlatten turns the fields of nested structs into top-level fields with
+// dotted names ("info.address.city") in JSON output. Tabular formats always
+// flatten structs.
+func WithFlatten() OutputOption {
+	return func(c *outputConfig) {
+		c.flatten = true
+	}
+}
+
+// WithNaNPolicy sets how NaN and infinite floats are written in JSON.
+func WithNaNPolicy(p NaNPolicy) OutputOption {
+	return func(c *outputConfig) {
+		c.nan = p
+	}
+}
+
+// WithInt64AsString writes 64-bit integers as JSON strings, so that
+// JavaScript consumers do not round values above 2^53.
+func WithInt64AsString() OutputOption {
+	return func(c *outputConfig) {
+		c.int64AsString = true
+	}
+}
+
+// WithBinaryEncoding sets how binary values are written in JSON.
+func WithBinaryEncoding(e BinaryEncoding) OutputOption {
+	return func(c *outputConfig) {
+		c.binary = e
+	}
+}
+
+// WithMaxWidth makes tables fit in n columns of text by truncating, or
+// wrapping, the widest values.
+func WithMaxWidth(n int) OutputOption {
+	return func(c *outputConfig) {
+		if n > 0 {
+			c.maxWidth = n
+		}
+	}
+}
+
+// WithWrap wraps values that do not fit in a table cell over several lines
+// instead of truncating them.
+func WithWrap() OutputOption {
+	return func(c *outputConfig) {
+		c.wrap = true
+	}
+}
+
+// WithDimNulls dims the nulls of tables with terminal escapes, to tell them
+// from strings that read like the null text.
+func WithDimNulls() OutputOption {
+	return func(c *outputConfig) {
+		c.dimNulls = true
+	}
+}
+
+// OutputFormats lists the formats NewRowWriter accepts.
+var OutputFormats = []string{"json", "csv", "tsv", "table", "markdown", "html", "arrow", "pgcopy", "pgcopy-binary"}
+
+// NewRowWriter returns a writer for format (see OutputFormats) that writes
+// rows made up of columns to w.
+func NewRowWriter(w io.Writer, format string, columns []Column, opts ...OutputOption) (RowWriter, error) {
+	cfg := &outputConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.explode && len(cfg.explodeColumns) > 0 {
+		return nil, fmt.Errorf("lists are exploded either all or by column, not both")
+	}
+	if err := checkExplodeColumns(columns, cfg.explodeColumns); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(format) {
+	case "", "json":
+		return newJSONWriter(w, columns, cfg), nil
+	case "csv":
+		return newCSVWriter(w, ',', columns, cfg), nil
+	case "tsv":
+		return newCSVWriter(w, '\t', columns, cfg), nil
+	case "table":
+		return newTableWriter(w, columns, cfg), nil
+	case "markdown", "md":
+		return newMarkdownWriter(w, columns, cfg), nil
+	case "html":
+		return newHTMLWriter(w, columns, cfg), nil
+	case "arrow":
+		if cfg.flatten || cfg.explode || len(cfg.explodeColumns) > 0 {
+			return nil, fmt.Errorf("arrow output keeps the schema and cannot be flattened or exploded")
+		}
+		return newArrowWriter(w, columns)
+	case "pgcopy", "pgcopy-binary":
+		if cfg.flatten || cfg.explode || len(cfg.explodeColumns) > 0 {
+			return nil, fmt.Errorf("%s output keeps the schema and cannot be flattened or exploded", format)
+		}
+		return newPGCopyWriter(w, columns, strings.EqualFold(format, "pgcopy-binary")), nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// jsonWriter writes one JSON object per row, with the fields in schema order,
+// or one per record of the row when it is flattened or exploded.
+type jsonWriter struct {
+	w       io.Writer
+	encoder *jsonEncoder
+	pretty  bool
+	columns []Column
+	flat    *flattener
+}
+
+func newJSONWriter(w io.Writer, columns []Column, cfg *outputConfig) *jsonWriter {
+	jw := &jsonWriter{w: w, encoder: newJSONEncoder(cfg), pretty: cfg.pretty, columns: columns}
+	if cfg.flatten || len(cfg.explodeColumns) > 0 {
+		jw.flat = &flattener{columns: columns, explodePaths: pathSet(cfg.explodeColumns), nest: !cfg.flatten}
+	}
+	return jw
+}
+
+func (w *jsonWriter) WriteRow(row map[string]interface{}) error {
+	if w.flat == nil {
+		return w.write(OrderRow(w.columns, row))
+	}
+	for _, record := range w.flat.records(row) {
+		if err := w.write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes a record on one line, or indented over several lines.
+func (w *jsonWriter) write(record OrderedRow) error {
+	data, err := w.encoder.encode(record)
+	if err != nil {
+		return err
+	}
+	if w.pretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	_, err = w.w.Write(append(data, '\n'))
+	return err
+}
+
+func (w *jsonWriter) Flush() error {
+	return nil
+}
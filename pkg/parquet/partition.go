@@ -0,0 +1,98 @@
+package parquet
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hiveDefaultPartition is the directory value Hive and Spark write for null
+// partition values.
+const hiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+// parsePartitions returns the key=value directory segments of path, such as
+// date=2024-01-01 and country=FR for
+//
+//	events/date=2024-01-01/country=FR/part-0.parquet
+//
+// Values are unescaped as written by Hive and Spark; the keys are returned
+// in path order.
+func parsePartitions(path string) ([]string, map[string]string) {
+	var keys []string
+	values := make(map[string]string)
+	dir := filepath.ToSlash(filepath.Dir(path))
+	for _, segment := range strings.Split(dir, "/") {
+		i := strings.IndexByte(segment, '=')
+		if i <= 0 {
+			continue
+		}
+		key, value := segment[:i], segment[i+1:]
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+	return keys, values
+}
+
+// partitionValues types the raw partition values of a dataset: a key whose
+// values all parse as integers becomes an int64 column, any other key a
+// string column. Files without a key, and Hive's default partition, get null.
+func partitionValues(keys []string, raw []map[string]string) []map[string]interface{} {
+	integer := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		integer[key] = true
+		for _, values := range raw {
+			v, ok := values[key]
+			if !ok || v == hiveDefaultPartition {
+				continue
+			}
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				integer[key] = false
+				break
+			}
+		}
+	}
+
+	typed := make([]map[string]interface{}, len(raw))
+	for i, values := range raw {
+		typed[i] = make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			v, ok := values[key]
+			switch {
+			case !ok || v == hiveDefaultPartition:
+				typed[i][key] = nil
+			case integer[key]:
+				n, _ := strconv.ParseInt(v, 10, 64)
+				typed[i][key] = n
+			default:
+				typed[i][key] = v
+			}
+		}
+	}
+	return typed
+}
+
+// partitionText returns the raw partition values of a dataset as written in
+// the paths, which quoted literals of filters compare with: the directory
+// month=03 matches month = '03' and month LIKE '0%' as text, month = 3 as a
+// number, but not month = '3'. Files without a key, and Hive's default
+// partition, get null.
+func partitionText(keys []string, raw []map[string]string) []map[string]interface{} {
+	text := make([]map[string]interface{}, len(raw))
+	for i, values := range raw {
+		text[i] = make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			if v, ok := values[key]; ok && v != hiveDefaultPartition {
+				text[i][key] = v
+			} else {
+				text[i][key] = nil
+			}
+		}
+	}
+	return text
+}
@@ -0,0 +1,233 @@
+package parquet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// partitionedDataset lays out copies of flat.parquet as
+// events/year=.../country=.../part-0.parquet in a temp dir.
+func partitionedDataset(t *testing.T) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "events")
+	for _, dir := range []string{
+		"year=2023/country=FR",
+		"year=2024/country=FR",
+		"year=2024/country=DE",
+		"year=2024/country=" + hiveDefaultPartition,
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+		copyFile(t, fixture("flat.parquet"), filepath.Join(root, dir, "part-0.parquet"))
+	}
+	return root
+}
+
+func TestParsePartitions(t *testing.T) {
+	keys, values := parsePartitions("data/events/date=2024-01-01/country=New%20York/part-0.parquet")
+	if len(keys) != 2 || keys[0] != "date" || keys[1] != "country" {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	if values["date"] != "2024-01-01" {
+		t.Errorf("date: got %q", values["date"])
+	}
+	if values["country"] != "New York" {
+		t.Errorf("country should be unescaped, got %q", values["country"])
+	}
+
+	if keys, _ := parsePartitions("data/=x/part.parquet"); len(keys) != 0 {
+		t.Errorf("segments without a key should be ignored, got %v", keys)
+	}
+}
+
+func TestPartitionValues(t *testing.T) {
+	keys := []string{"year", "country"}
+	raw := []map[string]string{
+		{"year": "2023", "country": "FR"},
+		{"year": hiveDefaultPartition, "country": "10"},
+		{"country": "DE"},
+	}
+	typed := partitionValues(keys, raw)
+	if typed[0]["year"] != int64(2023) {
+		t.Errorf("year should be int64, got %T %v", typed[0]["year"], typed[0]["year"])
+	}
+	if typed[1]["year"] != nil || typed[2]["year"] != nil {
+		t.Error("default and missing partitions should be null")
+	}
+	if typed[1]["country"] != "10" {
+		t.Errorf("country mixes strings and numbers and should stay a string, got %T", typed[1]["country"])
+	}
+
+	text := partitionText(keys, raw)
+	if text[0]["year"] != "2023" || text[1]["year"] != nil || text[2]["year"] != nil {
+		t.Errorf("text should hold the raw values and nulls, got %v", text)
+	}
+}
+
+func TestFilterBind(t *testing.T) {
+	values := map[string]interface{}{"year": int64(2024), "month": int64(3), "country": "FR"}
+	text := map[string]interface{}{"year": "2024", "month": "03", "country": "FR"}
+	cases := []struct {
+		expr     string
+		constant bool
+		value    bool
+	}{
+		{"year = 2024", true, true},
+		{"year = 2023", true, false},
+		{"country IN ('DE', 'FR') AND year >= 2024", true, true},
+		{"country = 'DE' AND age > 3", true, false},
+		{"country = 'FR' OR age > 3", true, true},
+		{"country = 'FR' AND age > 3", false, false},
+		{"NOT country = 'DE'", true, true},
+		{"age > 3", false, false},
+		// quoted literals compare with the directory text
+		{"year = '2024'", true, true},
+		{"month = '03'", true, true},
+		{"month = '3'", true, false},
+		{"month = 3", true, true},
+		{"year LIKE '20%'", true, true},
+		{"month IN ('02', '03')", true, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := ParseFilter(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			bound := f.bind(values, text)
+			value, ok := bound.constant()
+			if ok != tc.constant || value != tc.value {
+				t.Errorf("constant() = %v, %v, want %v, %v", value, ok, tc.value, tc.constant)
+			}
+			if !ok {
+				for _, path := range bound.Columns() {
+					if path[0] == "country" || path[0] == "year" || path[0] == "month" {
+						t.Errorf("bound filter still references %v", path)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPartitionedDataset(t *testing.T) {
+	root := partitionedDataset(t)
+
+	t.Run("virtual columns", func(t *testing.T) {
+		ds, err := OpenDataset([]string{root})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, err := ds.Head(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// files are listed in lexical order: year=2023/country=FR comes first
+		if rows[0]["year"] != int64(2023) || rows[0]["country"] != "FR" {
+			t.Errorf("unexpected partition values: year=%v country=%v", rows[0]["year"], rows[0]["country"])
+		}
+		if rows[0]["id"] != "id_0" {
+			t.Errorf("file columns should be kept, got %v", rows[0])
+		}
+	})
+
+	t.Run("directory pruning", func(t *testing.T) {
+		ds, err := OpenDataset([]string{root}, WithFilter("year = 2024 AND country = 'FR'"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if files := ds.Files(); len(files) != 1 {
+			t.Fatalf("expected 1 file after pruning, got %v", files)
+		}
+		count, err := ds.Count()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 100 {
+			t.Errorf("expected 100 rows, got %d", count)
+		}
+	})
+
+	t.Run("null partition", func(t *testing.T) {
+		ds, err := OpenDataset([]string{root}, WithFilter("country IS NULL"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if files := ds.Files(); len(files) != 1 {
+			t.Fatalf("expected the default partition only, got %v", files)
+		}
+	})
+
+	t.Run("mixed filter", func(t *testing.T) {
+		ds, err := OpenDataset([]string{root}, WithFilter("country = 'DE' AND age = 20"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, err := ds.Head(10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("expected 2 rows, got %d", len(rows))
+		}
+		for _, row := range rows {
+			if row["country"] != "DE" {
+				t.Errorf("unexpected country %v", row["country"])
+			}
+		}
+	})
+
+	t.Run("pruned to nothing", func(t *testing.T) {
+		ds, err := OpenDataset([]string{root}, WithFilter("year > 2030"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, err := ds.Head(5)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 0 {
+			t.Errorf("expected no rows, got %d", len(rows))
+		}
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		ds, err := OpenDataset([]string{root}, WithColumns("country", "id"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, err := ds.Tail(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows[0]) != 2 || rows[0]["id"] != "id_99" {
+			t.Errorf("expected country and id only, got %v", rows[0])
+		}
+		if _, ok := rows[0]["year"]; ok {
+			t.Error("unselected partition column 'year' should not be present")
+		}
+	})
+
+	t.Run("only partition columns", func(t *testing.T) {
+		ds, err := OpenDataset([]string{root}, WithColumns("year"), WithFilter("age = 20"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		n := 0
+		err = ds.StreamAll(func(row map[string]interface{}) error {
+			if len(row) != 1 {
+				t.Errorf("expected year only, got %v", row)
+			}
+			n++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 8 {
+			t.Errorf("expected 8 rows, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,306 @@
+package parquet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// pgCopyWriter writes rows in the text or binary format of PostgreSQL's COPY
+// FROM STDIN. Columns take the types "pq export --dialect postgres" gives
+// them, which the binary format requires the target table to use: nested
+// columns are jsonb, timestamps timestamptz or timestamp, decimals numeric.
+type pgCopyWriter struct {
+	w       *bufio.Writer
+	binary  bool
+	columns []Column
+	// types holds the PostgreSQL type of each column
+	types   []string
+	started bool
+	buf     []byte
+}
+
+func newPGCopyWriter(w io.Writer, columns []Column, binary bool) *pgCopyWriter {
+	types := make([]string, len(columns))
+	for i, col := range columns {
+		types[i] = DialectPostgres.columnType(col.Node)
+	}
+	return &pgCopyWriter{w: bufio.NewWriter(w), binary: binary, columns: columns, types: types}
+}
+
+// pgCopySignature starts the header of the binary format.
+const pgCopySignature = "PGCOPY\n\377\r\n\000"
+
+func (w *pgCopyWriter) writeHeader() {
+	if w.started || !w.binary {
+		return
+	}
+	w.started = true
+	// no flags and no header extension
+	w.w.WriteString(pgCopySignature)
+	w.w.Write(make([]byte, 8))
+}
+
+func (w *pgCopyWriter) WriteRow(row map[string]interface{}) error {
+	w.writeHeader()
+	buf := w.buf[:0]
+	if w.binary {
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(w.columns)))
+	}
+	for i, col := range w.columns {
+		v := row[col.Name]
+		var err error
+		if w.binary {
+			buf, err = appendPGBinary(buf, col.Node, w.types[i], v)
+		} else {
+			if i > 0 {
+				buf = append(buf, '\t')
+			}
+			buf, err = appendPGText(buf, col.Node, w.types[i], v)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", col.Name, err)
+		}
+	}
+	if !w.binary {
+		buf = append(buf, '\n')
+	}
+	w.buf = buf
+	_, err := w.w.Write(buf)
+	return err
+}
+
+func (w *pgCopyWriter) Flush() error {
+	w.writeHeader()
+	if w.binary {
+		// the file trailer: a field count of -1
+		w.w.Write([]byte{0xff, 0xff})
+	}
+	return w.w.Flush()
+}
+
+// pgValue returns a value of node rendered by its logical type, with nested
+// values encoded as JSON.
+func pgValue(node parquet.Node, v interface{}) (interface{}, error) {
+	if !node.Leaf() || node.Repeated() {
+		data, err := sqlJSON.encode(renderedNode(node, v))
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	if _, rendered := v.(string); rendered {
+		return v, nil
+	}
+	return renderLeaf(node.Type(), v), nil
+}
+
+// appendPGText appends a field of the text format: \N for null, otherwise
+// the text form of the value with backslashes, tabs and line breaks escaped.
+func appendPGText(buf []byte, node parquet.Node, typ string, v interface{}) ([]byte, error) {
+	if v == nil {
+		return append(buf, `\N`...), nil
+	}
+	v, err := pgValue(node, v)
+	if err != nil {
+		return nil, err
+	}
+	var s string
+	switch x := v.(type) {
+	case bool:
+		s = map[bool]string{true: "t", false: "f"}[x]
+	case float32:
+		s = pgFloat(float64(x), 32)
+	case float64:
+		s = pgFloat(x, 64)
+	case string:
+		s = x
+		if typ == "BYTEA" {
+			s = `\x` + hex.EncodeToString([]byte(x))
+		}
+	default:
+		if b, ok := bytesValue(v); ok {
+			if typ == "BYTEA" {
+				s = `\x` + hex.EncodeToString(b)
+			} else {
+				s = string(b)
+			}
+		} else {
+			s = fmt.Sprint(v)
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			buf = append(buf, `\\`...)
+		case '\t':
+			buf = append(buf, `\t`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf, nil
+}
+
+// pgFloat spells NaN and infinities as PostgreSQL reads them.
+func pgFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits)
+}
+
+// pgEpoch is the origin of PostgreSQL dates and timestamps.
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// appendPGBinary appends a field of the binary format: its length, or -1 for
+// null, followed by the value as PostgreSQL's binary send functions write it.
+func appendPGBinary(buf []byte, node parquet.Node, typ string, v interface{}) ([]byte, error) {
+	if v == nil {
+		return binary.BigEndian.AppendUint32(buf, math.MaxUint32), nil
+	}
+	v, err := pgValue(node, v)
+	if err != nil {
+		return nil, err
+	}
+	// reserve the length, filled in once the value is written
+	start := len(buf)
+	buf = append(buf, 0, 0, 0, 0)
+
+	switch typ {
+	case "BOOLEAN":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a boolean, got %T", v)
+		}
+		buf = append(buf, map[bool]byte{true: 1, false: 0}[b])
+	case "SMALLINT", "INTEGER", "BIGINT":
+		n, err := toInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case "SMALLINT":
+			buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+		case "INTEGER":
+			buf = binary.BigEndian.AppendUint32(buf, uint32(n))
+		default:
+			buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+		}
+	case "REAL", "DOUBLE PRECISION":
+		f, ok := floatValue(v)
+		if !ok {
+			return nil, fmt.Errorf("expected a float, got %T", v)
+		}
+		if typ == "REAL" {
+			buf = binary.BigEndian.AppendUint32(buf, math.Float32bits(float32(f)))
+		} else {
+			buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(f))
+		}
+	case "DATE":
+		d, err := parseDate(fmt.Sprint(v))
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.BigEndian.AppendUint32(buf, uint32(int32((d.Unix()-pgEpoch.Unix())/86400)))
+	case "TIME":
+		d, err := parseTimeOfDay(fmt.Sprint(v))
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.BigEndian.AppendUint64(buf, uint64(d/time.Microsecond))
+	case "TIMESTAMP", "TIMESTAMPTZ":
+		ts, err := parseTimestamp(fmt.Sprint(v))
+		if err != nil {
+			return nil, err
+		}
+		micros := ticksOf(ts, time.Microsecond) - ticksOf(pgEpoch, time.Microsecond)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(micros))
+	case "UUID":
+		b, err := parseUUID(fmt.Sprint(v))
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	case "JSONB":
+		// jsonb starts with its format version
+		buf = append(append(buf, 1), fmt.Sprint(v)...)
+	case "BYTEA", "TEXT":
+		b, ok := bytesValue(v)
+		if !ok {
+			return nil, fmt.Errorf("expected a string or bytes, got %T", v)
+		}
+		buf = append(buf, b...)
+	default:
+		if !strings.HasPrefix(typ, "NUMERIC") {
+			return nil, fmt.Errorf("unsupported type %s", typ)
+		}
+		if buf, err = appendPGNumeric(buf, fmt.Sprint(v)); err != nil {
+			return nil, err
+		}
+	}
+	binary.BigEndian.PutUint32(buf[start:], uint32(len(buf)-start-4))
+	return buf, nil
+}
+
+// appendPGNumeric appends a decimal string in the binary form of numeric:
+// the number of base-10000 digits, the weight of the first one, the sign and
+// the number of decimal places, followed by the digits.
+func appendPGNumeric(buf []byte, s string) ([]byte, error) {
+	sign := uint16(0)
+	digits := s
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = 0x4000, digits[1:]
+	}
+	integer, fraction, _ := strings.Cut(digits, ".")
+	if integer == "" && fraction == "" || strings.Trim(integer+fraction, "0123456789") != "" {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	dscale := len(fraction)
+	integer = strings.TrimLeft(integer, "0")
+	// align both parts on groups of four digits around the decimal point
+	integer = strings.Repeat("0", (4-len(integer)%4)%4) + integer
+	fraction += strings.Repeat("0", (4-len(fraction)%4)%4)
+
+	var groups []uint16
+	for i := 0; i < len(integer+fraction); i += 4 {
+		n, _ := strconv.Atoi((integer + fraction)[i : i+4])
+		groups = append(groups, uint16(n))
+	}
+	weight := len(integer)/4 - 1
+	for len(groups) > 0 && groups[0] == 0 {
+		groups, weight = groups[1:], weight-1
+	}
+	for len(groups) > 0 && groups[len(groups)-1] == 0 {
+		groups = groups[:len(groups)-1]
+	}
+	if len(groups) == 0 {
+		sign, weight = 0, 0
+	}
+
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(groups)))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(int16(weight)))
+	buf = binary.BigEndian.AppendUint16(buf, sign)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(dscale))
+	for _, g := range groups {
+		buf = binary.BigEndian.AppendUint16(buf, g)
+	}
+	return buf, nil
+}
@@ -0,0 +1,193 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func writePGCopy(t *testing.T, format string, rows []map[string]interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, format, sqlColumns())
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPGCopyText(t *testing.T) {
+	rows := []map[string]interface{}{
+		{
+			"id":    int64(1),
+			"name":  "tab\there\nand \\ back",
+			"score": math.Inf(-1),
+			"ok":    false,
+			"at":    "2024-03-01T12:30:00.5Z",
+			"price": "-12.34",
+			"blob":  []byte{0xde, 0xad},
+			"tags":  []interface{}{"a\tb"},
+		},
+		{"id": int64(2), "name": `\N`},
+	}
+	got := string(writePGCopy(t, "pgcopy", rows))
+	want := "1\ttab\\there\\nand \\\\ back\t-Infinity\tf\t2024-03-01T12:30:00.5Z\t-12.34\t\\\\xdead\t[\"a\\\\tb\"]\n" +
+		"2\t\\\\N\t\\N\t\\N\t\\N\t\\N\t\\N\t\\N\n"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestPGCopyBinary(t *testing.T) {
+	rows := []map[string]interface{}{
+		{
+			"id":    int64(7),
+			"name":  "hé",
+			"score": 0.5,
+			"ok":    true,
+			"at":    "2000-01-01T00:00:01Z",
+			"price": "12.34",
+			"blob":  []byte{1, 2},
+			"tags":  []interface{}{"a"},
+		},
+		{"id": int64(8)},
+	}
+	data := writePGCopy(t, "pgcopy-binary", rows)
+	want := strings.Join([]string{
+		hex.EncodeToString([]byte(pgCopySignature)), "00000000", "00000000",
+		// first row: 8 fields
+		"0008",
+		"00000008", "0000000000000007",
+		"00000003", hex.EncodeToString([]byte("hé")),
+		"00000008", "3fe0000000000000",
+		"00000001", "01",
+		"00000008", "00000000000f4240",
+		// 12.34: 2 digits, weight 0, positive, scale 2, digits 12 and 3400
+		"0000000c", "0002", "0000", "0000", "0002", "000c", "0d48",
+		"00000002", "0102",
+		"00000006", "01", hex.EncodeToString([]byte(`["a"]`)),
+		// second row: all nulls but id
+		"0008", "00000008", "0000000000000008",
+		"ffffffff", "ffffffff", "ffffffff", "ffffffff", "ffffffff", "ffffffff", "ffffffff",
+		"ffff",
+	}, "")
+	if got := hex.EncodeToString(data); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestPGCopyNestedDecimal(t *testing.T) {
+	price := parquet.Decimal(2, 9, parquet.FixedLenByteArrayType(4))
+	cols := []Column{
+		{Name: "prices", Node: parquet.Optional(parquet.List(price))},
+		{Name: "item", Node: parquet.Optional(parquet.Group{"price": price})},
+	}
+	const prices, item = `["12.34"]`, `{"price":"-12.34"}`
+	for _, format := range []string{"pgcopy", "pgcopy-binary"} {
+		rows := []map[string]interface{}{
+			// as stored, with --raw-types
+			{
+				"prices": []interface{}{[]byte{0x00, 0x00, 0x04, 0xd2}},
+				"item":   map[string]interface{}{"price": []byte{0xff, 0xff, 0xfb, 0x2e}},
+			},
+			// already rendered
+			{
+				"prices": []interface{}{"12.34"},
+				"item":   map[string]interface{}{"price": "-12.34"},
+			},
+		}
+		var buf bytes.Buffer
+		w, err := NewRowWriter(&buf, format, cols)
+		if err != nil {
+			t.Fatalf("%s: NewRowWriter: %v", format, err)
+		}
+		for _, row := range rows {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatalf("%s: WriteRow: %v", format, err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("%s: Flush: %v", format, err)
+		}
+
+		var want string
+		if format == "pgcopy" {
+			want = prices + "\t" + item + "\n"
+		} else {
+			// a field count, then each jsonb as its length, version 1 and text
+			want = string([]byte{0, 2, 0, 0, 0, byte(len(prices) + 1), 1}) + prices +
+				string([]byte{0, 0, 0, byte(len(item) + 1), 1}) + item
+		}
+		if got := buf.String(); strings.Count(got, want) != 2 {
+			t.Errorf("%s: expected both rows as %q, got %q", format, want, got)
+		}
+		if rendered := rows[1]["item"].(map[string]interface{})["price"]; rendered != "-12.34" {
+			t.Errorf("%s: input row changed: %v", format, rendered)
+		}
+	}
+}
+
+func TestPGNumeric(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []uint16 // ndigits, weight, sign, dscale, digits...
+	}{
+		{"0", []uint16{0, 0, 0, 0}},
+		{"-0.00", []uint16{0, 0, 0, 2}},
+		{"10000", []uint16{1, 1, 0, 0, 1}},
+		{"-0.0001", []uint16{1, 0xffff, 0x4000, 4, 1}},
+		{"123456789.5", []uint16{4, 2, 0, 1, 1, 2345, 6789, 5000}},
+		{"18446744073709551615", []uint16{5, 4, 0, 0, 1844, 6744, 737, 955, 1615}},
+	}
+	for _, tc := range cases {
+		buf, err := appendPGNumeric(nil, tc.in)
+		if err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		var got []uint16
+		for i := 0; i+1 < len(buf); i += 2 {
+			got = append(got, binary.BigEndian.Uint16(buf[i:]))
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.in, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: got %v, want %v", tc.in, got, tc.want)
+				break
+			}
+		}
+	}
+	if _, err := appendPGNumeric(nil, "1e5"); err == nil {
+		t.Error("1e5: expected an error")
+	}
+}
+
+func TestPGCopyRejectsReshaping(t *testing.T) {
+	for _, format := range []string{"pgcopy", "pgcopy-binary", "arrow"} {
+		for name, opt := range map[string]OutputOption{
+			"flatten":       WithFlatten(),
+			"explode":       WithExplodeColumns("tags"),
+			"lists explode": WithExplode(),
+		} {
+			if _, err := NewRowWriter(&bytes.Buffer{}, format, sqlColumns(), opt); err == nil {
+				t.Errorf("%s with %s: expected an error", format, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package parquet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+)
+
+// projectSchema builds a schema containing only the selected column paths.
+// Passing the result to parquet.NewReader makes it read just the matching
+// column chunks; everything else is never decoded.
+func projectSchema(schema *parquet.Schema, paths [][]string) (*parquet.Schema, error) {
+	root, err := projectGroup(schema, paths, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parquet.NewSchema(schema.Name(), root), nil
+}
+
+func projectNode(node parquet.Node, paths [][]string, prefix []string) (parquet.Node, error) {
+	for _, p := range paths {
+		if len(p) == 0 {
+			return node, nil
+		}
+	}
+	if node.Leaf() {
+		return nil, fmt.Errorf("column %q has no nested fields", strings.Join(prefix, "."))
+	}
+
+	var projected parquet.Node
+	switch {
+	case isListNode(node):
+		elem := listElement(node)
+		if elem == nil {
+			return nil, fmt.Errorf("cannot select fields inside list column %q, select the whole column instead", strings.Join(prefix, "."))
+		}
+		p, err := projectNode(elem, paths, prefix)
+		if err != nil {
+			return nil, err
+		}
+		projected = parquet.List(p)
+	case isMapNode(node):
+		key, value := mapKeyValue(node)
+		if key == nil || value == nil {
+			return nil, fmt.Errorf("cannot select fields inside map column %q, select the whole column instead", strings.Join(prefix, "."))
+		}
+		p, err := projectNode(value, paths, prefix)
+		if err != nil {
+			return nil, err
+		}
+		projected = parquet.Map(key, p)
+	default:
+		g, err := projectGroup(node, paths, prefix)
+		if err != nil {
+			return nil, err
+		}
+		projected = g
+	}
+
+	switch {
+	case node.Optional():
+		return parquet.Optional(projected), nil
+	case node.Repeated():
+		return parquet.Repeated(projected), nil
+	}
+	return projected, nil
+}
+
+// projectGroup builds the group of the fields of node selected by paths,
+// keeping the order of the fields in node.
+func projectGroup(node parquet.Node, paths [][]string, prefix []string) (orderedGroup, error) {
+	children := make(map[string][][]string)
+	for _, p := range paths {
+		if fieldByName(node, p[0]) == nil {
+			path := append(append([]string{}, prefix...), p[0])
+			return nil, fmt.Errorf("column %q not found in schema", strings.Join(path, "."))
+		}
+		children[p[0]] = append(children[p[0]], p[1:])
+	}
+
+	group := make(orderedGroup, 0, len(children))
+	for _, field := range node.Fields() {
+		name := field.Name()
+		if _, ok := children[name]; !ok {
+			continue
+		}
+		path := append(append([]string{}, prefix...), name)
+		child, err := projectNode(field, children[name], path)
+		if err != nil {
+			return nil, err
+		}
+		group.add(name, child)
+	}
+	return group, nil
+}
+
+func fieldByName(node parquet.Node, name string) parquet.Node {
+	for _, f := range node.Fields() {
+		if f.Name() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func isListNode(node parquet.Node) bool {
+	if lt := node.Type().LogicalType(); lt != nil && lt.List != nil {
+		return true
+	}
+	ct := node.Type().ConvertedType()
+	return ct != nil && *ct == deprecated.List
+}
+
+func isMapNode(node parquet.Node) bool {
+	if lt := node.Type().LogicalType(); lt != nil && lt.Map != nil {
+		return true
+	}
+	ct := node.Type().ConvertedType()
+	return ct != nil && (*ct == deprecated.Map || *ct == deprecated.MapKeyValue)
+}
+
+// listElement returns the element node of a standard three-level list, or nil
+// if the list uses a layout parquet.List cannot reproduce.
+func listElement(node parquet.Node) parquet.Node {
+	repeated := fieldByName(node, "list")
+	if repeated == nil || !repeated.Repeated() {
+		return nil
+	}
+	return fieldByName(repeated, "element")
+}
+
+// mapKeyValue returns the key and value nodes of a standard map layout.
+func mapKeyValue(node parquet.Node) (parquet.Node, parquet.Node) {
+	kv := fieldByName(node, "key_value")
+	if kv == nil || !kv.Repeated() {
+		return nil, nil
+	}
+	return fieldByName(kv, "key"), fieldByName(kv, "value")
+}
+
+// resolveColumn trims path to the part that names a column to read. Filters
+// may address map keys ("props.key_0"), so resolution stops at the first
+// list, map or leaf and selects that node whole.
+func resolveColumn(schema *parquet.Schema, path []string) ([]string, error) {
+	var node parquet.Node = schema
+	for i, name := range path {
+		field := fieldByName(node, name)
+		if field == nil {
+			return nil, fmt.Errorf("column %q not found in schema", strings.Join(path[:i+1], "."))
+		}
+		if field.Leaf() || isListNode(field) || isMapNode(field) {
+			return path[:i+1], nil
+		}
+		node = field
+	}
+	return path, nil
+}
+
+// coversPaths reports whether every path in want is inside some selected path.
+func coversPaths(selected, want [][]string) bool {
+	for _, w := range want {
+		covered := false
+		for _, s := range selected {
+			if len(s) <= len(w) && strings.Join(w[:len(s)], ".") == strings.Join(s, ".") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneValue drops everything from a reconstructed value that is not under
+// one of paths, using node to tell structs from maps.
+func pruneValue(node parquet.Node, v interface{}, paths [][]string) interface{} {
+	for _, p := range paths {
+		if len(p) == 0 {
+			return v
+		}
+	}
+	switch {
+	case v == nil || node.Leaf():
+		return v
+	case isListNode(node):
+		list, ok := v.([]interface{})
+		elem := listElement(node)
+		if !ok || elem == nil {
+			return v
+		}
+		out := make([]interface{}, len(list))
+		for i, e := range list {
+			out[i] = pruneValue(elem, e, paths)
+		}
+		return out
+	case isMapNode(node):
+		m, ok := v.(map[string]interface{})
+		_, value := mapKeyValue(node)
+		if !ok || value == nil {
+			return v
+		}
+		out := make(map[string]interface{}, len(m))
+		for k, e := range m {
+			out[k] = pruneValue(value, e, paths)
+		}
+		return out
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	children := make(map[string][][]string)
+	for _, p := range paths {
+		children[p[0]] = append(children[p[0]], p[1:])
+	}
+	out := make(map[string]interface{}, len(children))
+	for name, sub := range children {
+		field := fieldByName(node, name)
+		if field == nil {
+			continue
+		}
+		if e, ok := m[name]; ok {
+			out[name] = pruneValue(field, e, sub)
+		}
+	}
+	return out
+}
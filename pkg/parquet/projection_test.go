@@ -0,0 +1,201 @@
+package parquet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestWithColumns(t *testing.T) {
+	t.Run("flat subset", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("flat.parquet"), WithColumns("id", "age"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Head(3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows[0]) != 2 {
+			t.Errorf("expected 2 columns, got %d: %v", len(rows[0]), rows[0])
+		}
+		if rows[0]["id"] != "id_0" {
+			t.Errorf("id: got %v, want id_0", rows[0]["id"])
+		}
+		if _, ok := rows[0]["name"]; ok {
+			t.Error("unselected column 'name' should not be present")
+		}
+	})
+
+	t.Run("nested struct field", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("nested_struct.parquet"), WithColumns("info.name"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Head(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		info, ok := rows[0]["info"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("info should be map, got %T", rows[0]["info"])
+		}
+		if info["name"] != "user_0" {
+			t.Errorf("info.name: got %v, want user_0", info["name"])
+		}
+		if _, ok := info["address"]; ok {
+			t.Error("unselected field 'info.address' should not be present")
+		}
+		if _, ok := rows[0]["id"]; ok {
+			t.Error("unselected column 'id' should not be present")
+		}
+	})
+
+	t.Run("whole list and map", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("map_simple.parquet"), WithColumns("props"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Head(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := rows[0]["props"]; !ok {
+			t.Error("missing 'props' map field")
+		}
+		if _, ok := rows[0]["metrics"]; ok {
+			t.Error("unselected column 'metrics' should not be present")
+		}
+
+		r2, err := NewParquetReader(fixture("list_primitive.parquet"), WithColumns("tags"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r2.Close()
+		rows, err = r2.Head(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := rows[0]["tags"].([]interface{}); !ok {
+			t.Errorf("tags should be slice, got %T", rows[0]["tags"])
+		}
+	})
+
+	t.Run("field inside list of structs", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("list_struct.parquet"), WithColumns("id", "items.name"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Head(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		items, ok := rows[0]["items"].([]interface{})
+		if !ok || len(items) == 0 {
+			t.Fatalf("items should be non-empty slice, got %v", rows[0]["items"])
+		}
+		item, ok := items[0].(map[string]interface{})
+		if !ok {
+			t.Fatalf("list item should be map, got %T", items[0])
+		}
+		if item["name"] != "item_0" {
+			t.Errorf("items[0].name: got %v, want item_0", item["name"])
+		}
+		if _, ok := item["value"]; ok {
+			t.Error("unselected field 'items.value' should not be present")
+		}
+	})
+
+	t.Run("deeply nested path", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("deeply_nested.parquet"), WithColumns("data.meta.url", "trace_id"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Tail(2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rows[1]["trace_id"] != "trace_19" {
+			t.Errorf("trace_id: got %v, want trace_19", rows[1]["trace_id"])
+		}
+		if _, ok := rows[0]["source"]; ok {
+			t.Error("unselected column 'source' should not be present")
+		}
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("multi_rowgroup.parquet"), WithColumns("value"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		count := 0
+		err = r.StreamAll(func(row map[string]interface{}) error {
+			if len(row) != 1 {
+				t.Fatalf("expected 1 column, got %v", row)
+			}
+			count++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 90 {
+			t.Errorf("expected 90 rows, got %d", count)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := NewParquetReader(fixture("flat.parquet"), WithColumns("nope"))
+		if err == nil {
+			t.Fatal("expected error for unknown column")
+		}
+	})
+
+	t.Run("path through leaf", func(t *testing.T) {
+		_, err := NewParquetReader(fixture("flat.parquet"), WithColumns("id.x"))
+		if err == nil {
+			t.Fatal("expected error for path through a leaf column")
+		}
+	})
+}
+
+func TestProjectSchemaOrder(t *testing.T) {
+	info := orderedGroup{}
+	info.add("zip", parquet.String())
+	info.add("name", parquet.String())
+	info.add("address", parquet.String())
+	root := orderedGroup{}
+	root.add("id", parquet.Int(64))
+	root.add("info", info)
+	schema := parquet.NewSchema("t", root)
+
+	projected, err := projectSchema(schema, [][]string{{"info", "address"}, {"info", "zip"}, {"id"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, f := range projected.Fields() {
+		names = append(names, f.Name())
+	}
+	if got := strings.Join(names, ","); got != "id,info" {
+		t.Errorf("columns: got %s, want id,info", got)
+	}
+	names = nil
+	for _, f := range fieldByName(projected, "info").Fields() {
+		names = append(names, f.Name())
+	}
+	if got := strings.Join(names, ","); got != "zip,address" {
+		t.Errorf("info fields: got %s, want the schema order zip,address", got)
+	}
+
+	if _, err := projectSchema(schema, [][]string{{"info", "city"}}); err == nil || !strings.Contains(err.Error(), `"info.city"`) {
+		t.Errorf("expected a not found error for info.city, got %v", err)
+	}
+}
@@ -1,7 +1,8 @@
 package parquet
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -14,92 +15,239 @@ import (
 
 type ParquetReader struct {
 	reader *parquet.Reader
-	file   *os.File
+	pfile  *parquet.File
+	file   io.Closer
 	rowNum int64
+
+	// rawTypes skips logical type rendering (see renderLogicalTypes)
+	rawTypes bool
+
+	// sourceRows returns rows as stored instead of reconstructing them (see
+	// sourceRow), set by Dataset for its Parquet writer
+	sourceRows bool
+
+	// onError is the policy for unreadable rows, badRows the indices of the
+	// rows it skipped or replaced by nulls
+	onError ErrorPolicy
+	badRows []int64
+
+	// selected are the columns asked for with WithColumns, which set the
+	// order of Columns
+	selected [][]string
+
+	// filter state, only set when the reader was opened WithFilter
+	filter      *Filter
+	filterCols  [][]string
+	keepGroups  []int
+	outputPaths [][]string
 }
 
-func NewParquetReader(filepath string) (*ParquetReader, error) {
+// NewParquetReader opens the Parquet file at filepath. Pipes, FIFOs and other
+// non-regular files are spooled first (see NewParquetReaderFromStream).
+func NewParquetReader(filepath string, opts ...ReaderOption) (*ParquetReader, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
-	header := make([]byte, 4)
-	_, err = file.Read(header)
+	fileInfo, err := file.Stat()
 	if err != nil {
 		file.Close()
-		return nil, fmt.Errorf("failed to read file header: %v", err)
+		return nil, fmt.Errorf("failed to get file info: %v", err)
+	}
+
+	if !fileInfo.Mode().IsRegular() && !fileInfo.IsDir() {
+		defer file.Close()
+		return NewParquetReaderFromStream(file, opts...)
 	}
 
-	_, err = file.Seek(0, 0)
+	pr, err := newParquetReader(file, fileInfo.Size(), newReaderConfig(opts))
 	if err != nil {
 		file.Close()
-		return nil, fmt.Errorf("failed to reset file position: %v", err)
+		return nil, err
 	}
+	pr.file = file
+	return pr, nil
+}
 
-	if string(header) != "PAR1" {
-		file.Close()
-		return nil, fmt.Errorf("invalid file format: the file is not a valid Parquet file")
+// NewParquetReaderAt reads a Parquet file of the given size from r. The
+// caller keeps ownership of r; Close does not close it.
+func NewParquetReaderAt(r io.ReaderAt, size int64, opts ...ReaderOption) (*ParquetReader, error) {
+	return newParquetReader(r, size, newReaderConfig(opts))
+}
+
+// NewParquetReaderFromStream reads a Parquet file from a non-seekable stream
+// such as stdin. Since Parquet keeps its metadata in a footer, the stream is
+// buffered in memory up to the spool memory limit and spilled to a temporary
+// file beyond that; the temporary file is removed on Close.
+func NewParquetReaderFromStream(r io.Reader, opts ...ReaderOption) (*ParquetReader, error) {
+	cfg := newReaderConfig(opts)
+
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			return newParquetReader(f, info.Size(), cfg)
+		}
 	}
 
-	fileInfo, err := file.Stat()
+	data, size, closer, err := spool(r, cfg)
 	if err != nil {
-		file.Close()
-		return nil, fmt.Errorf("failed to get file info: %v", err)
+		return nil, err
+	}
+	pr, err := newParquetReader(data, size, cfg)
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, err
 	}
+	pr.file = closer
+	return pr, nil
+}
 
-	if fileInfo.Size() < 12 {
-		file.Close()
-		return nil, fmt.Errorf("file is too small to be a valid Parquet file, it might be corrupted")
+func newParquetReader(file io.ReaderAt, size int64, cfg *readerConfig) (*ParquetReader, error) {
+	header := make([]byte, 4)
+	_, err := file.ReadAt(header, 0)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("%w: %d bytes is too small to be a Parquet file", ErrTruncated, size)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file header: %v", err)
+	}
+
+	if string(header) != parquetMagic {
+		return nil, fmt.Errorf("%w: missing PAR1 magic bytes at the start of the file", ErrNotParquet)
+	}
+
+	footerStart, err := checkTrailer(file, size)
+	if err != nil {
+		return nil, err
 	}
 
-	var reader *parquet.Reader
-	var rowNum int64
+	pr := &ParquetReader{rawTypes: cfg.rawTypes, onError: cfg.onError}
 
 	err = func() (recErr error) {
 		defer func() {
 			if r := recover(); r != nil {
-				recErr = fmt.Errorf("failed to create Parquet reader: %v", r)
+				recErr = fmt.Errorf("%w: failed to create Parquet reader: %v", ErrCorrupt, r)
 			}
 		}()
-		reader = parquet.NewReader(file)
-		rowNum = reader.NumRows()
+		pfile, openErr := parquet.OpenFile(file, size)
+		if openErr != nil {
+			return fmt.Errorf("%w: failed to create Parquet reader: %v", ErrCorrupt, openErr)
+		}
+		if err := checkColumnChunks(pfile.Metadata(), footerStart); err != nil {
+			return err
+		}
+		pr.pfile = pfile
+
+		columns := cfg.columns
+		pr.selected = cfg.columns
+		if cfg.minimalColumns {
+			if fields := pfile.Schema().Fields(); len(fields) > 0 {
+				columns = [][]string{{fields[0].Name()}}
+			}
+		}
+		filter := cfg.filter
+		if filter == nil && cfg.where != "" {
+			var parseErr error
+			if filter, parseErr = ParseFilter(cfg.where); parseErr != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidFilter, parseErr)
+			}
+		}
+		if filter != nil {
+			footer := make([]byte, size-trailerSize-footerStart)
+			if _, err := file.ReadAt(footer, footerStart); err != nil && err != io.EOF {
+				return fmt.Errorf("failed to read file footer: %v", err)
+			}
+			if err := pr.setupFilter(filter, footerNullCounts(footer)); err != nil {
+				return err
+			}
+			if len(columns) > 0 && !coversPaths(columns, pr.filterCols) {
+				// The filter needs columns the caller did not ask for: read
+				// them too and drop them again once rows are matched.
+				pr.outputPaths = columns
+				columns = append(append([][]string{}, columns...), pr.filterCols...)
+			}
+		}
+
+		var readerOpts []parquet.ReaderOption
+		if len(columns) > 0 {
+			projected, projErr := projectSchema(pfile.Schema(), columns)
+			if projErr != nil {
+				return projErr
+			}
+			readerOpts = append(readerOpts, projected)
+		}
+		pr.reader = parquet.NewReader(pfile, readerOpts...)
+		pr.rowNum = pr.reader.NumRows()
 		return nil
 	}()
 
 	if err != nil {
-		file.Close()
 		return nil, err
 	}
 
-	return &ParquetReader{
-		reader: reader,
-		file:   file,
-		rowNum: rowNum,
-	}, nil
+	return pr, nil
+}
+
+// setupFilter resolves the filter columns and uses the footer statistics to
+// decide which row groups can contain matching rows. hasNullCount tells which
+// column chunks set a null count (see footerNullCounts).
+func (r *ParquetReader) setupFilter(filter *Filter, hasNullCount [][]bool) error {
+	schema := r.pfile.Schema()
+	for _, path := range filter.Columns() {
+		col, err := resolveColumn(schema, path)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		r.filterCols = append(r.filterCols, col)
+	}
+	if err := checkComparisons(schema, filter, r.rawTypes); err != nil {
+		return err
+	}
+
+	metadata := r.pfile.Metadata()
+	for i := range r.pfile.RowGroups() {
+		var counts []bool
+		if i < len(hasNullCount) {
+			counts = hasNullCount[i]
+		}
+		if i < len(metadata.RowGroups) && !filter.root.mayMatch(rowGroupStats(schema, &metadata.RowGroups[i], counts)) {
+			continue
+		}
+		r.keepGroups = append(r.keepGroups, i)
+	}
+	r.filter = filter
+	return nil
 }
 
 func (r *ParquetReader) Head(n int) ([]map[string]interface{}, error) {
 	if r == nil || r.reader == nil {
-		return nil, fmt.Errorf("invalid reader: reader is not initialized properly")
+		return nil, ErrInvalidReader
 	}
 	if r.rowNum == 0 {
-		return nil, fmt.Errorf("file is empty")
+		return nil, ErrEmptyFile
+	}
+	if r.filter != nil {
+		return r.headFiltered(n)
 	}
 	if int64(n) > r.rowNum {
 		n = int(r.rowNum)
 	}
 
 	r.reader.SeekToRow(0)
-	return r.readRows(n)
+	return r.readRows(0, n)
 }
 
 func (r *ParquetReader) Tail(n int) ([]map[string]interface{}, error) {
 	if r == nil || r.reader == nil {
-		return nil, fmt.Errorf("invalid reader: reader is not initialized properly")
+		return nil, ErrInvalidReader
 	}
 	if r.rowNum == 0 {
-		return nil, fmt.Errorf("file is empty")
+		return nil, ErrEmptyFile
+	}
+	if r.filter != nil {
+		return r.tailFiltered(n)
 	}
 	if int64(n) > r.rowNum {
 		n = int(r.rowNum)
@@ -110,17 +258,18 @@ func (r *ParquetReader) Tail(n int) ([]map[string]interface{}, error) {
 		startRow = 0
 	}
 	r.reader.SeekToRow(startRow)
-	return r.readRows(n)
+	return r.readRows(startRow, n)
 }
 
-func (r *ParquetReader) readRows(n int) ([]map[string]interface{}, error) {
+// readRows reads the n rows starting at first, where the reader is positioned.
+func (r *ParquetReader) readRows(first int64, n int) ([]map[string]interface{}, error) {
 	var result []map[string]interface{}
 	var err error
 
 	func() {
 		defer func() {
 			if rec := recover(); rec != nil {
-				err = fmt.Errorf("panic while reading rows: %v", rec)
+				err = fmt.Errorf("%w: panic while reading rows: %v", ErrCorrupt, rec)
 			}
 		}()
 
@@ -133,12 +282,16 @@ func (r *ParquetReader) readRows(n int) ([]map[string]interface{}, error) {
 			return
 		}
 
+		schema := r.reader.Schema()
 		for i := 0; i < count; i++ {
-			row := make(map[string]interface{})
-			reconstructErr := r.reader.Schema().Reconstruct(&row, rowBuf[i])
+			row, reconstructErr := r.reconstruct(schema, rowBuf[i])
 			if reconstructErr != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Skipping row %d due to error: %v\n", i, reconstructErr)
-				continue
+				if row, err = r.badRow(schema, first+int64(i), reconstructErr); err != nil {
+					return
+				}
+				if row == nil {
+					continue
+				}
 			}
 			result = append(result, row)
 		}
@@ -147,59 +300,33 @@ func (r *ParquetReader) readRows(n int) ([]map[string]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	if len(result) == 0 {
-		return nil, fmt.Errorf("no rows could be read from the file")
-	}
 	return result, nil
 }
 
 // StreamAll reads all rows in batches and calls fn for each row.
 // Stops early if fn returns a non-nil error.
 func (r *ParquetReader) StreamAll(fn func(row map[string]interface{}) error) error {
-	if r == nil || r.reader == nil {
-		return fmt.Errorf("invalid reader: reader is not initialized properly")
-	}
-	if r.rowNum == 0 {
-		return nil
-	}
-
-	r.reader.SeekToRow(0)
-	const batchSize = 256
-	rowBuf := make([]parquet.Row, batchSize)
-
-	for {
-		n, readErr := r.reader.ReadRows(rowBuf)
-		if n == 0 && readErr == io.EOF {
-			return nil
-		}
-		if readErr != nil && readErr != io.EOF {
-			return fmt.Errorf("failed to read rows: %v", readErr)
-		}
-
-		for i := 0; i < n; i++ {
-			row := make(map[string]interface{})
-			if err := r.reader.Schema().Reconstruct(&row, rowBuf[i]); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Skipping row due to error: %v\n", err)
-				continue
-			}
-			if err := fn(row); err != nil {
-				return err
-			}
+	for row, err := range r.Rows(context.Background()) {
+		if err != nil {
+			return err
 		}
-
-		if readErr == io.EOF {
-			return nil
+		if err := fn(row); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 // Sample returns n randomly selected rows from the file.
 func (r *ParquetReader) Sample(n int) ([]map[string]interface{}, error) {
 	if r == nil || r.reader == nil {
-		return nil, fmt.Errorf("invalid reader: reader is not initialized properly")
+		return nil, ErrInvalidReader
 	}
 	if r.rowNum == 0 {
-		return nil, fmt.Errorf("file is empty")
+		return nil, ErrEmptyFile
+	}
+	if r.filter != nil {
+		return r.sampleFiltered(n)
 	}
 	if int64(n) >= r.rowNum {
 		return r.Head(int(r.rowNum))
@@ -214,7 +341,13 @@ func (r *ParquetReader) Sample(n int) ([]map[string]interface{}, error) {
 			indices = append(indices, idx)
 		}
 	}
+	return r.rowsAt(indices)
+}
+
+// rowsAt returns the rows at indices, in increasing order.
+func (r *ParquetReader) rowsAt(indices []int64) ([]map[string]interface{}, error) {
 	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	n := len(indices)
 
 	r.reader.SeekToRow(0)
 	const batchSize = 256
@@ -234,10 +367,13 @@ func (r *ParquetReader) Sample(n int) ([]map[string]interface{}, error) {
 
 		for i := 0; i < readN && pick < len(indices); i++ {
 			if cursor+int64(i) == indices[pick] {
-				row := make(map[string]interface{})
-				if err := r.reader.Schema().Reconstruct(&row, rowBuf[i]); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: Skipping row %d due to error: %v\n", cursor+int64(i), err)
-				} else {
+				row, err := r.reconstruct(r.reader.Schema(), rowBuf[i])
+				if err != nil {
+					if row, err = r.badRow(r.reader.Schema(), cursor+int64(i), err); err != nil {
+						return nil, err
+					}
+				}
+				if row != nil {
 					result = append(result, row)
 				}
 				pick++
@@ -249,19 +385,182 @@ func (r *ParquetReader) Sample(n int) ([]map[string]interface{}, error) {
 		}
 	}
 
-	if len(result) == 0 {
-		return nil, fmt.Errorf("no rows could be read from the file")
-	}
 	return result, nil
 }
 
+// NumRows returns the number of rows in the file, ignoring any filter.
+func (r *ParquetReader) NumRows() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.rowNum
+}
+
+// Count returns the number of rows in the file, or the number of matching
+// rows when the reader has a filter.
 func (r *ParquetReader) Count() (int64, error) {
 	if r == nil {
-		return 0, fmt.Errorf("invalid reader: reader is not initialized properly")
+		return 0, ErrInvalidReader
+	}
+	if r.filter != nil {
+		return r.countFiltered()
 	}
 	return r.rowNum, nil
 }
 
+// reconstruct rebuilds a row of schema and renders its logical types.
+func (r *ParquetReader) reconstruct(schema *parquet.Schema, values parquet.Row) (map[string]interface{}, error) {
+	if r.sourceRows {
+		return map[string]interface{}{sourceRowKey: sourceRow(values.Clone())}, nil
+	}
+	row := make(map[string]interface{})
+	if err := schema.Reconstruct(&row, values); err != nil {
+		return nil, err
+	}
+	if !r.rawTypes {
+		renderLogicalTypes(schema, row)
+	}
+	return row, nil
+}
+
+// badRow applies the error policy to the row at index that could not be
+// reconstructed. It returns the row to use in its place, nil to drop it, or
+// the error to stop with.
+func (r *ParquetReader) badRow(schema *parquet.Schema, index int64, err error) (map[string]interface{}, error) {
+	switch r.onError {
+	case SkipOnError:
+		r.badRows = append(r.badRows, index)
+		return nil, nil
+	case NullOnError:
+		r.badRows = append(r.badRows, index)
+		row := make(map[string]interface{})
+		for _, field := range schema.Fields() {
+			row[field.Name()] = nil
+		}
+		return row, nil
+	}
+	return nil, &CorruptRowError{Row: index, Err: err}
+}
+
+// BadRows returns the indices of the rows that could not be read and were
+// skipped or replaced by nulls, following the error policy.
+func (r *ParquetReader) BadRows() []int64 {
+	if r == nil {
+		return nil
+	}
+	return r.badRows
+}
+
+// errStopScan ends a row-group scan early without reporting an error.
+var errStopScan = errors.New("stop scan")
+
+// filteredRowGroups returns the row groups that may contain matching rows,
+// converted to schema so that only its columns are decoded.
+func (r *ParquetReader) filteredRowGroups(schema *parquet.Schema) ([]parquet.RowGroup, error) {
+	fileSchema := r.pfile.Schema()
+	all := r.pfile.RowGroups()
+	groups := make([]parquet.RowGroup, 0, len(r.keepGroups))
+	var conv parquet.Conversion
+	if schema != fileSchema {
+		var err error
+		if conv, err = parquet.Convert(schema, fileSchema); err != nil {
+			return nil, fmt.Errorf("failed to project columns: %v", err)
+		}
+	}
+	for _, i := range r.keepGroups {
+		rg := all[i]
+		if conv != nil {
+			rg = parquet.ConvertRowGroup(rg, conv)
+		}
+		groups = append(groups, rg)
+	}
+	return groups, nil
+}
+
+func (r *ParquetReader) headFiltered(n int) ([]map[string]interface{}, error) {
+	result := make([]map[string]interface{}, 0)
+	if n <= 0 {
+		return result, nil
+	}
+	err := r.StreamAll(func(row map[string]interface{}) error {
+		result = append(result, row)
+		if len(result) >= n {
+			return errStopScan
+		}
+		return nil
+	})
+	if err != nil && err != errStopScan {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *ParquetReader) tailFiltered(n int) ([]map[string]interface{}, error) {
+	if n <= 0 {
+		return []map[string]interface{}{}, nil
+	}
+	ring := make([]map[string]interface{}, 0, n)
+	next := 0
+	err := r.StreamAll(func(row map[string]interface{}) error {
+		if len(ring) < n {
+			ring = append(ring, row)
+		} else {
+			ring[next] = row
+			next = (next + 1) % n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(ring[next:], ring[:next]...), nil
+}
+
+// sampleFiltered picks n matching rows by reservoir sampling, returned in
+// file order.
+func (r *ParquetReader) sampleFiltered(n int) ([]map[string]interface{}, error) {
+	type picked struct {
+		index int64
+		row   map[string]interface{}
+	}
+	reservoir := make([]picked, 0, n)
+	seen := int64(0)
+	err := r.StreamAll(func(row map[string]interface{}) error {
+		if len(reservoir) < n {
+			reservoir = append(reservoir, picked{seen, row})
+		} else if j := rand.Int63n(seen + 1); j < int64(n) {
+			reservoir[j] = picked{seen, row}
+		}
+		seen++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(reservoir, func(i, j int) bool { return reservoir[i].index < reservoir[j].index })
+	result := make([]map[string]interface{}, len(reservoir))
+	for i, p := range reservoir {
+		result[i] = p.row
+	}
+	return result, nil
+}
+
+// countFiltered counts matching rows, decoding only the filter columns.
+func (r *ParquetReader) countFiltered() (int64, error) {
+	schema, err := projectSchema(r.pfile.Schema(), r.filterCols)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	for _, err := range r.rows(context.Background(), schema, defaultRowsConfig()) {
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
+
 func (r *ParquetReader) Close() error {
 	if r == nil {
 		return nil
@@ -280,7 +579,7 @@ func (r *ParquetReader) Close() error {
 
 func (r *ParquetReader) GetSchema() (string, error) {
 	if r == nil || r.reader == nil {
-		return "", fmt.Errorf("invalid reader: reader is not initialized properly")
+		return "", ErrInvalidReader
 	}
 
 	var schemaStr string
@@ -296,7 +595,7 @@ func (r *ParquetReader) GetSchema() (string, error) {
 		if schema != nil {
 			schemaStr = schema.String()
 		} else {
-			err = fmt.Errorf("failed to get schema: schema is nil")
+			err = fmt.Errorf("failed to get schema: %w", ErrNoSchema)
 		}
 	}()
 
@@ -312,13 +611,31 @@ func (r *ParquetReader) GetSchema() (string, error) {
 	return result, nil
 }
 
-func PrintJSON(data []map[string]interface{}, w io.Writer, pretty bool) error {
-	encoder := json.NewEncoder(w)
+// PrintJSON writes rows as JSON, one object per line unless pretty. Options
+// such as WithNaNPolicy, WithInt64AsString and WithBinaryEncoding set how
+// values JSON cannot hold as such are written. The rows carry no schema, so
+// their keys are sorted by name: ParquetReader.PrintJSON keeps the order of
+// the file.
+func PrintJSON(data []map[string]interface{}, w io.Writer, pretty bool, opts ...OutputOption) error {
+	return printJSON(data, nil, w, pretty, opts)
+}
+
+// PrintJSON writes rows of r as JSON like the function PrintJSON, with the
+// columns and the fields of nested structs in the order of the schema.
+func (r *ParquetReader) PrintJSON(data []map[string]interface{}, w io.Writer, pretty bool, opts ...OutputOption) error {
+	return printJSON(data, r.Columns(), w, pretty, opts)
+}
+
+func printJSON(data []map[string]interface{}, columns []Column, w io.Writer, pretty bool, opts []OutputOption) error {
 	if pretty {
-		encoder.SetIndent("", "  ")
+		opts = append(opts, WithPretty())
+	}
+	writer, err := NewRowWriter(w, "json", columns, opts...)
+	if err != nil {
+		return err
 	}
 	for _, row := range data {
-		if err := encoder.Encode(row); err != nil {
+		if err := writer.WriteRow(row); err != nil {
 			if pathErr, ok := err.(*os.PathError); ok && (pathErr.Err == syscall.EPIPE || pathErr.Err.Error() == "broken pipe") {
 				return nil
 			}
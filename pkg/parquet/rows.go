@@ -0,0 +1,219 @@
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Row is a reconstructed row: column names mapped to values, with structs
+// and maps as nested maps and lists as slices.
+type Row = map[string]interface{}
+
+// RowsOption configures an iteration started with ParquetReader.Rows.
+type RowsOption func(*rowsConfig)
+
+type rowsConfig struct {
+	offset    int64
+	limit     int64
+	batchSize int
+}
+
+// WithOffset skips the first n rows, or the first n matching rows when the
+// reader has a filter.
+func WithOffset(n int64) RowsOption {
+	return func(c *rowsConfig) {
+		if n > 0 {
+			c.offset = n
+		}
+	}
+}
+
+// WithLimit stops the iteration after n rows.
+func WithLimit(n int64) RowsOption {
+	return func(c *rowsConfig) {
+		if n >= 0 {
+			c.limit = n
+		}
+	}
+}
+
+// WithBatchSize sets how many rows are decoded at a time (default 256).
+func WithBatchSize(n int) RowsOption {
+	return func(c *rowsConfig) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// rowSpan is a run of consecutive rows of the file, starting at first.
+type rowSpan struct {
+	first int64
+	open  func() (parquet.Rows, error)
+}
+
+// Rows returns an iterator over the rows of the file, or over the matching
+// rows when the reader has a filter:
+//
+//	for row, err := range r.Rows(ctx, parquet.WithLimit(100)) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Rows that cannot be reconstructed are handled by the reader's error
+// policy (see WithErrorPolicy): by default they yield a *CorruptRowError.
+// Errors, including the cancellation of ctx, are yielded once and end the
+// iteration. The reader must not be used for anything else until the loop is
+// done.
+func (r *ParquetReader) Rows(ctx context.Context, opts ...RowsOption) iter.Seq2[Row, error] {
+	cfg := defaultRowsConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if r == nil || r.reader == nil {
+		return func(yield func(Row, error) bool) {
+			yield(nil, ErrInvalidReader)
+		}
+	}
+	return r.rows(ctx, r.reader.Schema(), cfg)
+}
+
+func defaultRowsConfig() rowsConfig {
+	return rowsConfig{limit: -1, batchSize: 256}
+}
+
+// rows iterates over the rows of the file reconstructed with schema, which
+// must be the reader's schema or a projection of it.
+func (r *ParquetReader) rows(ctx context.Context, schema *parquet.Schema, cfg rowsConfig) iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		if cfg.limit == 0 || r.rowNum == 0 {
+			return
+		}
+
+		spans, err := r.rowSpans(schema, cfg.offset)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		skip := int64(0)
+		if r.filter != nil {
+			skip = cfg.offset
+		}
+		emitted := int64(0)
+		rowBuf := make([]parquet.Row, cfg.batchSize)
+
+		for _, span := range spans {
+			done, err := func() (bool, error) {
+				rows, err := span.open()
+				if err != nil {
+					return false, err
+				}
+				defer rows.Close()
+
+				index := span.first
+				for {
+					if err := ctx.Err(); err != nil {
+						return false, err
+					}
+					n, readErr := rows.ReadRows(rowBuf)
+					if readErr != nil && readErr != io.EOF {
+						return false, fmt.Errorf("failed to read rows: %v", readErr)
+					}
+					for i := 0; i < n; i++ {
+						if err := ctx.Err(); err != nil {
+							return false, err
+						}
+						row, err := r.reconstruct(schema, rowBuf[i])
+						if err != nil {
+							if row, err = r.badRow(schema, index+int64(i), err); err != nil {
+								return false, err
+							}
+							if row == nil {
+								continue
+							}
+						}
+						if r.filter != nil {
+							if !r.filter.Match(row) {
+								continue
+							}
+							if r.outputPaths != nil {
+								row, _ = pruneValue(schema, row, r.outputPaths).(map[string]interface{})
+							}
+							if skip > 0 {
+								skip--
+								continue
+							}
+						}
+						if !yield(row, nil) {
+							return true, nil
+						}
+						emitted++
+						if cfg.limit > 0 && emitted >= cfg.limit {
+							return true, nil
+						}
+					}
+					index += int64(n)
+					if readErr == io.EOF || n == 0 {
+						return false, nil
+					}
+				}
+			}()
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if done {
+				return
+			}
+		}
+	}
+}
+
+// rowSpans lists the rows to read: the whole file from offset on, or the row
+// groups that may contain matching rows when the reader has a filter.
+func (r *ParquetReader) rowSpans(schema *parquet.Schema, offset int64) ([]rowSpan, error) {
+	if r.filter == nil {
+		if offset >= r.rowNum {
+			return nil, nil
+		}
+		return []rowSpan{{first: offset, open: func() (parquet.Rows, error) {
+			if err := r.reader.SeekToRow(offset); err != nil {
+				return nil, fmt.Errorf("failed to seek to row %d: %v", offset, err)
+			}
+			return nopCloseRows{r.reader}, nil
+		}}}, nil
+	}
+
+	groups, err := r.filteredRowGroups(schema)
+	if err != nil {
+		return nil, err
+	}
+	starts := make([]int64, 0, len(r.pfile.RowGroups()))
+	first := int64(0)
+	for _, rg := range r.pfile.RowGroups() {
+		starts = append(starts, first)
+		first += rg.NumRows()
+	}
+	spans := make([]rowSpan, len(groups))
+	for i, rg := range groups {
+		spans[i] = rowSpan{first: starts[r.keepGroups[i]], open: func() (parquet.Rows, error) {
+			return rg.Rows(), nil
+		}}
+	}
+	return spans, nil
+}
+
+// nopCloseRows reads through the reader without closing it at the end of a
+// span.
+type nopCloseRows struct {
+	*parquet.Reader
+}
+
+func (nopCloseRows) Close() error { return nil }
@@ -0,0 +1,117 @@
+package parquet
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	t.Run("all rows", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("multi_rowgroup.parquet"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		n := 0
+		for row, err := range r.Rows(context.Background()) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if row["value"] != int64(n) {
+				t.Fatalf("row %d: got value %v", n, row["value"])
+			}
+			n++
+		}
+		if n != 90 {
+			t.Errorf("expected 90 rows, got %d", n)
+		}
+	})
+
+	t.Run("offset and limit", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("flat.parquet"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		var ids []interface{}
+		for row, err := range r.Rows(context.Background(), WithOffset(10), WithLimit(3), WithBatchSize(2)) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids = append(ids, row["id"])
+		}
+		if len(ids) != 3 || ids[0] != "id_10" || ids[2] != "id_12" {
+			t.Errorf("expected id_10..id_12, got %v", ids)
+		}
+	})
+
+	t.Run("filtered with offset", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("multi_rowgroup.parquet"), WithFilter("value >= 25 AND value < 65"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		var values []interface{}
+		for row, err := range r.Rows(context.Background(), WithOffset(30)) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			values = append(values, row["value"])
+		}
+		if len(values) != 10 || values[0] != int64(55) {
+			t.Errorf("expected values 55..64, got %v", values)
+		}
+	})
+
+	t.Run("break stops the iteration", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("flat.parquet"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		n := 0
+		for range r.Rows(context.Background()) {
+			n++
+			if n == 5 {
+				break
+			}
+		}
+		if n != 5 {
+			t.Errorf("expected 5 rows, got %d", n)
+		}
+		// the reader is usable again afterwards
+		rows, err := r.Head(1)
+		if err != nil || rows[0]["id"] != "id_0" {
+			t.Errorf("Head after break: %v, %v", rows, err)
+		}
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		r, err := NewParquetReader(fixture("large.parquet"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		n := 0
+		var last error
+		for _, err := range r.Rows(ctx) {
+			if err != nil {
+				last = err
+				continue
+			}
+			n++
+			if n == 10 {
+				cancel()
+			}
+		}
+		if !errors.Is(last, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", last)
+		}
+		if n != 10 {
+			t.Errorf("expected the iteration to stop right after cancel, got %d rows", n)
+		}
+	})
+}
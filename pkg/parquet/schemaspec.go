@@ -0,0 +1,299 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaSpec describes a Parquet schema in JSON or YAML:
+//
+//	{"fields": [
+//	  {"name": "id", "type": "int64"},
+//	  {"name": "at", "type": "timestamp[ms]", "optional": true},
+//	  {"name": "price", "type": "decimal(10,2)"},
+//	  {"name": "tags", "type": "list", "element": {"type": "string"}},
+//	  {"name": "attrs", "type": "map", "key": {"type": "string"}, "value": {"type": "double"}},
+//	  {"name": "info", "type": "struct", "fields": [{"name": "city", "type": "string"}]}
+//	]}
+//
+// A bare array of fields is accepted too. See ParseTypeName for the names of
+// leaf types, and NewGenerator for the settings of generated values.
+type SchemaSpec struct {
+	Name   string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Fields []FieldSpec `json:"fields" yaml:"fields"`
+}
+
+// FieldSpec describes a field of a SchemaSpec, or the element, key or value
+// of a list or map.
+type FieldSpec struct {
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+	Type     string `json:"type" yaml:"type"`
+	Optional bool   `json:"optional,omitempty" yaml:"optional,omitempty"`
+	// Fields are the fields of a struct.
+	Fields []FieldSpec `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Element describes the elements of a list.
+	Element *FieldSpec `json:"element,omitempty" yaml:"element,omitempty"`
+	// Key and Value describe the entries of a map.
+	Key   *FieldSpec `json:"key,omitempty" yaml:"key,omitempty"`
+	Value *FieldSpec `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Min and Max bound generated numbers, decimals, dates, times and
+	// timestamps.
+	Min *SpecValue `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *SpecValue `json:"max,omitempty" yaml:"max,omitempty"`
+	// Values are picked from instead of generating random values.
+	Values []SpecValue `json:"values,omitempty" yaml:"values,omitempty"`
+	// NullRatio is the share of nulls generated for an optional field.
+	NullRatio *float64 `json:"null_ratio,omitempty" yaml:"null_ratio,omitempty"`
+	// MinLength and MaxLength bound the length of generated strings and byte
+	// arrays, and the number of items of lists and maps.
+	MinLength *int `json:"min_length,omitempty" yaml:"min_length,omitempty"`
+	MaxLength *int `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+}
+
+// SpecValue is a value of a FieldSpec, such as a bound or an enum value, in
+// the text form pq cat prints. Numbers are accepted unquoted.
+type SpecValue string
+
+// UnmarshalJSON accepts a string, a number or a boolean.
+func (v *SpecValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = SpecValue(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		*v = SpecValue(n)
+		return nil
+	}
+	if text := string(bytes.TrimSpace(data)); text == "true" || text == "false" {
+		*v = SpecValue(text)
+		return nil
+	}
+	return fmt.Errorf("expected a string or a number, got %s", data)
+}
+
+// UnmarshalYAML accepts any scalar.
+func (v *SpecValue) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a string or a number", node.Line)
+	}
+	*v = SpecValue(node.Value)
+	return nil
+}
+
+// ParseSchemaSpec parses a SchemaSpec, or an array of its fields, from JSON
+// or, when it does not start like JSON, from YAML.
+func ParseSchemaSpec(data []byte) (*SchemaSpec, error) {
+	spec := &SchemaSpec{}
+	var err error
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		err = json.Unmarshal(trimmed, &spec.Fields)
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		err = json.Unmarshal(data, spec)
+	default:
+		var doc yaml.Node
+		if err = yaml.Unmarshal(data, &doc); err == nil && len(doc.Content) > 0 {
+			if doc.Content[0].Kind == yaml.SequenceNode {
+				err = doc.Content[0].Decode(&spec.Fields)
+			} else {
+				err = doc.Content[0].Decode(spec)
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	return spec, nil
+}
+
+// Schema builds the Parquet schema the spec describes.
+func (s *SchemaSpec) Schema() (*parquet.Schema, error) {
+	if len(s.Fields) == 0 {
+		return nil, fmt.Errorf("invalid schema: no fields")
+	}
+	group, err := specGroup(s.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	name := s.Name
+	if name == "" {
+		name = "schema"
+	}
+	return parquet.NewSchema(name, group), nil
+}
+
+// specGroup returns a group of fields, in the order they are declared.
+func specGroup(fields []FieldSpec) (orderedGroup, error) {
+	var group orderedGroup
+	for _, f := range fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("field without a name")
+		}
+		if fieldByName(group, f.Name) != nil {
+			return nil, fmt.Errorf("duplicate field %s", f.Name)
+		}
+		node, err := f.Node()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		group.add(f.Name, node)
+	}
+	return group, nil
+}
+
+// Node builds the Parquet node the field describes.
+func (f *FieldSpec) Node() (parquet.Node, error) {
+	var node parquet.Node
+	switch strings.ToLower(strings.TrimSpace(f.Type)) {
+	case "struct", "group":
+		if len(f.Fields) == 0 {
+			return nil, fmt.Errorf("struct without fields")
+		}
+		group, err := specGroup(f.Fields)
+		if err != nil {
+			return nil, err
+		}
+		node = group
+	case "list":
+		if f.Element == nil {
+			return nil, fmt.Errorf("list without an element")
+		}
+		elem, err := f.Element.Node()
+		if err != nil {
+			return nil, fmt.Errorf("element: %v", err)
+		}
+		node = parquet.List(elem)
+	case "map":
+		if f.Key == nil || f.Value == nil {
+			return nil, fmt.Errorf("map without a key and a value")
+		}
+		key, err := f.Key.Node()
+		if err != nil {
+			return nil, fmt.Errorf("key: %v", err)
+		}
+		value, err := f.Value.Node()
+		if err != nil {
+			return nil, fmt.Errorf("value: %v", err)
+		}
+		node = parquet.Map(key, value)
+	default:
+		var err error
+		if node, err = ParseTypeName(f.Type); err != nil {
+			return nil, err
+		}
+	}
+	if f.Optional {
+		node = parquet.Optional(node)
+	}
+	return node, nil
+}
+
+// ParseTypeName returns the required leaf node of a type name: bool, int8,
+// int16, int32, int64 (or int), uint8 to uint64, float, double, string, json,
+// enum, bytes, fixed(n), uuid, date, time[unit], timestamp[unit] and
+// decimal(precision,scale). Units are ms, us (the default) and ns.
+func ParseTypeName(s string) (parquet.Node, error) {
+	name := strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	base, arg := name, ""
+	if i := strings.IndexAny(name, "[("); i >= 0 {
+		closing := map[byte]byte{'[': ']', '(': ')'}[name[i]]
+		if name[len(name)-1] != closing {
+			return nil, fmt.Errorf("invalid type %q", s)
+		}
+		base, arg = name[:i], name[i+1:len(name)-1]
+	}
+	if arg != "" && base != "time" && base != "timestamp" && base != "decimal" && base != "fixed" {
+		return nil, fmt.Errorf("invalid type %q", s)
+	}
+
+	switch base {
+	case "bool", "boolean":
+		return parquet.Leaf(parquet.BooleanType), nil
+	case "int8", "int16", "int32", "int64", "int":
+		bits := 64
+		if base != "int" {
+			bits, _ = strconv.Atoi(strings.TrimPrefix(base, "int"))
+		}
+		return parquet.Int(bits), nil
+	case "uint8", "uint16", "uint32", "uint64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(base, "uint"))
+		return parquet.Uint(bits), nil
+	case "float", "float32":
+		return parquet.Leaf(parquet.FloatType), nil
+	case "double", "float64":
+		return parquet.Leaf(parquet.DoubleType), nil
+	case "string", "utf8":
+		return parquet.String(), nil
+	case "json":
+		return parquet.JSON(), nil
+	case "enum":
+		return parquet.Enum(), nil
+	case "bytes", "binary":
+		return parquet.Leaf(parquet.ByteArrayType), nil
+	case "fixed":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid type %q, expected fixed(length)", s)
+		}
+		return parquet.Leaf(parquet.FixedLenByteArrayType(n)), nil
+	case "uuid":
+		return parquet.UUID(), nil
+	case "date":
+		return parquet.Date(), nil
+	case "time", "timestamp":
+		unit, err := parseTimeUnit(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q: %v", s, err)
+		}
+		if base == "time" {
+			return parquet.Time(unit), nil
+		}
+		return parquet.Timestamp(unit), nil
+	case "decimal":
+		precision, scale, ok := strings.Cut(arg, ",")
+		p, err1 := strconv.Atoi(precision)
+		sc, err2 := strconv.Atoi(scale)
+		if !ok || err1 != nil || err2 != nil || p <= 0 || sc < 0 || sc > p {
+			return nil, fmt.Errorf("invalid type %q, expected decimal(precision,scale)", s)
+		}
+		return parquet.Decimal(sc, p, decimalType(p)), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", s)
+}
+
+func parseTimeUnit(s string) (parquet.TimeUnit, error) {
+	switch s {
+	case "ms":
+		return parquet.Millisecond, nil
+	case "", "us":
+		return parquet.Microsecond, nil
+	case "ns":
+		return parquet.Nanosecond, nil
+	}
+	return nil, fmt.Errorf("unknown unit %q, expected ms, us or ns", s)
+}
+
+// decimalType returns the smallest physical type holding decimals of
+// precision digits: INT32, INT64 or a fixed-length byte array.
+func decimalType(precision int) parquet.Type {
+	switch {
+	case precision <= 9:
+		return parquet.Int32Type
+	case precision <= 18:
+		return parquet.Int64Type
+	}
+	// the largest unscaled value needs its bits and a sign bit
+	bits := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil).BitLen() + 1
+	return parquet.FixedLenByteArrayType((bits + 7) / 8)
+}
@@ -0,0 +1,139 @@
+package parquet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestParseTypeName(t *testing.T) {
+	for name, check := range map[string]func(parquet.Node) bool{
+		"int16": func(n parquet.Node) bool { return n.Type().LogicalType().Integer.BitWidth == 16 },
+		"uint64": func(n parquet.Node) bool {
+			return !n.Type().LogicalType().Integer.IsSigned && n.Type().Kind() == parquet.Int64
+		},
+		"double":        func(n parquet.Node) bool { return n.Type().Kind() == parquet.Double },
+		"timestamp":     func(n parquet.Node) bool { return n.Type().LogicalType().Timestamp.Unit.Micros != nil },
+		"timestamp[ms]": func(n parquet.Node) bool { return n.Type().LogicalType().Timestamp.Unit.Millis != nil },
+		"Time[ns]":      func(n parquet.Node) bool { return n.Type().LogicalType().Time.Unit.Nanos != nil },
+		"decimal(9,2)": func(n parquet.Node) bool {
+			return n.Type().Kind() == parquet.Int32 && n.Type().LogicalType().Decimal.Scale == 2
+		},
+		"decimal(18, 0)": func(n parquet.Node) bool { return n.Type().Kind() == parquet.Int64 },
+		"decimal(38,10)": func(n parquet.Node) bool { return n.Type().Length() == 16 },
+		"fixed(4)":       func(n parquet.Node) bool { return n.Type().Length() == 4 },
+		"uuid":           func(n parquet.Node) bool { return n.Type().LogicalType().UUID != nil },
+	} {
+		node, err := ParseTypeName(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !check(node) {
+			t.Errorf("%s: unexpected node", name)
+		}
+	}
+	for _, name := range []string{"int7", "timestamp[s]", "decimal(2,3)", "decimal", "string(3)", "fixed(0)", "text"} {
+		if _, err := ParseTypeName(name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSchemaSpec(t *testing.T) {
+	spec, err := ParseSchemaSpec([]byte(`{"fields": [
+		{"name": "id", "type": "int64"},
+		{"name": "tags", "type": "list", "optional": true, "element": {"type": "string"}},
+		{"name": "attrs", "type": "map", "key": {"type": "string"}, "value": {"type": "double", "optional": true}},
+		{"name": "info", "type": "struct", "fields": [{"name": "zip", "type": "string"}, {"name": "city", "type": "string", "optional": true}]}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseSchemaSpec: %v", err)
+	}
+	schema, err := spec.Schema()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	if n := fieldByName(schema, "tags"); n == nil || !n.Optional() || !isListNode(n) {
+		t.Errorf("tags: expected an optional list")
+	}
+	if n := fieldByName(schema, "attrs"); n == nil || !isMapNode(n) {
+		t.Errorf("attrs: expected a map")
+	}
+	if n := fieldByName(schema, "info"); n == nil || fieldByName(n, "city") == nil {
+		t.Errorf("info: expected a struct with a city")
+	}
+	names := func(node parquet.Node) string {
+		var names []string
+		for _, field := range node.Fields() {
+			names = append(names, field.Name())
+		}
+		return strings.Join(names, ",")
+	}
+	if got := names(schema); got != "id,tags,attrs,info" {
+		t.Errorf("got fields %s, want the declared order", got)
+	}
+	if got := names(fieldByName(schema, "info")); got != "zip,city" {
+		t.Errorf("info: got fields %s, want the declared order", got)
+	}
+
+	// a bare array of fields
+	spec, err = ParseSchemaSpec([]byte(`[{"name": "id", "type": "int32"}]`))
+	if err != nil || len(spec.Fields) != 1 {
+		t.Fatalf("got %v, %v", spec, err)
+	}
+
+	for data, want := range map[string]string{
+		`{"fields": []}`:      "no fields",
+		`[{"type": "int32"}]`: "field without a name",
+		`[{"name": "a", "type": "int32"}, {"name": "a", "type": "int64"}]`:             "duplicate field a",
+		`[{"name": "a", "type": "list"}]`:                                              "a: list without an element",
+		`[{"name": "a", "type": "struct", "fields": [{"name": "b", "type": "nope"}]}]`: `a: b: unknown type "nope"`,
+	} {
+		spec, err := ParseSchemaSpec([]byte(data))
+		if err == nil {
+			_, err = spec.Schema()
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", data, err, want)
+		}
+	}
+}
+
+func TestSchemaSpecYAML(t *testing.T) {
+	spec, err := ParseSchemaSpec([]byte(`
+name: events
+fields:
+  - name: id
+    type: int64
+    min: 1
+  - name: kind
+    type: string
+    values: [click, "view"]
+`))
+	if err != nil {
+		t.Fatalf("ParseSchemaSpec: %v", err)
+	}
+	if spec.Name != "events" || len(spec.Fields) != 2 || *spec.Fields[0].Min != "1" || spec.Fields[1].Values[1] != "view" {
+		t.Errorf("unexpected spec %+v", spec)
+	}
+
+	// a bare list of fields
+	spec, err = ParseSchemaSpec([]byte("- {name: id, type: int32}\n"))
+	if err != nil || len(spec.Fields) != 1 || spec.Fields[0].Type != "int32" {
+		t.Fatalf("got %v, %v", spec, err)
+	}
+
+	// bounds may be JSON numbers or strings
+	spec, err = ParseSchemaSpec([]byte(`[{"name": "a", "type": "double", "min": -1.5, "max": "2"}]`))
+	if err != nil || *spec.Fields[0].Min != "-1.5" || *spec.Fields[0].Max != "2" {
+		t.Fatalf("got %v, %v", spec, err)
+	}
+	if _, err := ParseSchemaSpec([]byte(`[{"name": "a", "type": "int32", "min": [1]}]`)); err == nil {
+		t.Errorf("expected an error for a list bound")
+	}
+	if _, err := ParseSchemaSpec([]byte("fields: [{name: a, min: [1]}]")); err == nil {
+		t.Errorf("expected an error for a YAML list bound")
+	}
+}
@@ -0,0 +1,297 @@
+package parquet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// flattener reshapes rows for output. It turns the fields of structs into
+// dotted names ("info.address.city"), unless it nests them, and the elements
+// of exploded lists and entries of exploded maps into records of their own,
+// in which the other fields are repeated. Tabular writers flatten; the JSON
+// writer nests unless asked to flatten.
+type flattener struct {
+	columns []Column
+	// explode every list and map, or the lists at the dotted paths in
+	// explodePaths
+	explode      bool
+	explodePaths map[string]bool
+	// nest keeps structs as nested records named after their field
+	nest bool
+}
+
+func newFlattener(columns []Column, cfg *outputConfig) *flattener {
+	return &flattener{columns: columns, explode: cfg.explode, explodePaths: pathSet(cfg.explodeColumns)}
+}
+
+// explodes reports whether the list or map at path is exploded.
+func (f *flattener) explodes(path string) bool {
+	return f.explode || f.explodePaths[path]
+}
+
+// explodesUnder reports whether a list or map below the struct at path is
+// exploded.
+func (f *flattener) explodesUnder(path string) bool {
+	if f.explode {
+		return true
+	}
+	for p := range f.explodePaths {
+		if strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// pathSet returns the set of paths.
+func pathSet(paths []string) map[string]bool {
+	set := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		set[path] = true
+	}
+	return set
+}
+
+// flatColumn is a column of flattened output and the node of its values.
+type flatColumn struct {
+	name string
+	node parquet.Node
+}
+
+// names returns the flat column names, in column order.
+func (f *flattener) names() []string {
+	var names []string
+	for _, col := range f.flatColumns() {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+// flatColumns returns the flat columns, in column order.
+func (f *flattener) flatColumns() []flatColumn {
+	var columns []flatColumn
+	for _, col := range f.columns {
+		columns = append(columns, f.nodeColumns(col.Node, col.Name)...)
+	}
+	return columns
+}
+
+func (f *flattener) nodeColumns(node parquet.Node, name string) []flatColumn {
+	if !f.explodes(name) && isComposite(node) {
+		return []flatColumn{{name, node}}
+	}
+	if elem := elementNode(node); elem != nil {
+		return f.nodeColumns(elem, name)
+	}
+	switch {
+	case isMapNode(node):
+		if key, value := mapKeyValue(node); key != nil && value != nil {
+			return append([]flatColumn{{name + ".key", key}}, f.nodeColumns(value, name+".value")...)
+		}
+		return []flatColumn{{name, node}}
+	case node.Leaf() || isComposite(node):
+		return []flatColumn{{name, node}}
+	}
+	var columns []flatColumn
+	for _, field := range node.Fields() {
+		columns = append(columns, f.nodeColumns(field, name+"."+field.Name())...)
+	}
+	return columns
+}
+
+// records returns the records a row expands to, with their fields in column
+// order: a single one unless lists or maps are exploded, in which case each
+// element yields a record and several exploded columns multiply. Keys of the
+// row that are not columns follow, sorted by name.
+func (f *flattener) records(row map[string]interface{}) []OrderedRow {
+	records := [][]OrderedField{{}}
+	known := make(map[string]bool, len(f.columns))
+	for _, col := range f.columns {
+		known[col.Name] = true
+		records = crossFields(records, f.value(col.Node, col.Name, col.Name, row[col.Name]))
+	}
+	extra := extraFields(row, known)
+	out := make([]OrderedRow, len(records))
+	for i, record := range records {
+		out[i] = append(OrderedRow(record), extra...)
+	}
+	return out
+}
+
+// flatten returns the records a row expands to, keyed by flat column names.
+func (f *flattener) flatten(row map[string]interface{}) []map[string]interface{} {
+	records := f.records(row)
+	out := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		values := make(map[string]interface{}, len(record))
+		for _, field := range record {
+			values[field.Name] = field.Value
+		}
+		out[i] = values
+	}
+	return out
+}
+
+// value returns the alternative sets of fields that a value of node adds to
+// a record: one set, unless an exploded list or map is met. path is the
+// dotted path of the value and key the name of its field in the record.
+func (f *flattener) value(node parquet.Node, path, key string, v interface{}) [][]OrderedField {
+	if f.explodes(path) && isComposite(node) {
+		if elem := elementNode(node); elem != nil {
+			return f.explodeList(elem, path, key, v)
+		}
+		if keyNode, valueNode := mapKeyValue(node); isMapNode(node) && keyNode != nil && valueNode != nil {
+			return f.explodeMap(valueNode, path, key, v)
+		}
+	}
+	if node.Leaf() || isComposite(node) || (f.nest && !f.explodesUnder(path)) {
+		return [][]OrderedField{{{key, orderValue(node, v)}}}
+	}
+
+	// a struct that is flattened, or holds an exploded list or map
+	m, _ := v.(map[string]interface{})
+	if m == nil && f.nest {
+		return [][]OrderedField{{{key, nil}}}
+	}
+	records := [][]OrderedField{{}}
+	for _, field := range node.Fields() {
+		fieldKey := key + "." + field.Name()
+		if f.nest {
+			fieldKey = field.Name()
+		}
+		records = crossFields(records, f.value(field, path+"."+field.Name(), fieldKey, m[field.Name()]))
+	}
+	return f.wrap(key, records)
+}
+
+// wrap turns sets of fields into the value of a nested record called key,
+// when the flattener nests structs.
+func (f *flattener) wrap(key string, records [][]OrderedField) [][]OrderedField {
+	if !f.nest {
+		return records
+	}
+	out := make([][]OrderedField, len(records))
+	for i, record := range records {
+		out[i] = []OrderedField{{key, OrderedRow(record)}}
+	}
+	return out
+}
+
+// explodeList yields the fields of every element of a list; an empty or
+// null list yields those of a null element so that the row is kept.
+func (f *flattener) explodeList(elem parquet.Node, path, key string, v interface{}) [][]OrderedField {
+	items, _ := v.([]interface{})
+	if len(items) == 0 {
+		return f.value(elem, path, key, nil)
+	}
+	var out [][]OrderedField
+	for _, item := range items {
+		out = append(out, f.value(elem, path, key, item)...)
+	}
+	return out
+}
+
+// explodeMap yields the fields of every entry of a map, in key order, as a
+// key and a value field; an empty or null map yields a null entry.
+func (f *flattener) explodeMap(value parquet.Node, path, key string, v interface{}) [][]OrderedField {
+	keyName, valueName := key+".key", key+".value"
+	if f.nest {
+		keyName, valueName = "key", "value"
+	}
+	entry := func(k, item interface{}) [][]OrderedField {
+		fields := crossFields([][]OrderedField{{{keyName, k}}}, f.value(value, path+".value", valueName, item))
+		return f.wrap(key, fields)
+	}
+	m, _ := v.(map[string]interface{})
+	if len(m) == 0 {
+		return entry(nil, nil)
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out [][]OrderedField
+	for _, k := range keys {
+		out = append(out, entry(k, m[k])...)
+	}
+	return out
+}
+
+// crossFields combines every set of fields of a with every set of b.
+func crossFields(a, b [][]OrderedField) [][]OrderedField {
+	out := make([][]OrderedField, 0, len(a)*len(b))
+	for _, fa := range a {
+		for _, fb := range b {
+			out = append(out, append(fa[:len(fa):len(fa)], fb...))
+		}
+	}
+	return out
+}
+
+// elementNode returns the node of the elements of a list, or of a repeated
+// field, and nil for other nodes.
+func elementNode(node parquet.Node) parquet.Node {
+	switch {
+	case node.Repeated():
+		return parquet.Required(node)
+	case isListNode(node):
+		return listElement(node)
+	}
+	return nil
+}
+
+// findColumn returns the node at a dotted path through the columns, their
+// struct fields and the elements of their lists, with the paths of the lists
+// it goes through, or nil if there is none.
+func findColumn(columns []Column, path string) (parquet.Node, []string) {
+	for _, col := range columns {
+		if node, lists := findNode(col.Node, col.Name, path); node != nil {
+			return node, lists
+		}
+	}
+	return nil, nil
+}
+
+func findNode(node parquet.Node, name, path string) (parquet.Node, []string) {
+	if path == name {
+		return node, nil
+	}
+	if !strings.HasPrefix(path, name+".") || node.Leaf() || isMapNode(node) {
+		return nil, nil
+	}
+	if elem := elementNode(node); elem != nil {
+		found, lists := findNode(elem, name, path)
+		return found, append(lists, name)
+	}
+	for _, field := range node.Fields() {
+		if found, lists := findNode(field, name+"."+field.Name(), path); found != nil {
+			return found, lists
+		}
+	}
+	return nil, nil
+}
+
+// checkExplodeColumns verifies that every exploded path names a list column,
+// and that the lists it is nested in are exploded too.
+func checkExplodeColumns(columns []Column, paths []string) error {
+	exploded := pathSet(paths)
+	for _, path := range paths {
+		node, lists := findColumn(columns, path)
+		if node == nil {
+			return fmt.Errorf("cannot explode %s: no such column", path)
+		}
+		if elementNode(node) == nil {
+			return fmt.Errorf("cannot explode %s: not a list column", path)
+		}
+		for _, list := range lists {
+			if !exploded[list] {
+				return fmt.Errorf("cannot explode %s without exploding %s", path, list)
+			}
+		}
+	}
+	return nil
+}
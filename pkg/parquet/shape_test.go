@@ -0,0 +1,176 @@
+package parquet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func shapeColumns() []Column {
+	item := parquet.Group{
+		"name": parquet.String(),
+		"tags": parquet.List(parquet.String()),
+	}
+	return []Column{
+		{Name: "id", Node: parquet.Int(64)},
+		{Name: "info", Node: parquet.Optional(parquet.Group{
+			"city":   parquet.String(),
+			"labels": parquet.List(parquet.String()),
+		})},
+		{Name: "items", Node: parquet.Optional(parquet.List(item))},
+	}
+}
+
+func shapeRows() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"id":   int64(1),
+			"info": map[string]interface{}{"city": "Paris", "labels": []interface{}{"a", "b"}},
+			"items": []interface{}{
+				map[string]interface{}{"name": "x", "tags": []interface{}{"t1", "t2"}},
+				map[string]interface{}{"name": "y", "tags": []interface{}{}},
+			},
+		},
+		{"id": int64(2), "info": nil, "items": nil},
+	}
+}
+
+func writeShaped(t *testing.T, format string, opts ...OutputOption) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, format, shapeColumns(), opts...)
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	for _, row := range shapeRows() {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return buf.String()
+}
+
+func TestShapeJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		opts []OutputOption
+		want []string
+	}{
+		{
+			name: "flatten",
+			opts: []OutputOption{WithFlatten()},
+			want: []string{
+				`{"id":1,"info.city":"Paris","info.labels":["a","b"],"items":[{"name":"x","tags":["t1","t2"]},{"name":"y","tags":[]}]}`,
+				`{"id":2,"info.city":null,"info.labels":null,"items":null}`,
+			},
+		},
+		{
+			name: "explode",
+			opts: []OutputOption{WithExplodeColumns("items")},
+			want: []string{
+				`{"id":1,"info":{"city":"Paris","labels":["a","b"]},"items":{"name":"x","tags":["t1","t2"]}}`,
+				`{"id":1,"info":{"city":"Paris","labels":["a","b"]},"items":{"name":"y","tags":[]}}`,
+				`{"id":2,"info":null,"items":null}`,
+			},
+		},
+		{
+			name: "explode nested lists and flatten",
+			opts: []OutputOption{WithFlatten(), WithExplodeColumns("items", "items.tags")},
+			want: []string{
+				`{"id":1,"info.city":"Paris","info.labels":["a","b"],"items.name":"x","items.tags":"t1"}`,
+				`{"id":1,"info.city":"Paris","info.labels":["a","b"],"items.name":"x","items.tags":"t2"}`,
+				`{"id":1,"info.city":"Paris","info.labels":["a","b"],"items.name":"y","items.tags":null}`,
+				`{"id":2,"info.city":null,"info.labels":null,"items.name":null,"items.tags":null}`,
+			},
+		},
+		{
+			name: "explode a list inside a struct",
+			opts: []OutputOption{WithExplodeColumns("info.labels")},
+			want: []string{
+				`{"id":1,"info":{"city":"Paris","labels":"a"},"items":[{"name":"x","tags":["t1","t2"]},{"name":"y","tags":[]}]}`,
+				`{"id":1,"info":{"city":"Paris","labels":"b"},"items":[{"name":"x","tags":["t1","t2"]},{"name":"y","tags":[]}]}`,
+				`{"id":2,"info":null,"items":null}`,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := writeShaped(t, "json", tc.opts...)
+			want := strings.Join(tc.want, "\n") + "\n"
+			if got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestShapeTabular(t *testing.T) {
+	got := writeShaped(t, "csv", WithExplodeColumns("items"))
+	want := "" +
+		"id,info.city,info.labels,items.name,items.tags\n" +
+		`1,Paris,"[""a"",""b""]",x,"[""t1"",""t2""]"` + "\n" +
+		`1,Paris,"[""a"",""b""]",y,[]` + "\n" +
+		"2,,,,\n"
+	if got != want {
+		t.Errorf("csv: got:\n%s\nwant:\n%s", got, want)
+	}
+
+	table := writeShaped(t, "table", WithExplodeColumns("items"))
+	if lines := strings.Count(table, "\n"); lines != 7 {
+		t.Errorf("table: expected 3 rows, got:\n%s", table)
+	}
+}
+
+func TestExplodeColumnErrors(t *testing.T) {
+	for path, want := range map[string]string{
+		"nope":       "no such column",
+		"id":         "not a list column",
+		"info.city":  "not a list column",
+		"items.tags": "without exploding items",
+	} {
+		_, err := NewRowWriter(&bytes.Buffer{}, "json", shapeColumns(), WithExplodeColumns(path))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", path, err, want)
+		}
+	}
+	if _, err := NewRowWriter(&bytes.Buffer{}, "csv", shapeColumns(), WithExplode(), WithExplodeColumns("items")); err == nil {
+		t.Error("csv: expected an error when exploding all lists and some columns")
+	}
+	if _, err := NewRowWriter(&bytes.Buffer{}, "arrow", shapeColumns(), WithFlatten()); err == nil {
+		t.Error("arrow: expected an error when flattening")
+	}
+}
+
+func TestExplodeListStruct(t *testing.T) {
+	ds, err := OpenDataset([]string{fixture("list_struct.parquet")})
+	if err != nil {
+		t.Fatalf("OpenDataset: %v", err)
+	}
+	defer ds.Close()
+	columns, err := ds.Columns()
+	if err != nil {
+		t.Fatalf("Columns: %v", err)
+	}
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, "json", columns, WithFlatten(), WithExplodeColumns("items"))
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	err = ds.StreamAll(func(row map[string]interface{}) error { return w.WriteRow(row) })
+	if err != nil {
+		t.Fatalf("StreamAll: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// row i has i%3+1 items
+	if len(lines) != 99 {
+		t.Fatalf("expected 99 records, got %d", len(lines))
+	}
+	if want := `{"id":"id_1","items.name":"item_1","items.value":10}`; lines[2] != want {
+		t.Errorf("got %s, want %s", lines[2], want)
+	}
+}
@@ -0,0 +1,70 @@
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultSpoolMemoryLimit is how much of a non-seekable input is kept in
+// memory before it is spilled to a temporary file.
+const DefaultSpoolMemoryLimit = 64 << 20
+
+// spool makes a non-seekable stream readable at random offsets. The returned
+// closer is nil when the data fits in memory.
+func spool(r io.Reader, cfg *readerConfig) (io.ReaderAt, int64, io.Closer, error) {
+	if cfg.spoolMax > 0 {
+		r = io.LimitReader(r, cfg.spoolMax+1)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, r, cfg.spoolMemory+1)
+	if err == io.EOF {
+		if cfg.spoolMax > 0 && n > cfg.spoolMax {
+			return nil, 0, nil, spoolLimitError(cfg.spoolMax)
+		}
+		return bytes.NewReader(buf.Bytes()), n, nil, nil
+	}
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to read input: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(cfg.spoolDir, "pq-spool-*.parquet")
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to create spool file: %v", err)
+	}
+	spooled := &spoolFile{tmp}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		spooled.Close()
+		return nil, 0, nil, fmt.Errorf("failed to write spool file: %v", err)
+	}
+	rest, err := io.Copy(tmp, r)
+	if err != nil {
+		spooled.Close()
+		return nil, 0, nil, fmt.Errorf("failed to read input: %v", err)
+	}
+	size := n + rest
+	if cfg.spoolMax > 0 && size > cfg.spoolMax {
+		spooled.Close()
+		return nil, 0, nil, spoolLimitError(cfg.spoolMax)
+	}
+	return tmp, size, spooled, nil
+}
+
+func spoolLimitError(max int64) error {
+	return fmt.Errorf("input is larger than the spool limit of %d bytes", max)
+}
+
+// spoolFile is a temporary file that is deleted when closed.
+type spoolFile struct {
+	*os.File
+}
+
+func (f *spoolFile) Close() error {
+	err := f.File.Close()
+	if rmErr := os.Remove(f.Name()); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package parquet
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// streamOnly hides every interface but io.Reader, like a pipe would.
+type streamOnly struct{ r io.Reader }
+
+func (s streamOnly) Read(p []byte) (int, error) { return s.r.Read(p) }
+
+func TestNewParquetReaderAt(t *testing.T) {
+	data, err := os.ReadFile(fixture("nested_struct.parquet"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	r, err := NewParquetReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+	rows, err := r.Head(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0]["id"] != "id_0" {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+
+	if _, err := NewParquetReaderAt(bytes.NewReader([]byte("NOT1xxxxxxxxxxxx")), 16); err == nil {
+		t.Error("expected error for bad magic header")
+	}
+}
+
+func TestNewParquetReaderFromStream(t *testing.T) {
+	data, err := os.ReadFile(fixture("multi_rowgroup.parquet"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	t.Run("in memory", func(t *testing.T) {
+		r, err := NewParquetReaderFromStream(streamOnly{bytes.NewReader(data)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Tail(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rows[0]["id"] != "id_89" {
+			t.Errorf("last row should be id_89, got %v", rows[0]["id"])
+		}
+	})
+
+	t.Run("spilled to temp file", func(t *testing.T) {
+		dir := t.TempDir()
+		r, err := NewParquetReaderFromStream(streamOnly{bytes.NewReader(data)}, WithSpoolMemoryLimit(64), WithSpoolDir(dir))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		spooled, _ := filepath.Glob(filepath.Join(dir, "*"))
+		if len(spooled) != 1 {
+			t.Errorf("expected 1 spool file, got %d", len(spooled))
+		}
+		count, _ := r.Count()
+		if count != 90 {
+			t.Errorf("expected 90 rows, got %d", count)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatalf("close failed: %v", err)
+		}
+		spooled, _ = filepath.Glob(filepath.Join(dir, "*"))
+		if len(spooled) != 0 {
+			t.Errorf("spool file should be removed on close, found %v", spooled)
+		}
+	})
+
+	t.Run("over max size", func(t *testing.T) {
+		_, err := NewParquetReaderFromStream(streamOnly{bytes.NewReader(data)}, WithSpoolMemoryLimit(64), WithSpoolMaxSize(128), WithSpoolDir(t.TempDir()))
+		if err == nil {
+			t.Fatal("expected error for input above the spool limit")
+		}
+	})
+
+	t.Run("over max size in memory", func(t *testing.T) {
+		_, err := NewParquetReaderFromStream(streamOnly{bytes.NewReader(data)}, WithSpoolMaxSize(128))
+		if err == nil || !strings.Contains(err.Error(), "spool limit") {
+			t.Fatalf("expected the spool limit error, got %v", err)
+		}
+	})
+
+	t.Run("regular file is not spooled", func(t *testing.T) {
+		f, err := os.Open(fixture("flat.parquet"))
+		if err != nil {
+			t.Fatalf("failed to open fixture: %v", err)
+		}
+		defer f.Close()
+		r, err := NewParquetReaderFromStream(f, WithSpoolMemoryLimit(0), WithSpoolDir(filepath.Join(t.TempDir(), "missing")))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer r.Close()
+		count, _ := r.Count()
+		if count != 100 {
+			t.Errorf("expected 100 rows, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,405 @@
+package parquet
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// SQLDialect is the database an SQL export is written for.
+type SQLDialect int
+
+const (
+	// DialectPostgres writes SQL for PostgreSQL (the default).
+	DialectPostgres SQLDialect = iota
+	// DialectMySQL writes SQL for MySQL and MariaDB.
+	DialectMySQL
+	// DialectSQLite writes SQL for SQLite.
+	DialectSQLite
+)
+
+// ParseSQLDialect parses "postgres", "mysql" or "sqlite".
+func ParseSQLDialect(s string) (SQLDialect, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "postgres", "postgresql", "pg":
+		return DialectPostgres, nil
+	case "mysql", "mariadb":
+		return DialectMySQL, nil
+	case "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	}
+	return DialectPostgres, fmt.Errorf("unknown SQL dialect %q, expected postgres, mysql or sqlite", s)
+}
+
+// defaultInsertBatchSize is the number of rows per INSERT statement.
+const defaultInsertBatchSize = 1000
+
+// WithSQLDialect sets the database SQL output is written for.
+func WithSQLDialect(d SQLDialect) OutputOption {
+	return func(c *outputConfig) {
+		c.dialect = d
+	}
+}
+
+// WithTable sets the name of the table SQL output creates and fills. A
+// dotted name is qualified by its schema.
+func WithTable(name string) OutputOption {
+	return func(c *outputConfig) {
+		c.table = name
+	}
+}
+
+// WithInsertBatchSize sets the number of rows per INSERT statement.
+func WithInsertBatchSize(n int) OutputOption {
+	return func(c *outputConfig) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// ExportFormats lists the formats NewExportWriter accepts.
+var ExportFormats = []string{"sql"}
+
+// NewExportWriter returns a writer for format (see ExportFormats) that loads
+// rows made up of columns into a database.
+func NewExportWriter(w io.Writer, format string, columns []Column, opts ...OutputOption) (RowWriter, error) {
+	cfg := &outputConfig{batchSize: defaultInsertBatchSize}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	switch strings.ToLower(format) {
+	case "", "sql":
+		if cfg.table == "" {
+			return nil, fmt.Errorf("sql output needs a table name")
+		}
+		return newSQLWriter(w, columns, cfg), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q, expected one of %s", format, strings.Join(ExportFormats, ", "))
+}
+
+// sqlWriter writes a CREATE TABLE statement for the columns, followed by
+// INSERT statements of up to batchSize rows each. Nested columns are stored
+// as JSON.
+type sqlWriter struct {
+	w         *bufio.Writer
+	dialect   SQLDialect
+	table     string
+	columns   []Column
+	batchSize int
+	// pending counts the rows of the INSERT statement being written
+	pending int
+	started bool
+}
+
+func newSQLWriter(w io.Writer, columns []Column, cfg *outputConfig) *sqlWriter {
+	return &sqlWriter{
+		w:         bufio.NewWriter(w),
+		dialect:   cfg.dialect,
+		table:     cfg.table,
+		columns:   columns,
+		batchSize: cfg.batchSize,
+	}
+}
+
+func (w *sqlWriter) writeHeader() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.w.WriteString("CREATE TABLE " + w.dialect.quoteTable(w.table) + " (\n")
+	for i, col := range w.columns {
+		w.w.WriteString("  " + w.dialect.quoteIdent(col.Name) + " " + w.dialect.columnType(col.Node))
+		if col.Node.Required() {
+			w.w.WriteString(" NOT NULL")
+		}
+		if i < len(w.columns)-1 {
+			w.w.WriteString(",")
+		}
+		w.w.WriteString("\n")
+	}
+	w.w.WriteString(");\n")
+}
+
+func (w *sqlWriter) WriteRow(row map[string]interface{}) error {
+	w.writeHeader()
+	if w.pending == 0 {
+		names := make([]string, len(w.columns))
+		for i, col := range w.columns {
+			names[i] = w.dialect.quoteIdent(col.Name)
+		}
+		w.w.WriteString("INSERT INTO " + w.dialect.quoteTable(w.table) + " (" + strings.Join(names, ", ") + ") VALUES\n  (")
+	} else {
+		w.w.WriteString(",\n  (")
+	}
+	for i, col := range w.columns {
+		if i > 0 {
+			w.w.WriteString(", ")
+		}
+		literal, err := w.dialect.literal(col.Node, row[col.Name])
+		if err != nil {
+			return fmt.Errorf("%s: %v", col.Name, err)
+		}
+		w.w.WriteString(literal)
+	}
+	w.w.WriteString(")")
+	if w.pending++; w.pending == w.batchSize {
+		w.pending = 0
+		if _, err := w.w.WriteString(";\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *sqlWriter) Flush() error {
+	w.writeHeader()
+	if w.pending > 0 {
+		w.pending = 0
+		w.w.WriteString(";\n")
+	}
+	return w.w.Flush()
+}
+
+// quoteIdent quotes a table or column name.
+func (d SQLDialect) quoteIdent(name string) string {
+	if d == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteTable quotes a table name, and the schema qualifying it.
+func (d SQLDialect) quoteTable(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = d.quoteIdent(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteString returns s as a string literal.
+func (d SQLDialect) quoteString(s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if d == DialectMySQL {
+		// MySQL treats backslashes in strings as escapes
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + s + "'"
+}
+
+// columnType returns the type of the database column holding the values of
+// node. Lists, maps and structs are stored as JSON.
+func (d SQLDialect) columnType(node parquet.Node) string {
+	if !node.Leaf() || node.Repeated() {
+		return d.pick("JSONB", "JSON", "TEXT")
+	}
+	t := node.Type()
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	if lt == nil {
+		lt = &format.LogicalType{}
+	}
+
+	switch {
+	case t.Kind() == parquet.Int96:
+		return d.pick("TIMESTAMPTZ", "DATETIME(6)", "TEXT")
+	case lt.Timestamp != nil:
+		fraction := "(6)"
+		if lt.Timestamp.Unit.Millis != nil {
+			fraction = "(3)"
+		}
+		if lt.Timestamp.IsAdjustedToUTC {
+			return d.pick("TIMESTAMPTZ", "DATETIME"+fraction, "TEXT")
+		}
+		return d.pick("TIMESTAMP", "DATETIME"+fraction, "TEXT")
+	case lt.Date != nil:
+		return d.pick("DATE", "DATE", "TEXT")
+	case lt.Time != nil:
+		fraction := "(6)"
+		if lt.Time.Unit.Millis != nil {
+			fraction = "(3)"
+		}
+		return d.pick("TIME", "TIME"+fraction, "TEXT")
+	case lt.Decimal != nil:
+		precision, scale := lt.Decimal.Precision, lt.Decimal.Scale
+		if scale < 0 {
+			// a negative scale leaves out trailing zeros
+			precision, scale = precision-scale, 0
+		}
+		return fmt.Sprintf("%s(%d,%d)", d.pick("NUMERIC", "DECIMAL", "NUMERIC"), precision, scale)
+	case lt.UUID != nil:
+		return d.pick("UUID", "CHAR(36)", "TEXT")
+	case lt.Float16 != nil:
+		return d.pick("REAL", "FLOAT", "REAL")
+	case lt.Integer != nil:
+		bits := int(lt.Integer.BitWidth)
+		if bits == 0 {
+			bits = 32
+			if t.Kind() == parquet.Int64 {
+				bits = 64
+			}
+		}
+		return d.intType(bits, lt.Integer.IsSigned)
+	case lt.Json != nil:
+		return d.pick("JSONB", "JSON", "TEXT")
+	case lt.UTF8 != nil || lt.Enum != nil:
+		return d.pick("TEXT", "LONGTEXT", "TEXT")
+	}
+
+	switch t.Kind() {
+	case parquet.Boolean:
+		return "BOOLEAN"
+	case parquet.Int32:
+		return d.intType(32, true)
+	case parquet.Int64:
+		return d.intType(64, true)
+	case parquet.Float:
+		return d.pick("REAL", "FLOAT", "REAL")
+	case parquet.Double:
+		return d.pick("DOUBLE PRECISION", "DOUBLE", "REAL")
+	}
+	return d.pick("BYTEA", "LONGBLOB", "BLOB")
+}
+
+// intType returns the smallest integer type holding integers of bits bits.
+// PostgreSQL has no unsigned types: they take the next larger type.
+func (d SQLDialect) intType(bits int, signed bool) string {
+	switch d {
+	case DialectMySQL:
+		name := map[int]string{8: "TINYINT", 16: "SMALLINT", 32: "INT"}[bits]
+		if name == "" {
+			name = "BIGINT"
+		}
+		if !signed {
+			name += " UNSIGNED"
+		}
+		return name
+	case DialectSQLite:
+		return "INTEGER"
+	}
+	if !signed {
+		bits *= 2
+	}
+	switch {
+	case bits <= 16:
+		return "SMALLINT"
+	case bits <= 32:
+		return "INTEGER"
+	case bits <= 64:
+		return "BIGINT"
+	}
+	return "NUMERIC(20,0)"
+}
+
+// pick returns the name used by the dialect.
+func (d SQLDialect) pick(postgres, mysql, sqlite string) string {
+	switch d {
+	case DialectMySQL:
+		return mysql
+	case DialectSQLite:
+		return sqlite
+	}
+	return postgres
+}
+
+// sqlJSON encodes the nested columns stored as JSON.
+var sqlJSON = &jsonEncoder{}
+
+// literal returns the SQL literal of a value of node, as stored or rendered
+// by its logical type.
+func (d SQLDialect) literal(node parquet.Node, v interface{}) (string, error) {
+	if v == nil {
+		return "NULL", nil
+	}
+	if !node.Leaf() || node.Repeated() {
+		data, err := sqlJSON.encode(renderedNode(node, v))
+		if err != nil {
+			return "", err
+		}
+		return d.quoteString(string(data)), nil
+	}
+	t := node.Type()
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	if lt == nil {
+		lt = &format.LogicalType{}
+	}
+
+	if _, rendered := v.(string); !rendered {
+		v = renderLeaf(t, v)
+	}
+	switch v := v.(type) {
+	case bool:
+		if d == DialectSQLite {
+			return map[bool]string{true: "1", false: "0"}[v], nil
+		}
+		return strings.ToUpper(strconv.FormatBool(v)), nil
+	case float32:
+		return d.floatLiteral(float64(v), 32), nil
+	case float64:
+		return d.floatLiteral(v, 64), nil
+	case string:
+		switch {
+		case lt.Decimal != nil:
+			return v, nil
+		case d == DialectMySQL && (lt.Timestamp != nil || t.Kind() == parquet.Int96):
+			// MySQL takes neither the Z suffix nor nanoseconds
+			ts, err := parseTimestamp(v)
+			if err != nil {
+				return "", err
+			}
+			return d.quoteString(ts.UTC().Format("2006-01-02 15:04:05.999999")), nil
+		case d == DialectMySQL && lt.Time != nil:
+			return d.quoteString(strings.TrimSuffix(v, "Z")), nil
+		}
+		if t.Kind() == parquet.ByteArray || t.Kind() == parquet.FixedLenByteArray {
+			if lt.UTF8 == nil && lt.Enum == nil && lt.Json == nil && lt.UUID == nil {
+				return d.binaryLiteral([]byte(v)), nil
+			}
+		}
+		return d.quoteString(v), nil
+	}
+	if n, ok := intValue(v); ok {
+		return strconv.FormatInt(n, 10), nil
+	}
+	if n, ok := v.(uint64); ok {
+		return strconv.FormatUint(n, 10), nil
+	}
+	if b, ok := bytesValue(v); ok {
+		return d.binaryLiteral(b), nil
+	}
+	return "", fmt.Errorf("unexpected %T value", v)
+}
+
+// floatLiteral writes NaN and infinities as PostgreSQL spells them, as
+// NULL in MySQL, which has neither, and as overflowing numbers in SQLite.
+func (d SQLDialect) floatLiteral(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return d.pick("'NaN'", "NULL", "NULL")
+	case math.IsInf(f, 1):
+		return d.pick("'Infinity'", "NULL", "9e999")
+	case math.IsInf(f, -1):
+		return d.pick("'-Infinity'", "NULL", "-9e999")
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits)
+}
+
+func (d SQLDialect) binaryLiteral(b []byte) string {
+	if d == DialectPostgres {
+		return `'\x` + hex.EncodeToString(b) + "'"
+	}
+	return "X'" + hex.EncodeToString(b) + "'"
+}
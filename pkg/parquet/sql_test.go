@@ -0,0 +1,201 @@
+package parquet
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func sqlColumns() []Column {
+	return []Column{
+		{Name: "id", Node: parquet.Int(64)},
+		{Name: "name", Node: parquet.Optional(parquet.String())},
+		{Name: "score", Node: parquet.Optional(parquet.Leaf(parquet.DoubleType))},
+		{Name: "ok", Node: parquet.Optional(parquet.Leaf(parquet.BooleanType))},
+		{Name: "at", Node: parquet.Optional(parquet.Timestamp(parquet.Microsecond))},
+		{Name: "price", Node: parquet.Optional(parquet.Decimal(2, 9, parquet.Int32Type))},
+		{Name: "blob", Node: parquet.Optional(parquet.Leaf(parquet.ByteArrayType))},
+		{Name: "tags", Node: parquet.Optional(parquet.List(parquet.String()))},
+	}
+}
+
+func writeSQL(t *testing.T, rows []map[string]interface{}, opts ...OutputOption) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewExportWriter(&buf, "sql", sqlColumns(), append([]OutputOption{WithTable("public.users")}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewExportWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return buf.String()
+}
+
+func TestSQLWriter(t *testing.T) {
+	rows := []map[string]interface{}{
+		{
+			"id":    int64(1),
+			"name":  `O'Brien \ co`,
+			"score": 1.5,
+			"ok":    true,
+			"at":    "2024-03-01T12:30:00.5Z",
+			"price": "12.34",
+			"blob":  []byte{0xde, 0xad},
+			"tags":  []interface{}{"a", "b"},
+		},
+		{"id": int64(2), "score": math.NaN()},
+	}
+
+	got := writeSQL(t, rows)
+	want := `CREATE TABLE "public"."users" (
+  "id" BIGINT NOT NULL,
+  "name" TEXT,
+  "score" DOUBLE PRECISION,
+  "ok" BOOLEAN,
+  "at" TIMESTAMPTZ,
+  "price" NUMERIC(9,2),
+  "blob" BYTEA,
+  "tags" JSONB
+);
+INSERT INTO "public"."users" ("id", "name", "score", "ok", "at", "price", "blob", "tags") VALUES
+  (1, 'O''Brien \ co', 1.5, TRUE, '2024-03-01T12:30:00.5Z', 12.34, '\xdead', '["a","b"]'),
+  (2, NULL, 'NaN', NULL, NULL, NULL, NULL, NULL);
+`
+	if got != want {
+		t.Errorf("postgres: got\n%s\nwant\n%s", got, want)
+	}
+
+	got = writeSQL(t, rows, WithSQLDialect(DialectMySQL))
+	for _, want := range []string{
+		"CREATE TABLE `public`.`users` (",
+		"`name` LONGTEXT,",
+		"`at` DATETIME(6),",
+		"`price` DECIMAL(9,2),",
+		`(1, 'O''Brien \\ co', 1.5, TRUE, '2024-03-01 12:30:00.5', 12.34, X'dead', '["a","b"]')`,
+		"(2, NULL, NULL, NULL,",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("mysql: missing %s in\n%s", want, got)
+		}
+	}
+
+	got = writeSQL(t, rows, WithSQLDialect(DialectSQLite))
+	for _, want := range []string{
+		`"at" TEXT,`,
+		`(1, 'O''Brien \ co', 1.5, 1, '2024-03-01T12:30:00.5Z', 12.34, X'dead', '["a","b"]')`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("sqlite: missing %s in\n%s", want, got)
+		}
+	}
+}
+
+func TestSQLWriterBatches(t *testing.T) {
+	var rows []map[string]interface{}
+	for i := 0; i < 5; i++ {
+		rows = append(rows, map[string]interface{}{"id": int64(i)})
+	}
+	got := writeSQL(t, rows, WithInsertBatchSize(2))
+	if n := strings.Count(got, "INSERT INTO"); n != 3 {
+		t.Errorf("expected 3 INSERT statements, got %d:\n%s", n, got)
+	}
+	if !strings.HasSuffix(got, "  (4, NULL, NULL, NULL, NULL, NULL, NULL, NULL);\n") {
+		t.Errorf("last batch not terminated:\n%s", got)
+	}
+
+	// an empty input still creates the table
+	got = writeSQL(t, nil)
+	if !strings.HasPrefix(got, "CREATE TABLE") || strings.Contains(got, "INSERT") {
+		t.Errorf("empty input: got\n%s", got)
+	}
+}
+
+func TestSQLNestedDecimal(t *testing.T) {
+	price := parquet.Decimal(2, 9, parquet.FixedLenByteArrayType(4))
+	cols := []Column{
+		{Name: "prices", Node: parquet.Optional(parquet.List(price))},
+		{Name: "item", Node: parquet.Optional(parquet.Group{"price": price})},
+	}
+	rows := []map[string]interface{}{
+		// as stored, with --raw-types
+		{
+			"prices": []interface{}{[]byte{0x00, 0x00, 0x04, 0xd2}},
+			"item":   map[string]interface{}{"price": []byte{0xff, 0xff, 0xfb, 0x2e}},
+		},
+		// already rendered
+		{
+			"prices": []interface{}{"12.34"},
+			"item":   map[string]interface{}{"price": "-12.34"},
+		},
+	}
+	var buf bytes.Buffer
+	w, err := NewExportWriter(&buf, "sql", cols, WithTable("t"))
+	if err != nil {
+		t.Fatalf("NewExportWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	want := `  ('["12.34"]', '{"price":"-12.34"}')`
+	if got := buf.String(); strings.Count(got, want) != 2 {
+		t.Errorf("expected both rows as %s, got\n%s", want, got)
+	}
+	if rendered := rows[1]["item"].(map[string]interface{})["price"]; rendered != "-12.34" {
+		t.Errorf("input row changed: %v", rendered)
+	}
+	if raw, ok := rows[0]["prices"].([]interface{})[0].([]byte); !ok || len(raw) != 4 {
+		t.Errorf("input row changed: %v", rows[0]["prices"])
+	}
+}
+
+func TestSQLColumnTypes(t *testing.T) {
+	cases := []struct {
+		node parquet.Node
+		want [3]string
+	}{
+		{parquet.Int(16), [3]string{"SMALLINT", "SMALLINT", "INTEGER"}},
+		{parquet.Uint(32), [3]string{"BIGINT", "INT UNSIGNED", "INTEGER"}},
+		{parquet.Uint(64), [3]string{"NUMERIC(20,0)", "BIGINT UNSIGNED", "INTEGER"}},
+		{parquet.Date(), [3]string{"DATE", "DATE", "TEXT"}},
+		{parquet.Timestamp(parquet.Millisecond), [3]string{"TIMESTAMPTZ", "DATETIME(3)", "TEXT"}},
+		{parquet.UUID(), [3]string{"UUID", "CHAR(36)", "TEXT"}},
+		{parquet.JSON(), [3]string{"JSONB", "JSON", "TEXT"}},
+		{parquet.Map(parquet.String(), parquet.Int(32)), [3]string{"JSONB", "JSON", "TEXT"}},
+	}
+	for _, tc := range cases {
+		for i, d := range []SQLDialect{DialectPostgres, DialectMySQL, DialectSQLite} {
+			if got := d.columnType(tc.node); got != tc.want[i] {
+				t.Errorf("%v in dialect %d: got %s, want %s", tc.node, d, got, tc.want[i])
+			}
+		}
+	}
+}
+
+func TestParseSQLDialect(t *testing.T) {
+	if d, err := ParseSQLDialect("PostgreSQL"); err != nil || d != DialectPostgres {
+		t.Errorf("got %v, %v", d, err)
+	}
+	if d, err := ParseSQLDialect("sqlite3"); err != nil || d != DialectSQLite {
+		t.Errorf("got %v, %v", d, err)
+	}
+	if _, err := ParseSQLDialect("oracle"); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := NewExportWriter(&bytes.Buffer{}, "sql", sqlColumns()); err == nil {
+		t.Error("expected an error without a table name")
+	}
+}
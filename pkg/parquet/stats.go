@@ -0,0 +1,144 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/encoding/thrift"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// columnStats holds the decoded footer statistics of one column chunk.
+type columnStats struct {
+	min, max     interface{}
+	hasMinMax    bool
+	nullCount    int64
+	hasNullCount bool
+	numValues    int64
+}
+
+// statsFunc returns the statistics of the column at path, or nil when the
+// column has no usable statistics.
+type statsFunc func(path []string) *columnStats
+
+// rowGroupStats indexes the column chunk statistics of a row group by path.
+// Only non-repeated columns whose physical ordering matches the way rows are
+// compared are included; everything else is treated as unknown. hasNullCount
+// tells which column chunks set a null count (see footerNullCounts).
+func rowGroupStats(schema *parquet.Schema, rg *format.RowGroup, hasNullCount []bool) statsFunc {
+	stats := make(map[string]*columnStats, len(rg.Columns))
+	for i := range rg.Columns {
+		md := &rg.Columns[i].MetaData
+		leaf, ok := schema.Lookup(md.PathInSchema...)
+		if !ok || leaf.MaxRepetitionLevel > 0 || !orderedLikeRows(leaf.Node) {
+			continue
+		}
+		st := &columnStats{
+			nullCount:    md.Statistics.NullCount,
+			hasNullCount: i < len(hasNullCount) && hasNullCount[i],
+			numValues:    md.NumValues,
+		}
+		minBytes, maxBytes := md.Statistics.MinValue, md.Statistics.MaxValue
+		if minBytes == nil && maxBytes == nil && md.Type != format.ByteArray {
+			// The deprecated min/max fields use signed ordering, which is
+			// only trustworthy for numeric columns.
+			minBytes, maxBytes = md.Statistics.Min, md.Statistics.Max
+		}
+		if minBytes != nil && maxBytes != nil {
+			min, ok1 := decodeStatValue(md.Type, minBytes)
+			max, ok2 := decodeStatValue(md.Type, maxBytes)
+			st.min, st.max, st.hasMinMax = min, max, ok1 && ok2
+		}
+		stats[strings.Join(md.PathInSchema, ".")] = st
+	}
+	return func(path []string) *columnStats {
+		return stats[strings.Join(path, ".")]
+	}
+}
+
+// nullCountFooter is the part of the file metadata that tells whether the
+// statistics of each column chunk set a null count. format.Statistics holds
+// it as a plain int64, so a missing count reads the same as a count of 0.
+type nullCountFooter struct {
+	RowGroups []struct {
+		Columns []struct {
+			MetaData struct {
+				Statistics struct {
+					NullCount *int64 `thrift:"3,optional"`
+				} `thrift:"12,optional"`
+			} `thrift:"3,optional"`
+		} `thrift:"1,required"`
+	} `thrift:"4,required"`
+}
+
+// footerNullCounts decodes the Thrift-encoded footer of a file and reports,
+// for each column chunk of each row group, whether its null count is set.
+// When the footer cannot be decoded no count is known.
+func footerNullCounts(footer []byte) [][]bool {
+	var md nullCountFooter
+	if err := thrift.Unmarshal(new(thrift.CompactProtocol), footer, &md); err != nil {
+		return nil
+	}
+	counts := make([][]bool, len(md.RowGroups))
+	for i, rg := range md.RowGroups {
+		counts[i] = make([]bool, len(rg.Columns))
+		for j, chunk := range rg.Columns {
+			counts[i][j] = chunk.MetaData.Statistics.NullCount != nil
+		}
+	}
+	return counts
+}
+
+// orderedLikeRows reports whether min/max statistics of a leaf can be compared
+// against the values Reconstruct produces for it.
+func orderedLikeRows(node parquet.Node) bool {
+	lt := node.Type().LogicalType()
+	if lt == nil {
+		return true
+	}
+	switch {
+	case lt.UTF8 != nil, lt.Enum != nil, lt.Json != nil:
+		return true
+	case lt.Integer != nil:
+		return lt.Integer.IsSigned
+	}
+	return false
+}
+
+// decodeStatValue decodes a PLAIN-encoded statistics value.
+func decodeStatValue(t format.Type, b []byte) (interface{}, bool) {
+	switch t {
+	case format.Boolean:
+		if len(b) < 1 {
+			return nil, false
+		}
+		return b[0] != 0, true
+	case format.Int32:
+		if len(b) < 4 {
+			return nil, false
+		}
+		return int64(int32(binary.LittleEndian.Uint32(b))), true
+	case format.Int64:
+		if len(b) < 8 {
+			return nil, false
+		}
+		return int64(binary.LittleEndian.Uint64(b)), true
+	case format.Float:
+		if len(b) < 4 {
+			return nil, false
+		}
+		f := float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		return f, !math.IsNaN(f)
+	case format.Double:
+		if len(b) < 8 {
+			return nil, false
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(b))
+		return f, !math.IsNaN(f)
+	case format.ByteArray:
+		return string(b), true
+	}
+	return nil, false
+}
@@ -0,0 +1,339 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// storedRow converts the values of a row, as returned by a reader with or
+// without WithRawTypes, back to the physical values of the fields of node.
+// It undoes renderLogicalTypes: timestamps, dates, times, decimals and UUIDs
+// are parsed from their text form, unsigned integers and FLOAT16 values are
+// stored in their signed and 2-byte forms.
+func storedRow(node parquet.Node, row map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(row))
+	for _, field := range node.Fields() {
+		v, err := storedNode(field, row[field.Name()])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", field.Name(), err)
+		}
+		out[field.Name()] = v
+	}
+	return out, nil
+}
+
+func storedNode(node parquet.Node, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if node.Repeated() {
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a list, got %T", v)
+		}
+		return storedItems(parquet.Required(node), items)
+	}
+	switch {
+	case node.Leaf():
+		return storedLeaf(node.Type(), v)
+	case isListNode(node):
+		elem := listElement(node)
+		items, ok := v.([]interface{})
+		if !ok || elem == nil {
+			return nil, fmt.Errorf("expected a list, got %T", v)
+		}
+		return storedItems(elem, items)
+	case isMapNode(node):
+		_, value := mapKeyValue(node)
+		m, ok := v.(map[string]interface{})
+		if !ok || value == nil {
+			// maps with keys other than strings are left as read
+			return v, nil
+		}
+		out := make(map[string]interface{}, len(m))
+		for k, item := range m {
+			stored, err := storedNode(value, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			out[k] = stored
+		}
+		return out, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a struct, got %T", v)
+	}
+	return storedRow(node, m)
+}
+
+func storedItems(node parquet.Node, items []interface{}) ([]interface{}, error) {
+	out := make([]interface{}, len(items))
+	for i, item := range items {
+		stored, err := storedNode(node, item)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %v", i, err)
+		}
+		out[i] = stored
+	}
+	return out, nil
+}
+
+// storedLeaf converts a value to the physical type of t.
+func storedLeaf(t parquet.Type, v interface{}) (interface{}, error) {
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = logicalTypeOf(t.ConvertedType())
+	}
+	if lt == nil {
+		lt = &format.LogicalType{}
+	}
+	s, isString := v.(string)
+
+	switch {
+	case t.Kind() == parquet.Int96:
+		if isString {
+			ts, err := parseTimestamp(s)
+			if err != nil {
+				return nil, err
+			}
+			return int96Of(ts), nil
+		}
+	case lt.Timestamp != nil && isString:
+		ts, err := parseTimestamp(s)
+		if err != nil {
+			return nil, err
+		}
+		tick, _ := unitDuration(lt.Timestamp.Unit)
+		return physicalInt(t, ticksOf(ts, tick))
+	case lt.Date != nil && isString:
+		d, err := parseDate(s)
+		if err != nil {
+			return nil, err
+		}
+		return physicalInt(t, d.Unix()/86400)
+	case lt.Time != nil && isString:
+		d, err := parseTimeOfDay(s)
+		if err != nil {
+			return nil, err
+		}
+		tick, _ := unitDuration(lt.Time.Unit)
+		return physicalInt(t, int64(d/tick))
+	case lt.Decimal != nil && isString:
+		unscaled, err := parseDecimal(s, int(lt.Decimal.Scale))
+		if err != nil {
+			return nil, err
+		}
+		switch t.Kind() {
+		case parquet.Int32, parquet.Int64:
+			if !unscaled.IsInt64() {
+				return nil, fmt.Errorf("decimal %q is out of range", s)
+			}
+			return physicalInt(t, unscaled.Int64())
+		}
+		size := 0
+		if t.Kind() == parquet.FixedLenByteArray {
+			size = t.Length()
+		}
+		return decimalBytes(unscaled, size)
+	case lt.UUID != nil && isString:
+		b, err := parseUUID(s)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case lt.Float16 != nil:
+		switch f := v.(type) {
+		case float32:
+			return float16Bytes(f), nil
+		case float64:
+			return float16Bytes(float32(f)), nil
+		}
+	}
+
+	switch t.Kind() {
+	case parquet.Int32, parquet.Int64:
+		n, err := toInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		return physicalInt(t, n)
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		b, ok := bytesValue(v)
+		if !ok {
+			return nil, fmt.Errorf("expected a string or bytes, got %T", v)
+		}
+		if t.Kind() == parquet.FixedLenByteArray && len(b) != t.Length() {
+			return nil, fmt.Errorf("expected %d bytes, got %d", t.Length(), len(b))
+		}
+		return b, nil
+	}
+	return v, nil
+}
+
+// physicalInt returns n as the INT32 or INT64 value of t.
+func physicalInt(t parquet.Type, n int64) (interface{}, error) {
+	if t.Kind() == parquet.Int32 {
+		if n < math.MinInt32 || n > math.MaxUint32 {
+			return nil, fmt.Errorf("%d is out of range for INT32", n)
+		}
+		return int32(n), nil
+	}
+	return n, nil
+}
+
+// parseTimestamp parses an RFC 3339 timestamp, or one without a time zone as
+// written for timestamps not adjusted to UTC.
+func parseTimestamp(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02T15:04:05.999999999", s); err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+		}
+	}
+	return t, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t, nil
+}
+
+// parseTimeOfDay parses a time such as "15:04:05.123" or "15:04:05Z" and
+// returns the time since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04:05.999999999", strings.TrimSuffix(s, "Z"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)), nil
+}
+
+// ticksOf returns the number of ticks between the Unix epoch and t.
+func ticksOf(t time.Time, tick time.Duration) int64 {
+	perSecond := int64(time.Second / tick)
+	return t.Unix()*perSecond + int64(t.Nanosecond())/int64(tick)
+}
+
+// int96Of encodes t in the legacy INT96 layout (see formatInt96).
+func int96Of(t time.Time) deprecated.Int96 {
+	days := t.Unix() / 86400
+	nanos := t.Sub(time.Unix(days*86400, 0))
+	if nanos < 0 {
+		days--
+		nanos += 24 * time.Hour
+	}
+	return deprecated.Int96{uint32(nanos), uint32(uint64(nanos) >> 32), uint32(days + julianDayOfUnixEpoch)}
+}
+
+// parseDecimal returns the unscaled value of a decimal string at scale.
+func parseDecimal(s string, scale int) (*big.Int, error) {
+	if scale < 0 {
+		// a negative scale leaves out trailing zeros
+		n, err := parseDecimal(s, 0)
+		if err != nil {
+			return nil, err
+		}
+		unscaled, rem := new(big.Int).QuoRem(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil), new(big.Int))
+		if rem.Sign() != 0 {
+			return nil, fmt.Errorf("decimal %q is not a multiple of 1e%d", s, -scale)
+		}
+		return unscaled, nil
+	}
+	digits := s
+	fraction := ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits, fraction = s[:i], s[i+1:]
+	}
+	if len(fraction) > scale {
+		return nil, fmt.Errorf("decimal %q has more than %d fractional digits", s, scale)
+	}
+	n, ok := new(big.Int).SetString(digits+fraction+strings.Repeat("0", scale-len(fraction)), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	return n, nil
+}
+
+// decimalBytes encodes n as big-endian two's complement in size bytes, or in
+// as few bytes as it needs when size is 0.
+func decimalBytes(n *big.Int, size int) ([]byte, error) {
+	// the magnitude without the sign bit: n, or -n-1 for negative numbers
+	magnitude := n
+	if n.Sign() < 0 {
+		magnitude = new(big.Int).Not(n)
+	}
+	if size == 0 {
+		size = magnitude.BitLen()/8 + 1
+	}
+	if magnitude.BitLen() >= 8*size {
+		return nil, fmt.Errorf("decimal %s does not fit in %d bytes", n, size)
+	}
+	v := new(big.Int).Set(n)
+	if v.Sign() < 0 {
+		v.Add(v, new(big.Int).Lsh(big.NewInt(1), uint(8*size)))
+	}
+	return v.FillBytes(make([]byte, size)), nil
+}
+
+// parseUUID parses a UUID in canonical 8-4-4-4-12 form.
+func parseUUID(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != 16 || len(s) != 36 {
+		return nil, fmt.Errorf("invalid UUID %q", s)
+	}
+	return b, nil
+}
+
+// float16Bytes encodes f as a little-endian IEEE 754 half-precision float,
+// rounding to the nearest value.
+func float16Bytes(f float32) []byte {
+	return binary.LittleEndian.AppendUint16(nil, float32ToFloat16(f))
+}
+
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127 + 15
+	frac := bits & 0x7fffff
+	switch {
+	case bits>>23&0xff == 0xff:
+		if frac != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp >= 0x1f:
+		return sign | 0x7c00
+	case exp <= 0:
+		// subnormal, or too small to represent
+		if exp < -10 {
+			return sign
+		}
+		frac |= 0x800000
+		shift := uint(14 - exp)
+		half := frac >> shift
+		rem, mid := frac&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > mid || rem == mid && half&1 == 1 {
+			half++
+		}
+		return sign | uint16(half)
+	}
+	half := uint32(exp)<<10 | frac>>13
+	// rounding may carry into the exponent, up to infinity
+	if rem := frac & 0x1fff; rem > 0x1000 || rem == 0x1000 && half&1 == 1 {
+		half++
+	}
+	return sign | uint16(half)
+}
@@ -0,0 +1,261 @@
+package parquet
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+)
+
+func TestStoredLeaf(t *testing.T) {
+	cases := []struct {
+		name string
+		typ  parquet.Type
+		raw  interface{}
+	}{
+		{"timestamp ms", parquet.Timestamp(parquet.Millisecond).Type(), int64(1704164645123)},
+		{"timestamp ns before epoch", parquet.Timestamp(parquet.Nanosecond).Type(), int64(-189291354877000000)},
+		{"date", parquet.Date().Type(), int32(19724)},
+		{"date before epoch", parquet.Date().Type(), int32(-1)},
+		{"time", parquet.Time(parquet.Microsecond).Type(), int64(11045123456)},
+		{"decimal int32", parquet.Decimal(2, 9, parquet.Int32Type).Type(), int32(-5)},
+		{"decimal int64", parquet.Decimal(2, 18, parquet.Int64Type).Type(), int64(1234567)},
+		{"decimal fixed", parquet.Decimal(4, 20, parquet.FixedLenByteArrayType(9)).Type(), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfb}},
+		{"uuid", parquet.UUID().Type(), []byte{0x12, 0x34, 0x56, 0x78, 0x12, 0x34, 0x56, 0x78, 0x12, 0x34, 0x56, 0x78, 0x12, 0x34, 0x56, 0x78}},
+		{"uint32", parquet.Uint(32).Type(), int32(-294967296)},
+		{"uint64", parquet.Uint(64).Type(), int64(-1)},
+		{"string", parquet.String().Type(), []byte("hello")},
+		{"int96", parquet.Int96Type, deprecated.Int96{0x2a05f200, 0x2e, 2437087}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered := renderLeaf(tc.typ, tc.raw)
+			got, err := storedLeaf(tc.typ, rendered)
+			if err != nil {
+				t.Fatalf("storedLeaf(%v): %v", rendered, err)
+			}
+			if !reflect.DeepEqual(got, tc.raw) {
+				t.Errorf("storedLeaf(%v) = %v (%T), want %v (%T)", rendered, got, got, tc.raw, tc.raw)
+			}
+			// stored values pass through unchanged
+			if got, err := storedLeaf(tc.typ, tc.raw); err != nil || !reflect.DeepEqual(got, tc.raw) {
+				t.Errorf("storedLeaf(%v) = %v, %v", tc.raw, got, err)
+			}
+		})
+	}
+
+	errors := []struct {
+		name string
+		typ  parquet.Type
+		v    interface{}
+	}{
+		{"bad timestamp", parquet.Timestamp(parquet.Millisecond).Type(), "yesterday"},
+		{"too many decimal places", parquet.Decimal(2, 9, parquet.Int32Type).Type(), "1.234"},
+		{"decimal overflow", parquet.Decimal(0, 4, parquet.FixedLenByteArrayType(1)).Type(), "128"},
+		{"short uuid", parquet.UUID().Type(), "1234"},
+		{"int32 overflow", parquet.Int(32).Type(), int64(1) << 40},
+	}
+	for _, tc := range errors {
+		if _, err := storedLeaf(tc.typ, tc.v); err == nil {
+			t.Errorf("%s: expected an error for %v", tc.name, tc.v)
+		}
+	}
+}
+
+func TestStoredRow(t *testing.T) {
+	schema := parquet.NewSchema("row", parquet.Group{
+		"day":  parquet.Optional(parquet.Date()),
+		"days": parquet.List(parquet.Date()),
+		"info": parquet.Group{"seen": parquet.Date()},
+		"tags": parquet.Map(parquet.String(), parquet.Date()),
+	})
+	row := map[string]interface{}{
+		"day":  nil,
+		"days": []interface{}{"1970-01-02", "1970-01-03"},
+		"info": map[string]interface{}{"seen": "1970-01-04"},
+		"tags": map[string]interface{}{"a": "1970-01-05"},
+	}
+	want := map[string]interface{}{
+		"day":  nil,
+		"days": []interface{}{int32(1), int32(2)},
+		"info": map[string]interface{}{"seen": int32(3)},
+		"tags": map[string]interface{}{"a": int32(4)},
+	}
+	got, err := storedRow(schema, row)
+	if err != nil {
+		t.Fatalf("storedRow: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFloat32ToFloat16(t *testing.T) {
+	for h := 0; h < 0x10000; h++ {
+		f := float16ToFloat32(uint16(h))
+		got := float32ToFloat16(f)
+		if math.IsNaN(float64(f)) {
+			if got&0x7c00 != 0x7c00 || got&0x3ff == 0 {
+				t.Fatalf("NaN %04x encoded as %04x", h, got)
+			}
+			continue
+		}
+		if got != uint16(h) {
+			t.Fatalf("%04x (%v) encoded as %04x", h, f, got)
+		}
+	}
+	if got := float32ToFloat16(1e10); got != 0x7c00 {
+		t.Errorf("overflow: got %04x, want +Inf", got)
+	}
+	// 1 + 2^-11 lies halfway between 1 and the next half, and rounds to even
+	if got := float32ToFloat16(1 + 1.0/2048); got != 0x3c00 {
+		t.Errorf("rounding: got %04x, want 3c00", got)
+	}
+}
+
+func TestParquetWriterRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []ReaderOption
+	}{
+		{"rendered", nil},
+		{"raw", []ReaderOption{WithRawTypes()}},
+		{"selected columns", []ReaderOption{WithColumns("price", "id")}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ds, err := OpenDataset([]string{fixture("logical_types.parquet")}, tc.opts...)
+			if err != nil {
+				t.Fatalf("OpenDataset: %v", err)
+			}
+			defer ds.Close()
+			rows, err := ds.Head(3)
+			if err != nil {
+				t.Fatalf("Head: %v", err)
+			}
+
+			var buf bytes.Buffer
+			w, err := ds.NewParquetWriter(&buf)
+			if err != nil {
+				t.Fatalf("NewParquetWriter: %v", err)
+			}
+			for _, row := range rows {
+				if err := w.WriteRow(row); err != nil {
+					t.Fatalf("WriteRow: %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+
+			r, err := NewParquetReaderFromStream(bytes.NewReader(buf.Bytes()), tc.opts...)
+			if err != nil {
+				t.Fatalf("reading the written file: %v", err)
+			}
+			defer r.Close()
+			got, err := r.Head(10)
+			if err != nil {
+				t.Fatalf("Head: %v", err)
+			}
+			if !reflect.DeepEqual(got, rows) {
+				t.Errorf("rows differ after the round trip:\ngot  %v\nwant %v", got, rows)
+			}
+		})
+	}
+}
+
+func TestParquetWriterCopiesRows(t *testing.T) {
+	type record struct {
+		ID    int64            `parquet:"id"`
+		Score float64          `parquet:"score"`
+		Attrs map[int32]string `parquet:"attrs"`
+	}
+	path := filepath.Join(t.TempDir(), "in.parquet")
+	var in bytes.Buffer
+	w := parquet.NewWriter(&in, parquet.SchemaOf(record{}))
+	for _, rec := range []record{
+		{ID: 1, Score: math.NaN(), Attrs: map[int32]string{2: "b", 1: "a"}},
+		{ID: 2, Score: 0.1, Attrs: map[int32]string{}},
+	} {
+		if err := w.Write(rec); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := os.WriteFile(path, in.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ds, err := OpenDataset([]string{path})
+	if err != nil {
+		t.Fatalf("OpenDataset: %v", err)
+	}
+	defer ds.Close()
+	var out bytes.Buffer
+	pw, err := ds.NewParquetWriter(&out)
+	if err != nil {
+		t.Fatalf("NewParquetWriter: %v", err)
+	}
+	if err := ds.StreamAll(pw.WriteRow); err != nil {
+		t.Fatalf("StreamAll: %v", err)
+	}
+	if err := pw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	read := func(data []byte) []map[string]interface{} {
+		r, err := NewParquetReaderFromStream(bytes.NewReader(data), WithRawTypes())
+		if err != nil {
+			t.Fatalf("NewParquetReaderFromStream: %v", err)
+		}
+		defer r.Close()
+		rows, err := r.Head(10)
+		if err != nil {
+			t.Fatalf("Head: %v", err)
+		}
+		return rows
+	}
+	want, got := read(in.Bytes()), read(out.Bytes())
+	// NaN is not equal to itself: compare its bits
+	for _, rows := range [][]map[string]interface{}{want, got} {
+		rows[0]["score"] = math.Float64bits(rows[0]["score"].(float64))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows differ after the copy:\ngot  %v\nwant %v", got, want)
+	}
+}
+
+func TestParquetWriterColumnOrder(t *testing.T) {
+	ds, err := OpenDataset([]string{fixture("logical_types.parquet")}, WithColumns("price", "id"))
+	if err != nil {
+		t.Fatalf("OpenDataset: %v", err)
+	}
+	defer ds.Close()
+	var buf bytes.Buffer
+	w, err := ds.NewParquetWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewParquetWriter: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	r, err := NewParquetReaderFromStream(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("reading the written file: %v", err)
+	}
+	defer r.Close()
+	var names []string
+	for _, field := range r.pfile.Schema().Fields() {
+		names = append(names, field.Name())
+	}
+	if got := strings.Join(names, ","); got != "id,price" {
+		t.Errorf("got columns %s, want the file order id,price", got)
+	}
+}
@@ -0,0 +1,258 @@
+package parquet
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// minCellWidth is the narrowest a column is squeezed to when the table must
+// fit in a maximum width.
+const minCellWidth = 6
+
+// tableWriter prints rows as a column-aligned grid. Widths depend on every
+// value, so rows are buffered until Flush.
+type tableWriter struct {
+	w         io.Writer
+	flat      *flattener
+	header    []string
+	rows      [][]tableCell
+	nullValue string
+	maxWidth  int
+	wrap      bool
+	dimNulls  bool
+}
+
+type tableCell struct {
+	text    string
+	numeric bool
+	null    bool
+}
+
+// Terminal escapes around the nulls of tables written WithDimNulls.
+const (
+	dimStart = "\x1b[2m"
+	dimEnd   = "\x1b[0m"
+)
+
+func newTableWriter(w io.Writer, columns []Column, cfg *outputConfig) *tableWriter {
+	flat := newFlattener(columns, cfg)
+	return &tableWriter{
+		w:         w,
+		flat:      flat,
+		header:    flat.names(),
+		nullValue: displayNull(cfg),
+		maxWidth:  cfg.maxWidth,
+		wrap:      cfg.wrap,
+		dimNulls:  cfg.dimNulls,
+	}
+}
+
+func (w *tableWriter) WriteRow(row map[string]interface{}) error {
+	for _, values := range w.flat.flatten(row) {
+		cells := make([]tableCell, len(w.header))
+		for i, name := range w.header {
+			text, err := formatCell(values[name], w.nullValue)
+			if err != nil {
+				return err
+			}
+			cells[i] = tableCell{text: escapeControl(text), numeric: isNumber(values[name]), null: values[name] == nil}
+		}
+		w.rows = append(w.rows, cells)
+	}
+	return nil
+}
+
+func (w *tableWriter) Flush() error {
+	widths := w.columnWidths()
+	out := bufio.NewWriter(w.w)
+	separator := tableSeparator(widths)
+
+	out.WriteString(separator)
+	header := make([]tableCell, len(w.header))
+	for i, name := range w.header {
+		header[i] = tableCell{text: name}
+	}
+	w.writeRow(out, header, widths)
+	out.WriteString(separator)
+	for _, row := range w.rows {
+		w.writeRow(out, row, widths)
+	}
+	if len(w.rows) > 0 {
+		out.WriteString(separator)
+	}
+	w.rows = nil
+	return out.Flush()
+}
+
+// columnWidths sizes every column to its widest value, then narrows the
+// widest columns until the table fits in maxWidth.
+func (w *tableWriter) columnWidths() []int {
+	widths := make([]int, len(w.header))
+	for i, name := range w.header {
+		widths[i] = displayWidth(name)
+	}
+	for _, row := range w.rows {
+		for i, cell := range row {
+			if n := displayWidth(cell.text); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	if w.maxWidth <= 0 || len(widths) == 0 {
+		return widths
+	}
+
+	// "| " + cells joined by " | " + " |"
+	total := 3*len(widths) + 1
+	for _, n := range widths {
+		total += n
+	}
+	for total > w.maxWidth {
+		widest := 0
+		for i, n := range widths {
+			if n > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minCellWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+func (w *tableWriter) writeRow(out *bufio.Writer, row []tableCell, widths []int) {
+	lines := make([][]string, len(row))
+	height := 1
+	for i, cell := range row {
+		if w.wrap {
+			lines[i] = wrapText(cell.text, widths[i])
+		} else {
+			lines[i] = []string{truncateText(cell.text, widths[i])}
+		}
+		if len(lines[i]) > height {
+			height = len(lines[i])
+		}
+	}
+	for l := 0; l < height; l++ {
+		out.WriteString("|")
+		for i, cell := range row {
+			text := ""
+			if l < len(lines[i]) {
+				text = lines[i][l]
+			}
+			pad := strings.Repeat(" ", widths[i]-displayWidth(text))
+			if cell.null && w.dimNulls && text != "" {
+				text = dimStart + text + dimEnd
+			}
+			out.WriteString(" ")
+			if cell.numeric {
+				out.WriteString(pad + text)
+			} else {
+				out.WriteString(text + pad)
+			}
+			out.WriteString(" |")
+		}
+		out.WriteString("\n")
+	}
+}
+
+func tableSeparator(widths []int) string {
+	var b strings.Builder
+	b.WriteString("+")
+	for _, n := range widths {
+		b.WriteString(strings.Repeat("-", n+2))
+		b.WriteString("+")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// truncateText cuts s to width columns, marking the cut with an ellipsis.
+func truncateText(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		rw := runeWidth(r)
+		if used+rw > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += rw
+	}
+	b.WriteString("…")
+	return b.String()
+}
+
+// wrapText splits s into lines of at most width columns.
+func wrapText(s string, width int) []string {
+	if width <= 0 || displayWidth(s) <= width {
+		return []string{s}
+	}
+	var lines []string
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		rw := runeWidth(r)
+		if used+rw > width {
+			lines = append(lines, b.String())
+			b.Reset()
+			used = 0
+		}
+		b.WriteRune(r)
+		used += rw
+	}
+	return append(lines, b.String())
+}
+
+// escapeControl replaces line breaks and tabs, which would break the grid.
+func escapeControl(s string) string {
+	if !strings.ContainsAny(s, "\n\r\t") {
+		return s
+	}
+	return strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s)
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+// displayWidth returns the number of terminal columns s takes up.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth returns 2 for East Asian wide characters and emoji, 0 for
+// combining marks and 1 otherwise.
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x0300 && r <= 0x036F, r >= 0x200B && r <= 0x200F, r == 0xFE0F:
+		return 0
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,105 @@
+package parquet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestTableWriter(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Node: parquet.Int(64)},
+		{Name: "name", Node: parquet.Optional(parquet.String())},
+		{Name: "info", Node: parquet.Group{"city": parquet.String()}},
+	}
+	rows := []map[string]interface{}{
+		{"id": int64(1), "name": "alice", "info": map[string]interface{}{"city": "Paris"}},
+		{"id": int64(20), "name": nil, "info": map[string]interface{}{"city": "São Paulo\nBrazil"}},
+	}
+	write := func(t *testing.T, opts ...OutputOption) string {
+		t.Helper()
+		var buf bytes.Buffer
+		w, err := NewRowWriter(&buf, "table", columns, opts...)
+		if err != nil {
+			t.Fatalf("NewRowWriter: %v", err)
+		}
+		for _, row := range rows {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatalf("WriteRow: %v", err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		return buf.String()
+	}
+
+	t.Run("aligned", func(t *testing.T) {
+		want := "" +
+			"+----+-------+-------------------+\n" +
+			"| id | name  | info.city         |\n" +
+			"+----+-------+-------------------+\n" +
+			"|  1 | alice | Paris             |\n" +
+			"| 20 | NULL  | São Paulo\\nBrazil |\n" +
+			"+----+-------+-------------------+\n"
+		if got := write(t); got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		want := "" +
+			"+----+-------+------------+\n" +
+			"| id | name  | info.city  |\n" +
+			"+----+-------+------------+\n" +
+			"|  1 | alice | Paris      |\n" +
+			"| 20 | NULL  | São Paulo… |\n" +
+			"+----+-------+------------+\n"
+		if got := write(t, WithMaxWidth(27)); got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		want := "" +
+			"+----+-------+------------+\n" +
+			"| id | name  | info.city  |\n" +
+			"+----+-------+------------+\n" +
+			"|  1 | alice | Paris      |\n" +
+			"| 20 | ∅     | São Paulo\\ |\n" +
+			"|    |       | nBrazil    |\n" +
+			"+----+-------+------------+\n"
+		if got := write(t, WithMaxWidth(27), WithWrap(), WithNullValue("∅")); got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("dimmed nulls", func(t *testing.T) {
+		rows = append(rows, map[string]interface{}{"id": int64(3), "name": "NULL", "info": map[string]interface{}{"city": "Oslo"}})
+		want := "" +
+			"+----+-------+-------------------+\n" +
+			"| id | name  | info.city         |\n" +
+			"+----+-------+-------------------+\n" +
+			"|  1 | alice | Paris             |\n" +
+			"| 20 | \x1b[2mNULL\x1b[0m  | São Paulo\\nBrazil |\n" +
+			"|  3 | NULL  | Oslo              |\n" +
+			"+----+-------+-------------------+\n"
+		if got := write(t, WithDimNulls()); got != want {
+			t.Errorf("got:\n%q\nwant:\n%q", got, want)
+		}
+	})
+}
+
+func TestDisplayWidth(t *testing.T) {
+	if n := displayWidth("表格ab"); n != 6 {
+		t.Errorf("expected width 6, got %d", n)
+	}
+	if s := truncateText("表格表格", 5); s != "表格…" {
+		t.Errorf("unexpected truncation %q", s)
+	}
+	if lines := wrapText("abcdefg", 3); !reflect.DeepEqual(lines, []string{"abc", "def", "g"}) {
+		t.Errorf("unexpected wrap %q", lines)
+	}
+}
@@ -3,7 +3,10 @@
 
 import pyarrow as pa
 import pyarrow.parquet as pq
+import datetime
+import decimal
 import os
+import uuid
 
 OUTDIR = os.path.dirname(os.path.abspath(__file__))
 
@@ -268,6 +271,87 @@ def gen_large():
     write("large.parquet", pa.table(data, schema=schema))
 
 
+def gen_logical_types():
+    """One column per logical type; the last row is all nulls.
+
+    pa.uuid() needs pyarrow >= 18.
+    """
+    base = datetime.datetime(2024, 1, 2, 3, 4, 5, 123456, tzinfo=datetime.timezone.utc)
+    schema = pa.schema([
+        ("id", pa.string()),
+        ("ts_ms", pa.timestamp("ms", tz="UTC")),
+        ("ts_us", pa.timestamp("us", tz="UTC")),
+        ("ts_ns", pa.timestamp("ns", tz="UTC")),
+        ("ts_local", pa.timestamp("us")),
+        ("day", pa.date32()),
+        ("time_us", pa.time64("us")),
+        ("price", pa.decimal128(9, 2)),
+        ("amount", pa.decimal128(38, 10)),
+        ("uid", pa.uuid()),
+        ("counter", pa.uint32()),
+        ("raw", pa.binary(4)),
+    ])
+    data = {
+        "id": ["id_0", "id_1", "id_2"],
+        "ts_ms": [base, base - datetime.timedelta(days=365 * 60), None],
+        "ts_us": [base, base - datetime.timedelta(days=365 * 60), None],
+        "ts_ns": [base, base - datetime.timedelta(days=365 * 60), None],
+        "ts_local": [base.replace(tzinfo=None), base.replace(tzinfo=None), None],
+        "day": [base.date(), datetime.date(1969, 12, 31), None],
+        "time_us": [base.time().replace(tzinfo=None), datetime.time(23, 59, 59), None],
+        "price": [decimal.Decimal("12345.67"), decimal.Decimal("-0.05"), None],
+        "amount": [decimal.Decimal("1234567890123456789012345678.0123456789"), decimal.Decimal("-1"), None],
+        "uid": [uuid.UUID("12345678-1234-5678-1234-567812345678").bytes, uuid.UUID(int=0).bytes, None],
+        "counter": [4000000000, 1, None],
+        "raw": [b"\x00\x01\x02\x03", b"abcd", None],
+    }
+    write("logical_types.parquet", pa.table(data, schema=schema), coerce_timestamps=None)
+
+
+def gen_int96():
+    """Spark-style INT96 timestamps."""
+    base = datetime.datetime(2024, 1, 2, 3, 4, 5, 123456, tzinfo=datetime.timezone.utc)
+    schema = pa.schema([
+        ("id", pa.string()),
+        ("ts", pa.timestamp("ns", tz="UTC")),
+    ])
+    data = {
+        "id": ["id_0", "id_1"],
+        "ts": [base, datetime.datetime(1960, 6, 1, tzinfo=datetime.timezone.utc)],
+    }
+    write("int96.parquet", pa.table(data, schema=schema), use_deprecated_int96_timestamps=True)
+
+
+def gen_arrow_stream():
+    """An Arrow IPC stream written by pyarrow, which the Arrow output of pq
+    is checked against (TestArrowGolden builds the same rows)."""
+    schema = pa.schema([
+        pa.field("id", pa.int64(), nullable=False),
+        ("name", pa.string()),
+        ("score", pa.float64()),
+        ("tags", pa.list_(pa.field("element", pa.string(), nullable=False))),
+        ("attrs", pa.map_(pa.string(), pa.field("value", pa.int32(), nullable=False))),
+        ("info", pa.struct([pa.field("city", pa.string(), nullable=False), ("zip", pa.int32())])),
+        ("at", pa.timestamp("ms", tz="UTC")),
+        ("price", pa.decimal128(9, 2)),
+    ])
+    data = {
+        "id": [1, 2, 3],
+        "name": ["a", None, "ccc"],
+        "score": [1.5, None, 2.0],
+        "tags": [["x", "y"], None, []],
+        "attrs": [[("k", 7)], None, []],
+        "info": [{"city": "Paris", "zip": 75001}, None, {"city": "Oslo", "zip": None}],
+        "at": [datetime.datetime(2024, 1, 2, 3, 4, 5, tzinfo=datetime.timezone.utc), None,
+               datetime.datetime(1969, 12, 31, 23, 59, 59, tzinfo=datetime.timezone.utc)],
+        "price": [decimal.Decimal("1.50"), None, decimal.Decimal("-2.25")],
+    }
+    path = os.path.join(OUTDIR, "golden.arrows")
+    with pa.OSFile(path, "wb") as sink, pa.ipc.new_stream(sink, schema) as writer:
+        writer.write_table(pa.table(data, schema=schema))
+    print(f"  golden.arrows: {os.path.getsize(path)} bytes")
+
+
 if __name__ == "__main__":
     print("Generating test fixtures...")
     gen_flat()
@@ -281,4 +365,7 @@ if __name__ == "__main__":
     gen_multi_rowgroup()
     gen_empty()
     gen_large()
+    gen_logical_types()
+    gen_int96()
+    gen_arrow_stream()
     print("Done.")
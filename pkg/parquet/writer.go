@@ -9,12 +9,13 @@ import (
 	"strings"
 
 	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
 )
 
 func SplitParquetFile(filePath string, numFiles int) error {
 	srcFile, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open source file: %v", err)
+		return fmt.Errorf("failed to open source file: %w", err)
 	}
 	defer srcFile.Close()
 
@@ -28,7 +29,7 @@ func SplitParquetFile(filePath string, numFiles int) error {
 		return fmt.Errorf("failed to reset file position: %v", err)
 	}
 	if string(header) != "PAR1" {
-		return fmt.Errorf("invalid file format: the file is not a valid Parquet file")
+		return fmt.Errorf("%w: missing PAR1 magic bytes at the start of the file", ErrNotParquet)
 	}
 
 	reader := parquet.NewReader(srcFile)
@@ -39,7 +40,7 @@ func SplitParquetFile(filePath string, numFiles int) error {
 
 	totalRows := reader.NumRows()
 	if totalRows == 0 {
-		return fmt.Errorf("file contains no rows, no need to split")
+		return fmt.Errorf("%w: no rows to split", ErrEmptyFile)
 	}
 
 	rowsPerFile := int64(math.Ceil(float64(totalRows) / float64(numFiles)))
@@ -119,3 +120,108 @@ func SplitParquetFile(filePath string, numFiles int) error {
 	fmt.Printf("Successfully split file %s into %d files\n", filePath, numFiles)
 	return nil
 }
+
+// arrowSchemaKey is the metadata key under which Arrow writers store their
+// own schema. It no longer matches once columns are selected or added.
+const arrowSchemaKey = "ARROW:schema"
+
+// sourceRow holds the values of a row as stored in its file. Readers set up
+// by Dataset.NewParquetWriter return rows as a sourceRow under sourceRowKey,
+// which the writer copies without converting them.
+type sourceRow parquet.Row
+
+const sourceRowKey = ""
+
+// parquetWriter writes rows to a Parquet file.
+type parquetWriter struct {
+	writer *parquet.Writer
+	schema *parquet.Schema
+}
+
+// NewParquetWriter returns a RowWriter that writes the rows of the dataset to
+// w as a Parquet file. The file has the schema, key-value metadata and
+// compression codec of the first input, narrowed to the selected columns in
+// file order and extended with the partition columns. Rows may be read with
+// or without WithRawTypes. Flush writes the footer.
+//
+// Without selected columns, filter or partition columns, rows read from the
+// dataset afterwards are copied as stored, like SplitParquetFile does, from
+// every file with the schema of the first; they are only meant for this
+// writer.
+func (d *Dataset) NewParquetWriter(w io.Writer) (RowWriter, error) {
+	if len(d.files) == 0 {
+		return nil, fmt.Errorf("no input files")
+	}
+	var schema *parquet.Schema
+	var metadata *format.FileMetaData
+	var fields []parquet.Field
+	err := d.with(d.files[0], func(r *ParquetReader) error {
+		schema = r.pfile.Schema()
+		metadata = r.pfile.Metadata()
+		if !d.partitionsOnly {
+			fields = r.outputSchema().Fields()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reshaped := false
+	cfg := newReaderConfig(d.files[0].opts)
+	if len(cfg.columns) > 0 || len(d.partitionKeys) > 0 {
+		columns, err := d.Columns()
+		if err != nil {
+			return nil, err
+		}
+		partitions := columns[len(columns)-len(d.partitionKeys):]
+		isPartition := make(map[string]bool, len(partitions))
+		for _, col := range partitions {
+			isPartition[col.Name] = true
+		}
+		var group orderedGroup
+		for _, field := range fields {
+			if !isPartition[field.Name()] {
+				group.add(field.Name(), field)
+			}
+		}
+		for _, col := range partitions {
+			group.add(col.Name, col.Node)
+		}
+		schema = parquet.NewSchema(schema.Name(), group)
+		reshaped = true
+	} else if cfg.where == "" && cfg.filter == nil {
+		d.copySchema = schema
+	}
+
+	opts := []parquet.WriterOption{schema}
+	if len(metadata.RowGroups) > 0 && len(metadata.RowGroups[0].Columns) > 0 {
+		codec := metadata.RowGroups[0].Columns[0].MetaData.Codec
+		if c := parquet.LookupCompressionCodec(codec); c != nil {
+			opts = append(opts, parquet.Compression(c))
+		}
+	}
+	for _, kv := range metadata.KeyValueMetadata {
+		if reshaped && kv.Key == arrowSchemaKey {
+			continue
+		}
+		opts = append(opts, parquet.KeyValueMetadata(kv.Key, kv.Value))
+	}
+	return &parquetWriter{writer: parquet.NewWriter(w, opts...), schema: schema}, nil
+}
+
+func (w *parquetWriter) WriteRow(row map[string]interface{}) error {
+	if values, ok := row[sourceRowKey].(sourceRow); ok {
+		_, err := w.writer.WriteRows([]parquet.Row{parquet.Row(values)})
+		return err
+	}
+	stored, err := storedRow(w.schema, row)
+	if err != nil {
+		return err
+	}
+	return w.writer.Write(stored)
+}
+
+func (w *parquetWriter) Flush() error {
+	return w.writer.Close()
+}